@@ -12,9 +12,14 @@ import (
 	"github.com/BlaisePopov/stack-auth/api/teams"
 	"github.com/BlaisePopov/stack-auth/api/users"
 	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+	_interface "github.com/BlaisePopov/stack-auth/base-http-client/interface"
 )
 
 type Client struct {
+	// HTTPClient - базовый HTTP-клиент, общий для всех разделов API.
+	// В клиенте, созданном AsUser, это тот же базовый клиент с учетными данными конфигурации:
+	// запросы через него выполняются не от имени пользователя
+	HTTPClient      *base_http_client.Client
 	ContactChannels *contactchannels.Client
 	Oauth           *oauth.Client
 	Others          *others.Client
@@ -28,18 +33,86 @@ type Client struct {
 }
 
 func NewClient(config base_http_client.Config) *Client {
-	baseHTTPClient := base_http_client.NewClient(config)
+	return newClient(base_http_client.NewClient(config))
+}
 
+// NewServerClient создает клиент с типом доступа "server" и проверкой учетных данных
+func NewServerClient(config base_http_client.Config) (*Client, error) {
+	baseHTTPClient, err := base_http_client.NewServerClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(baseHTTPClient), nil
+}
+
+// NewClientAccessClient создает клиент с типом доступа "client" и проверкой учетных данных
+func NewClientAccessClient(config base_http_client.Config) (*Client, error) {
+	baseHTTPClient, err := base_http_client.NewClientAccessClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(baseHTTPClient), nil
+}
+
+// NewAdminClient создает клиент с типом доступа "admin" и проверкой учетных данных
+func NewAdminClient(config base_http_client.Config) (*Client, error) {
+	baseHTTPClient, err := base_http_client.NewAdminClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(baseHTTPClient), nil
+}
+
+// newClient создает клиенты разделов API поверх базового HTTP-клиента
+func newClient(baseHTTPClient *base_http_client.Client) *Client {
+	return newClientView(baseHTTPClient, baseHTTPClient)
+}
+
+// newClientView создает клиенты разделов API, отправляющие запросы через httpClient.
+// Поле HTTPClient результата указывает на базовый клиент baseHTTPClient
+func newClientView(baseHTTPClient *base_http_client.Client, httpClient _interface.BaseHTTPClient) *Client {
 	return &Client{
-		ContactChannels: contactchannels.NewClient(baseHTTPClient),
-		Oauth:           oauth.NewClient(baseHTTPClient),
-		Others:          others.NewClient(baseHTTPClient),
-		OTP:             otp.NewClient(baseHTTPClient),
-		Password:        password.NewClient(baseHTTPClient),
-		Permissions:     permissions.NewClient(baseHTTPClient),
-		Projects:        projects.NewClient(baseHTTPClient),
-		Sessions:        sessions.NewClient(baseHTTPClient),
-		Teams:           teams.NewClient(baseHTTPClient),
-		Users:           users.NewClient(baseHTTPClient),
+		HTTPClient:      baseHTTPClient,
+		ContactChannels: contactchannels.NewClient(httpClient),
+		Oauth:           oauth.NewClient(httpClient),
+		Others:          others.NewClient(httpClient),
+		OTP:             otp.NewClient(httpClient),
+		Password:        password.NewClient(httpClient),
+		Permissions:     permissions.NewClient(httpClient),
+		Projects:        projects.NewClient(httpClient),
+		Sessions:        sessions.NewClient(httpClient),
+		Teams:           teams.NewClient(httpClient),
+		Users:           users.NewClient(httpClient),
+	}
+}
+
+// Services содержит разделы API в виде интерфейсов.
+// Любой раздел можно заменить собственной реализацией или заглушкой из пакета api/mocks.
+type Services struct {
+	ContactChannels ContactChannelsAPI
+	Oauth           OAuthAPI
+	Others          OthersAPI
+	OTP             OTPAPI
+	Password        PasswordAPI
+	Permissions     PermissionsAPI
+	Projects        ProjectsAPI
+	Sessions        SessionsAPI
+	Teams           TeamsAPI
+	Users           UsersAPI
+}
+
+// Services возвращает разделы клиента в виде интерфейсов
+func (c *Client) Services() *Services {
+	return &Services{
+		ContactChannels: c.ContactChannels,
+		Oauth:           c.Oauth,
+		Others:          c.Others,
+		OTP:             c.OTP,
+		Password:        c.Password,
+		Permissions:     c.Permissions,
+		Projects:        c.Projects,
+		Sessions:        c.Sessions,
+		Teams:           c.Teams,
+		Users:           c.Users,
 	}
 }
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+	"net/url"
+	"slices"
+
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+// userHTTPClient выполняет запросы базового клиента с токенами пользователя
+type userHTTPClient struct {
+	base *base_http_client.Client
+	opts []base_http_client.RequestOption
+}
+
+// SendRequest отправляет HTTP-запрос к API от имени пользователя
+func (u *userHTTPClient) SendRequest(method, path string, queryParams url.Values, body []byte, opts ...base_http_client.RequestOption) ([]byte, error) {
+	return u.base.SendRequest(method, path, queryParams, body, slices.Concat(u.opts, opts)...)
+}
+
+// SendRequestContext отправляет HTTP-запрос к API от имени пользователя с учетом контекста
+func (u *userHTTPClient) SendRequestContext(ctx context.Context, method, path string, queryParams url.Values, body []byte, opts ...base_http_client.RequestOption) ([]byte, error) {
+	return u.base.SendRequestContext(ctx, method, path, queryParams, body, slices.Concat(u.opts, opts)...)
+}
+
+// Do выполняет запрос от имени пользователя. Опции вызова применяются после опций пользователя
+func (u *userHTTPClient) Do(ctx context.Context, request *base_http_client.Request, opts ...base_http_client.RequestOption) ([]byte, error) {
+	return u.base.Do(ctx, request, slices.Concat(u.opts, opts)...)
+}
+
+// AsUser возвращает клиент, все разделы которого выполняют запросы от имени пользователя с указанными токенами.
+// Клиент использует базовый HTTP-клиент c и его ключи проекта, поэтому создается дешево и подходит
+// для обработки отдельного входящего запроса. Один процесс может одновременно работать от имени многих пользователей.
+//
+// Запросы выполняются только с указанными токенами: токены пользователя из конфигурации c и хранилища токенов
+// не используются, поэтому при пустом refreshToken запросы выполняются без refresh token.
+// Опции opts применяются ко всем запросам клиента, например base_http_client.WithAccessType(base_http_client.AccessTypeClient)
+// выполняет запросы с типом доступа "client" вместо типа доступа c.
+//
+// Поле HTTPClient результата указывает на базовый клиент c и токены пользователя не применяет.
+// Для произвольных запросов от имени пользователя передайте опцию base_http_client.WithUserTokens
+func (c *Client) AsUser(accessToken, refreshToken string, opts ...base_http_client.RequestOption) *Client {
+	userOpts := []base_http_client.RequestOption{base_http_client.WithUserTokens(accessToken, refreshToken)}
+	return newClientView(c.HTTPClient, &userHTTPClient{base: c.HTTPClient, opts: append(userOpts, opts...)})
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/BlaisePopov/stack-auth/api/password"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+	"github.com/BlaisePopov/stack-auth/stackauthtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAsUser_ConcurrentUsers(t *testing.T) {
+	server := stackauthtest.NewServer()
+	defer server.Close()
+	client := NewClient(server.Config())
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		userID := server.CreateUser(fmt.Sprintf("user%d@example.com", i), "")
+		accessToken, refreshToken, err := server.SignIn(userID)
+		assert.NoError(t, err)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			user, err := client.AsUser(accessToken, refreshToken).Users.GetCurrentUser()
+			assert.NoError(t, err)
+			if assert.NotNil(t, user) {
+				assert.Equal(t, userID, user.ID)
+			}
+		}()
+	}
+	wg.Wait()
+
+	_, err := client.Users.GetCurrentUser()
+	assert.Error(t, err)
+}
+
+func TestAsUser_ClientAccessType(t *testing.T) {
+	server := stackauthtest.NewServer()
+	defer server.Close()
+	userID := server.CreateUser("john@example.com", "")
+	accessToken, refreshToken, err := server.SignIn(userID)
+	assert.NoError(t, err)
+
+	_, err = NewClient(server.Config()).AsUser(accessToken, refreshToken, base_http_client.WithAccessType(base_http_client.AccessTypeClient)).Users.GetCurrentUser()
+	var configErr *base_http_client.ConfigError
+	if assert.ErrorAs(t, err, &configErr) {
+		assert.Equal(t, "PublishableClientKey", configErr.Field)
+	}
+
+	config := server.Config()
+	config.PublishableClientKey = stackauthtest.DefaultPublishableClientKey
+	user := NewClient(config).AsUser(accessToken, refreshToken, base_http_client.WithAccessType(base_http_client.AccessTypeClient))
+	current, err := user.Users.GetCurrentUser()
+	assert.NoError(t, err)
+	assert.Equal(t, userID, current.ID)
+
+	// Запросы с типом доступа "client" не получают серверных прав
+	_, err = user.Users.GetUser(userID)
+	assert.Error(t, err)
+}
+
+func TestAsUser_SessionMethodsSendRefreshToken(t *testing.T) {
+	server := stackauthtest.NewServer()
+	defer server.Close()
+	accessToken, refreshToken, err := server.SignIn(server.CreateUser("john@example.com", ""))
+	assert.NoError(t, err)
+	client := NewClient(server.Config())
+	user := client.AsUser(accessToken, refreshToken)
+
+	refreshed, err := client.Sessions.RefreshAccessToken(refreshToken)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, refreshed.AccessToken)
+
+	_, err = user.Sessions.SignOut(refreshToken)
+	assert.NoError(t, err)
+	_, err = client.Sessions.RefreshAccessToken(refreshToken)
+	assert.ErrorIs(t, err, base_http_client.ErrRefreshTokenNotFoundOrExpired)
+}
+
+func TestAsUser_DoesNotInheritConfigTokens(t *testing.T) {
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id":"user-bob"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(base_http_client.Config{
+		BaseURL:         server.URL,
+		ProjectID:       "project-id",
+		SecretServerKey: "secret",
+		AccessToken:     "alice-access",
+		RefreshToken:    "alice-refresh",
+		TokenStore:      base_http_client.NewMemoryTokenStore(base_http_client.Tokens{AccessToken: "stored-access", RefreshToken: "stored-refresh"}),
+	})
+
+	_, err := client.AsUser("bob-access", "").Users.GetCurrentUser()
+	assert.NoError(t, err)
+	assert.Equal(t, "bob-access", headers.Get("X-Stack-Access-Token"))
+	assert.Empty(t, headers.Values("X-Stack-Refresh-Token"))
+
+	_, err = client.AsUser("", "").Users.GetCurrentUser()
+	assert.NoError(t, err)
+	assert.Empty(t, headers.Values("X-Stack-Access-Token"))
+	assert.Empty(t, headers.Values("X-Stack-Refresh-Token"))
+}
+
+func TestAsUser_DoesNotShareConfigTokenStore(t *testing.T) {
+	server := stackauthtest.NewServer()
+	defer server.Close()
+	server.CreateUser("alice@example.com", "alice-password")
+	server.CreateUser("bob@example.com", "bob-password")
+
+	store := base_http_client.NewMemoryTokenStore(base_http_client.Tokens{})
+	config := server.Config()
+	config.TokenStore = store
+	client := NewClient(config)
+
+	alice, err := client.Password.SignInWithEmail(&password.SignInRequest{Email: "alice@example.com", Password: "alice-password"})
+	assert.NoError(t, err)
+	bob, err := client.AsUser("", "").Password.SignInWithEmail(&password.SignInRequest{Email: "bob@example.com", Password: "bob-password"})
+	assert.NoError(t, err)
+
+	stored, err := store.Load(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, alice.RefreshToken, stored.RefreshToken)
+
+	user, err := client.AsUser(bob.AccessToken, "").Users.GetCurrentUser()
+	assert.NoError(t, err)
+	if assert.NotNil(t, user) {
+		assert.Equal(t, "bob@example.com", user.PrimaryEmail)
+	}
+	user, err = client.Users.GetCurrentUser()
+	assert.NoError(t, err)
+	if assert.NotNil(t, user) {
+		assert.Equal(t, "alice@example.com", user.PrimaryEmail)
+	}
+}
+
+func TestAsUser_HTTPClientIsNotUserScoped(t *testing.T) {
+	server := stackauthtest.NewServer()
+	defer server.Close()
+	userID := server.CreateUser("john@example.com", "")
+	accessToken, refreshToken, err := server.SignIn(userID)
+	assert.NoError(t, err)
+
+	client := NewClient(server.Config())
+	user := client.AsUser(accessToken, refreshToken)
+	assert.Same(t, client.HTTPClient, user.HTTPClient)
+
+	_, err = user.HTTPClient.SendRequest(http.MethodGet, "/users/me", nil, nil)
+	assert.Error(t, err)
+
+	response, err := user.HTTPClient.SendRequest(http.MethodGet, "/users/me", nil, nil, base_http_client.WithUserTokens(accessToken, refreshToken))
+	assert.NoError(t, err)
+	assert.Contains(t, string(response), userID)
+}
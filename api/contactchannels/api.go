@@ -1,10 +1,13 @@
 package contactchannels
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"github.com/BlaisePopov/stack-auth/api/pagination"
 	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client/interface"
 	"github.com/BlaisePopov/stack-auth/base-http-client/utils"
+	"github.com/BlaisePopov/stack-auth/internal/transport"
+	"iter"
 	"net/url"
 )
 
@@ -23,98 +26,109 @@ func NewClient(httpClient base_http_client.BaseHTTPClient) *Client {
 // Входные параметры:
 //   - userID: идентификатор пользователя
 //   - contactChannelID: идентификатор контактного канала (опционально)
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект ListContactChannelsResponse и ошибка, если она возникла
-func (c *Client) ListContactChannels(userID, contactChannelID string) (*ListContactChannelsResponse, error) {
-	response := &ListContactChannelsResponse{}
+func (c *Client) ListContactChannels(userID, contactChannelID string, opts ...base_http_client.RequestOption) (*ListContactChannelsResponse, error) {
+	return c.ListContactChannelsCtx(context.Background(), userID, contactChannelID, opts...)
+}
+
+// ListContactChannelsCtx выполняет ListContactChannels с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) ListContactChannelsCtx(ctx context.Context, userID, contactChannelID string, opts ...base_http_client.RequestOption) (*ListContactChannelsResponse, error) {
+	return c.listContactChannels(ctx, userID, contactChannelID, "", opts...)
+}
+
+// AllContactChannels возвращает итератор по всем контактным каналам пользователя, автоматически переходящий по курсорам страниц.
+//
+// Входные параметры:
+//   - ctx: контекст, отмена которого завершает перебор
+//   - userID: идентификатор пользователя
+//   - opts: параметры запросов страниц (опционально)
+//
+// Возвращаемое значение: итератор пар (контактный канал, ошибка)
+func (c *Client) AllContactChannels(ctx context.Context, userID string, opts ...base_http_client.RequestOption) iter.Seq2[ContactChannel, error] {
+	return pagination.Seq(ctx, func(ctx context.Context, cursor string) ([]ContactChannel, string, error) {
+		response, err := c.listContactChannels(ctx, userID, "", cursor, opts...)
+		if err != nil {
+			return nil, "", err
+		}
+		return response.Items, response.Pagination.NextCursor, nil
+	})
+}
+
+// listContactChannels загружает страницу контактных каналов, начиная с курсора
+func (c *Client) listContactChannels(ctx context.Context, userID, contactChannelID, cursor string, opts ...base_http_client.RequestOption) (*ListContactChannelsResponse, error) {
 	queryParams := url.Values{}
 	utils.AddOptionalStringParam(queryParams, "user_id", userID)
 	utils.AddOptionalStringParam(queryParams, "contact_channel_id", contactChannelID)
-
-	rawResponse, err := c.HTTPClient.SendRequest("GET", "/contact-channels", queryParams, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+	utils.AddOptionalStringParam(queryParams, "cursor", cursor)
+
+	return transport.Do[transport.NoBody, ListContactChannelsResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "contactchannels.ListContactChannels",
+		Method:    "GET",
+		Path:      "/contact-channels",
+		Query:     queryParams,
+	}, nil, opts...)
 }
 
 // CreateContactChannel создает новый контактный канал для пользователя. [https://docs.stack-auth.com/next/rest-api/server/contact-channels/create-a-contact-channel]
 //
 // Входные параметры:
 //   - request: данные для создания контактного канала
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект ContactChannelResponse и ошибка, если она возникла
-func (c *Client) CreateContactChannel(request *CreateContactChannelRequest) (*ContactChannelResponse, error) {
-	response := &ContactChannelResponse{}
-
-	body, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-	}
-
-	rawResponse, err := c.HTTPClient.SendRequest("POST", "/contact-channels", nil, body)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) CreateContactChannel(request *CreateContactChannelRequest, opts ...base_http_client.RequestOption) (*ContactChannelResponse, error) {
+	return c.CreateContactChannelCtx(context.Background(), request, opts...)
+}
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+// CreateContactChannelCtx выполняет CreateContactChannel с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) CreateContactChannelCtx(ctx context.Context, request *CreateContactChannelRequest, opts ...base_http_client.RequestOption) (*ContactChannelResponse, error) {
+	return transport.Do[CreateContactChannelRequest, ContactChannelResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "contactchannels.CreateContactChannel",
+		Method:    "POST",
+		Path:      "/contact-channels",
+	}, request, opts...)
 }
 
 // VerifyEmail подтверждает email пользователя с помощью кода верификации. [https://docs.stack-auth.com/next/rest-api/server/contact-channels/verify-an-email]
 //
 // Входные параметры:
 //   - request: данные с кодом подтверждения
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект VerifyResponse и ошибка, если она возникла
-func (c *Client) VerifyEmail(request *VerifyRequest) (*VerifyResponse, error) {
-	response := &VerifyResponse{}
-
-	body, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-	}
-
-	rawResponse, err := c.HTTPClient.SendRequest("POST", "/contact-channels/verify", nil, body)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) VerifyEmail(request *VerifyRequest, opts ...base_http_client.RequestOption) (*VerifyResponse, error) {
+	return c.VerifyEmailCtx(context.Background(), request, opts...)
+}
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+// VerifyEmailCtx выполняет VerifyEmail с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) VerifyEmailCtx(ctx context.Context, request *VerifyRequest, opts ...base_http_client.RequestOption) (*VerifyResponse, error) {
+	return transport.Do[VerifyRequest, VerifyResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "contactchannels.VerifyEmail",
+		Method:    "POST",
+		Path:      "/contact-channels/verify",
+	}, request, opts...)
 }
 
 // CheckEmailVerificationCode проверяет валидность кода подтверждения email. [https://docs.stack-auth.com/next/rest-api/server/contact-channels/check-email-verification-code]
 //
 // Входные параметры:
 //   - request: данные с кодом подтверждения
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект CheckCodeResponse и ошибка, если она возникла
-func (c *Client) CheckEmailVerificationCode(request *CheckCodeRequest) (*CheckCodeResponse, error) {
-	response := &CheckCodeResponse{}
-
-	body, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-	}
-
-	rawResponse, err := c.HTTPClient.SendRequest("POST", "/contact-channels/verify/check-code", nil, body)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) CheckEmailVerificationCode(request *CheckCodeRequest, opts ...base_http_client.RequestOption) (*CheckCodeResponse, error) {
+	return c.CheckEmailVerificationCodeCtx(context.Background(), request, opts...)
+}
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+// CheckEmailVerificationCodeCtx выполняет CheckEmailVerificationCode с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) CheckEmailVerificationCodeCtx(ctx context.Context, request *CheckCodeRequest, opts ...base_http_client.RequestOption) (*CheckCodeResponse, error) {
+	return transport.Do[CheckCodeRequest, CheckCodeResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "contactchannels.CheckEmailVerificationCode",
+		Method:    "POST",
+		Path:      "/contact-channels/verify/check-code",
+	}, request, opts...)
 }
 
 // GetContactChannel возвращает контактный канал по идентификаторам пользователя и канала. [https://docs.stack-auth.com/next/rest-api/server/contact-channels/get-a-contact-channel]
@@ -122,21 +136,23 @@ func (c *Client) CheckEmailVerificationCode(request *CheckCodeRequest) (*CheckCo
 // Входные параметры:
 //   - userID: идентификатор пользователя
 //   - contactChannelID: идентификатор контактного канала
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект ContactChannelResponse и ошибка, если она возникла
-func (c *Client) GetContactChannel(userID, contactChannelID string) (*ContactChannelResponse, error) {
-	response := &ContactChannelResponse{}
-	path := fmt.Sprintf("/contact-channels/%s/%s", userID, contactChannelID)
+func (c *Client) GetContactChannel(userID, contactChannelID string, opts ...base_http_client.RequestOption) (*ContactChannelResponse, error) {
+	return c.GetContactChannelCtx(context.Background(), userID, contactChannelID, opts...)
+}
 
-	rawResponse, err := c.HTTPClient.SendRequest("GET", path, nil, nil)
-	if err != nil {
-		return nil, err
-	}
+// GetContactChannelCtx выполняет GetContactChannel с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) GetContactChannelCtx(ctx context.Context, userID, contactChannelID string, opts ...base_http_client.RequestOption) (*ContactChannelResponse, error) {
+	path := fmt.Sprintf("/contact-channels/%s/%s", userID, contactChannelID)
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+	return transport.Do[transport.NoBody, ContactChannelResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation:    "contactchannels.GetContactChannel",
+		Method:       "GET",
+		PathTemplate: "/contact-channels/{user_id}/{contact_channel_id}",
+		Path:         path,
+	}, nil, opts...)
 }
 
 // DeleteContactChannel удаляет контактный канал пользователя. [https://docs.stack-auth.com/next/rest-api/server/contact-channels/delete-a-contact-channel]
@@ -144,21 +160,23 @@ func (c *Client) GetContactChannel(userID, contactChannelID string) (*ContactCha
 // Входные параметры:
 //   - userID: идентификатор пользователя
 //   - contactChannelID: идентификатор контактного канала
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект DeleteResponse и ошибка, если она возникла
-func (c *Client) DeleteContactChannel(userID, contactChannelID string) (*DeleteResponse, error) {
-	response := &DeleteResponse{}
-	path := fmt.Sprintf("/contact-channels/%s/%s", userID, contactChannelID)
+func (c *Client) DeleteContactChannel(userID, contactChannelID string, opts ...base_http_client.RequestOption) (*DeleteResponse, error) {
+	return c.DeleteContactChannelCtx(context.Background(), userID, contactChannelID, opts...)
+}
 
-	rawResponse, err := c.HTTPClient.SendRequest("DELETE", path, nil, nil)
-	if err != nil {
-		return nil, err
-	}
+// DeleteContactChannelCtx выполняет DeleteContactChannel с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) DeleteContactChannelCtx(ctx context.Context, userID, contactChannelID string, opts ...base_http_client.RequestOption) (*DeleteResponse, error) {
+	path := fmt.Sprintf("/contact-channels/%s/%s", userID, contactChannelID)
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+	return transport.Do[transport.NoBody, DeleteResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation:    "contactchannels.DeleteContactChannel",
+		Method:       "DELETE",
+		PathTemplate: "/contact-channels/{user_id}/{contact_channel_id}",
+		Path:         path,
+	}, nil, opts...)
 }
 
 // UpdateContactChannel обновляет существующий контактный канал. [https://docs.stack-auth.com/next/rest-api/server/contact-channels/update-a-contact-channel]
@@ -167,26 +185,23 @@ func (c *Client) DeleteContactChannel(userID, contactChannelID string) (*DeleteR
 //   - userID: идентификатор пользователя
 //   - contactChannelID: идентификатор контактного канала
 //   - request: данные для обновления
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект ContactChannelResponse и ошибка, если она возникла
-func (c *Client) UpdateContactChannel(userID, contactChannelID string, request *UpdateContactChannelRequest) (*ContactChannelResponse, error) {
-	response := &ContactChannelResponse{}
-	path := fmt.Sprintf("/contact-channels/%s/%s", userID, contactChannelID)
-
-	body, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-	}
+func (c *Client) UpdateContactChannel(userID, contactChannelID string, request *UpdateContactChannelRequest, opts ...base_http_client.RequestOption) (*ContactChannelResponse, error) {
+	return c.UpdateContactChannelCtx(context.Background(), userID, contactChannelID, request, opts...)
+}
 
-	rawResponse, err := c.HTTPClient.SendRequest("PATCH", path, nil, body)
-	if err != nil {
-		return nil, err
-	}
+// UpdateContactChannelCtx выполняет UpdateContactChannel с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) UpdateContactChannelCtx(ctx context.Context, userID, contactChannelID string, request *UpdateContactChannelRequest, opts ...base_http_client.RequestOption) (*ContactChannelResponse, error) {
+	path := fmt.Sprintf("/contact-channels/%s/%s", userID, contactChannelID)
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+	return transport.Do[UpdateContactChannelRequest, ContactChannelResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation:    "contactchannels.UpdateContactChannel",
+		Method:       "PATCH",
+		PathTemplate: "/contact-channels/{user_id}/{contact_channel_id}",
+		Path:         path,
+	}, request, opts...)
 }
 
 // SendVerificationCode отправляет код подтверждения на контактный канал. [https://docs.stack-auth.com/next/rest-api/server/contact-channels/send-contact-channel-verification-code]
@@ -195,24 +210,21 @@ func (c *Client) UpdateContactChannel(userID, contactChannelID string, request *
 //   - userID: идентификатор пользователя
 //   - contactChannelID: идентификатор контактного канала
 //   - request: данные с callback URL
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект SendCodeResponse и ошибка, если она возникла
-func (c *Client) SendVerificationCode(userID, contactChannelID string, request *SendCodeRequest) (*SendCodeResponse, error) {
-	response := &SendCodeResponse{}
-	path := fmt.Sprintf("/contact-channels/%s/%s/send-verification-code", userID, contactChannelID)
-
-	body, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-	}
+func (c *Client) SendVerificationCode(userID, contactChannelID string, request *SendCodeRequest, opts ...base_http_client.RequestOption) (*SendCodeResponse, error) {
+	return c.SendVerificationCodeCtx(context.Background(), userID, contactChannelID, request, opts...)
+}
 
-	rawResponse, err := c.HTTPClient.SendRequest("POST", path, nil, body)
-	if err != nil {
-		return nil, err
-	}
+// SendVerificationCodeCtx выполняет SendVerificationCode с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) SendVerificationCodeCtx(ctx context.Context, userID, contactChannelID string, request *SendCodeRequest, opts ...base_http_client.RequestOption) (*SendCodeResponse, error) {
+	path := fmt.Sprintf("/contact-channels/%s/%s/send-verification-code", userID, contactChannelID)
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+	return transport.Do[SendCodeRequest, SendCodeResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation:    "contactchannels.SendVerificationCode",
+		Method:       "POST",
+		PathTemplate: "/contact-channels/{user_id}/{contact_channel_id}/send-verification-code",
+		Path:         path,
+	}, request, opts...)
 }
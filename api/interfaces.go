@@ -0,0 +1,292 @@
+package api
+
+import (
+	"context"
+	"iter"
+
+	"github.com/BlaisePopov/stack-auth/api/contactchannels"
+	"github.com/BlaisePopov/stack-auth/api/oauth"
+	"github.com/BlaisePopov/stack-auth/api/others"
+	"github.com/BlaisePopov/stack-auth/api/otp"
+	"github.com/BlaisePopov/stack-auth/api/password"
+	"github.com/BlaisePopov/stack-auth/api/permissions"
+	"github.com/BlaisePopov/stack-auth/api/projects"
+	"github.com/BlaisePopov/stack-auth/api/sessions"
+	"github.com/BlaisePopov/stack-auth/api/teams"
+	"github.com/BlaisePopov/stack-auth/api/users"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+// ContactChannelsAPI описывает операции раздела contactchannels. Интерфейс реализуют contactchannels.Client и mocks.ContactChannels
+type ContactChannelsAPI interface {
+	// ListContactChannels возвращает список контактных каналов пользователя
+	ListContactChannels(userID, contactChannelID string, opts ...base_http_client.RequestOption) (*contactchannels.ListContactChannelsResponse, error)
+	ListContactChannelsCtx(ctx context.Context, userID, contactChannelID string, opts ...base_http_client.RequestOption) (*contactchannels.ListContactChannelsResponse, error)
+
+	// AllContactChannels возвращает итератор по всем контактным каналам пользователя, автоматически переходящий по курсорам страниц
+	AllContactChannels(ctx context.Context, userID string, opts ...base_http_client.RequestOption) iter.Seq2[contactchannels.ContactChannel, error]
+
+	// CreateContactChannel создает новый контактный канал для пользователя
+	CreateContactChannel(request *contactchannels.CreateContactChannelRequest, opts ...base_http_client.RequestOption) (*contactchannels.ContactChannelResponse, error)
+	CreateContactChannelCtx(ctx context.Context, request *contactchannels.CreateContactChannelRequest, opts ...base_http_client.RequestOption) (*contactchannels.ContactChannelResponse, error)
+
+	// VerifyEmail подтверждает email пользователя с помощью кода верификации
+	VerifyEmail(request *contactchannels.VerifyRequest, opts ...base_http_client.RequestOption) (*contactchannels.VerifyResponse, error)
+	VerifyEmailCtx(ctx context.Context, request *contactchannels.VerifyRequest, opts ...base_http_client.RequestOption) (*contactchannels.VerifyResponse, error)
+
+	// CheckEmailVerificationCode проверяет валидность кода подтверждения email
+	CheckEmailVerificationCode(request *contactchannels.CheckCodeRequest, opts ...base_http_client.RequestOption) (*contactchannels.CheckCodeResponse, error)
+	CheckEmailVerificationCodeCtx(ctx context.Context, request *contactchannels.CheckCodeRequest, opts ...base_http_client.RequestOption) (*contactchannels.CheckCodeResponse, error)
+
+	// GetContactChannel возвращает контактный канал по идентификаторам пользователя и канала
+	GetContactChannel(userID, contactChannelID string, opts ...base_http_client.RequestOption) (*contactchannels.ContactChannelResponse, error)
+	GetContactChannelCtx(ctx context.Context, userID, contactChannelID string, opts ...base_http_client.RequestOption) (*contactchannels.ContactChannelResponse, error)
+
+	// DeleteContactChannel удаляет контактный канал пользователя
+	DeleteContactChannel(userID, contactChannelID string, opts ...base_http_client.RequestOption) (*contactchannels.DeleteResponse, error)
+	DeleteContactChannelCtx(ctx context.Context, userID, contactChannelID string, opts ...base_http_client.RequestOption) (*contactchannels.DeleteResponse, error)
+
+	// UpdateContactChannel обновляет существующий контактный канал
+	UpdateContactChannel(userID, contactChannelID string, request *contactchannels.UpdateContactChannelRequest, opts ...base_http_client.RequestOption) (*contactchannels.ContactChannelResponse, error)
+	UpdateContactChannelCtx(ctx context.Context, userID, contactChannelID string, request *contactchannels.UpdateContactChannelRequest, opts ...base_http_client.RequestOption) (*contactchannels.ContactChannelResponse, error)
+
+	// SendVerificationCode отправляет код подтверждения на контактный канал
+	SendVerificationCode(userID, contactChannelID string, request *contactchannels.SendCodeRequest, opts ...base_http_client.RequestOption) (*contactchannels.SendCodeResponse, error)
+	SendVerificationCodeCtx(ctx context.Context, userID, contactChannelID string, request *contactchannels.SendCodeRequest, opts ...base_http_client.RequestOption) (*contactchannels.SendCodeResponse, error)
+}
+
+// OAuthAPI описывает операции раздела oauth. Интерфейс реализуют oauth.Client и mocks.OAuth
+type OAuthAPI interface {
+	// Token обменивает код авторизации или refresh token на access token
+	Token(request *oauth.TokenRequest, opts ...base_http_client.RequestOption) (*oauth.TokenResponse, error)
+	TokenCtx(ctx context.Context, request *oauth.TokenRequest, opts ...base_http_client.RequestOption) (*oauth.TokenResponse, error)
+
+	// Authorize инициирует OAuth авторизацию или связывание аккаунта
+	Authorize(providerID string, query *oauth.AuthorizeQuery, opts ...base_http_client.RequestOption) error
+	AuthorizeCtx(ctx context.Context, providerID string, query *oauth.AuthorizeQuery, opts ...base_http_client.RequestOption) error
+}
+
+// OthersAPI описывает операции раздела others. Интерфейс реализуют others.Client и mocks.Others
+type OthersAPI interface {
+	// ListTeamInvitations возвращает список приглашений в команду
+	ListTeamInvitations(opts ...base_http_client.RequestOption) (*others.ListTeamInvitationsResponse, error)
+	ListTeamInvitationsCtx(ctx context.Context, opts ...base_http_client.RequestOption) (*others.ListTeamInvitationsResponse, error)
+
+	// AllTeamInvitations возвращает итератор по всем приглашениям в команду, автоматически переходящий по курсорам страниц
+	AllTeamInvitations(ctx context.Context, opts ...base_http_client.RequestOption) iter.Seq2[others.TeamInvitation, error]
+
+	// DeleteTeamInvitation удаляет приглашение в команду по ID
+	DeleteTeamInvitation(id string, opts ...base_http_client.RequestOption) (*others.DeleteTeamInvitationResponse, error)
+	DeleteTeamInvitationCtx(ctx context.Context, id string, opts ...base_http_client.RequestOption) (*others.DeleteTeamInvitationResponse, error)
+
+	// ConfirmNeonTransferCheck подтверждает проверку передачи проекта Neon
+	ConfirmNeonTransferCheck(request *others.ConfirmNeonTransferCheckRequest, opts ...base_http_client.RequestOption) (*others.ConfirmNeonTransferCheckResponse, error)
+	ConfirmNeonTransferCheckCtx(ctx context.Context, request *others.ConfirmNeonTransferCheckRequest, opts ...base_http_client.RequestOption) (*others.ConfirmNeonTransferCheckResponse, error)
+}
+
+// OTPAPI описывает операции раздела otp. Интерфейс реализуют otp.Client и mocks.OTP
+type OTPAPI interface {
+	// SignInWithCode выполняет вход с использованием одноразового кода
+	SignInWithCode(request *otp.SignInWithCodeRequest, opts ...base_http_client.RequestOption) (*otp.AuthResponse, error)
+	SignInWithCodeCtx(ctx context.Context, request *otp.SignInWithCodeRequest, opts ...base_http_client.RequestOption) (*otp.AuthResponse, error)
+
+	// SendSignInCode отправляет код для входа на email пользователя
+	SendSignInCode(request *otp.SendSignInCodeRequest, opts ...base_http_client.RequestOption) (*otp.SendSignInCodeResponse, error)
+	SendSignInCodeCtx(ctx context.Context, request *otp.SendSignInCodeRequest, opts ...base_http_client.RequestOption) (*otp.SendSignInCodeResponse, error)
+
+	// MFASignIn выполняет MFA аутентификацию с использованием TOTP
+	MFASignIn(request *otp.MFASignInRequest, opts ...base_http_client.RequestOption) (*otp.AuthResponse, error)
+	MFASignInCtx(ctx context.Context, request *otp.MFASignInRequest, opts ...base_http_client.RequestOption) (*otp.AuthResponse, error)
+
+	// CheckSignInCode проверяет валидность кода для входа
+	CheckSignInCode(request *otp.CheckSignInCodeRequest, opts ...base_http_client.RequestOption) (*otp.CheckSignInCodeResponse, error)
+	CheckSignInCodeCtx(ctx context.Context, request *otp.CheckSignInCodeRequest, opts ...base_http_client.RequestOption) (*otp.CheckSignInCodeResponse, error)
+}
+
+// PasswordAPI описывает операции раздела password. Интерфейс реализуют password.Client и mocks.Password
+type PasswordAPI interface {
+	// UpdatePassword обновляет пароль текущего пользователя
+	UpdatePassword(request *password.UpdatePasswordRequest, opts ...base_http_client.RequestOption) (*password.UpdatePasswordResponse, error)
+	UpdatePasswordCtx(ctx context.Context, request *password.UpdatePasswordRequest, opts ...base_http_client.RequestOption) (*password.UpdatePasswordResponse, error)
+
+	// SignUpWithEmail создает новую учетную запись с email и паролем
+	SignUpWithEmail(request *password.SignUpWithEmailRequest, opts ...base_http_client.RequestOption) (*password.SignUpResponse, error)
+	SignUpWithEmailCtx(ctx context.Context, request *password.SignUpWithEmailRequest, opts ...base_http_client.RequestOption) (*password.SignUpResponse, error)
+
+	// SignInWithEmail выполняет вход в учетную запись с email и паролем
+	SignInWithEmail(request *password.SignInRequest, opts ...base_http_client.RequestOption) (*password.SignInResponse, error)
+	SignInWithEmailCtx(ctx context.Context, request *password.SignInRequest, opts ...base_http_client.RequestOption) (*password.SignInResponse, error)
+
+	// SetPassword устанавливает новый пароль для текущего пользователя
+	SetPassword(request *password.SetPasswordRequest, opts ...base_http_client.RequestOption) (*password.SetPasswordResponse, error)
+	SetPasswordCtx(ctx context.Context, request *password.SetPasswordRequest, opts ...base_http_client.RequestOption) (*password.SetPasswordResponse, error)
+
+	// SendResetPasswordCode отправляет код сброса пароля на email
+	SendResetPasswordCode(request *password.SendResetCodeRequest, opts ...base_http_client.RequestOption) (*password.SendResetCodeResponse, error)
+	SendResetPasswordCodeCtx(ctx context.Context, request *password.SendResetCodeRequest, opts ...base_http_client.RequestOption) (*password.SendResetCodeResponse, error)
+
+	// ResetPasswordWithCode сбрасывает пароль с использованием кода
+	ResetPasswordWithCode(request *password.ResetPasswordRequest, opts ...base_http_client.RequestOption) (*password.ResetPasswordResponse, error)
+	ResetPasswordWithCodeCtx(ctx context.Context, request *password.ResetPasswordRequest, opts ...base_http_client.RequestOption) (*password.ResetPasswordResponse, error)
+
+	// CheckResetPasswordCode проверяет валидность кода сброса пароля
+	CheckResetPasswordCode(request *password.CheckCodeRequest, opts ...base_http_client.RequestOption) (*password.CheckCodeResponse, error)
+	CheckResetPasswordCodeCtx(ctx context.Context, request *password.CheckCodeRequest, opts ...base_http_client.RequestOption) (*password.CheckCodeResponse, error)
+}
+
+// PermissionsAPI описывает операции раздела permissions. Интерфейс реализуют permissions.Client и mocks.Permissions
+type PermissionsAPI interface {
+	// ListTeamPermissions возвращает список командных разрешений пользователя
+	ListTeamPermissions(options *permissions.ListTeamPermissionsOptions, opts ...base_http_client.RequestOption) (*permissions.ListTeamPermissionsResponse, error)
+	ListTeamPermissionsCtx(ctx context.Context, options *permissions.ListTeamPermissionsOptions, opts ...base_http_client.RequestOption) (*permissions.ListTeamPermissionsResponse, error)
+
+	// AllTeamPermissions возвращает итератор по всем командным разрешениям, автоматически переходящий по курсорам страниц
+	AllTeamPermissions(ctx context.Context, options *permissions.ListTeamPermissionsOptions, opts ...base_http_client.RequestOption) iter.Seq2[permissions.TeamPermission, error]
+
+	// GrantTeamPermissionToUser выдает пользователю командное разрешение
+	GrantTeamPermissionToUser(teamID, userID, permissionID, recursive string, opts ...base_http_client.RequestOption) (*permissions.GrantTeamPermissionResponse, error)
+	GrantTeamPermissionToUserCtx(ctx context.Context, teamID, userID, permissionID, recursive string, opts ...base_http_client.RequestOption) (*permissions.GrantTeamPermissionResponse, error)
+
+	// RevokeTeamPermissionFromUser отзывает командное разрешение у пользователя
+	RevokeTeamPermissionFromUser(teamID, userID, permissionID, recursive string, opts ...base_http_client.RequestOption) (*permissions.RevokeTeamPermissionResponse, error)
+	RevokeTeamPermissionFromUserCtx(ctx context.Context, teamID, userID, permissionID, recursive string, opts ...base_http_client.RequestOption) (*permissions.RevokeTeamPermissionResponse, error)
+}
+
+// ProjectsAPI описывает операции раздела projects. Интерфейс реализуют projects.Client и mocks.Projects
+type ProjectsAPI interface {
+	// GetCurrentProject возвращает информацию о текущем проекте
+	GetCurrentProject(opts ...base_http_client.RequestOption) (*projects.GetCurrentProjectResponse, error)
+	GetCurrentProjectCtx(ctx context.Context, opts ...base_http_client.RequestOption) (*projects.GetCurrentProjectResponse, error)
+}
+
+// SessionsAPI описывает операции раздела sessions. Интерфейс реализуют sessions.Client и mocks.Sessions
+type SessionsAPI interface {
+	// CreateSession создает новую сессию для пользователя
+	CreateSession(request *sessions.CreateSessionRequest, opts ...base_http_client.RequestOption) (*sessions.CreateSessionResponse, error)
+	CreateSessionCtx(ctx context.Context, request *sessions.CreateSessionRequest, opts ...base_http_client.RequestOption) (*sessions.CreateSessionResponse, error)
+
+	// SignOut завершает текущую сессию пользователя
+	SignOut(refreshToken string, opts ...base_http_client.RequestOption) (*sessions.SignOutResponse, error)
+	SignOutCtx(ctx context.Context, refreshToken string, opts ...base_http_client.RequestOption) (*sessions.SignOutResponse, error)
+
+	// RefreshAccessToken обновляет access token с использованием refresh token
+	RefreshAccessToken(refreshToken string, opts ...base_http_client.RequestOption) (*sessions.RefreshAccessTokenResponse, error)
+	RefreshAccessTokenCtx(ctx context.Context, refreshToken string, opts ...base_http_client.RequestOption) (*sessions.RefreshAccessTokenResponse, error)
+}
+
+// TeamsAPI описывает операции раздела teams. Интерфейс реализуют teams.Client и mocks.Teams
+type TeamsAPI interface {
+	// ListTeams возвращает список команд проекта
+	ListTeams(options *teams.ListTeamsOptions, opts ...base_http_client.RequestOption) (*teams.ListTeamsResponse, error)
+	ListTeamsCtx(ctx context.Context, options *teams.ListTeamsOptions, opts ...base_http_client.RequestOption) (*teams.ListTeamsResponse, error)
+
+	// AllTeams возвращает итератор по всем командам, автоматически переходящий по курсорам страниц
+	AllTeams(ctx context.Context, options *teams.ListTeamsOptions, opts ...base_http_client.RequestOption) iter.Seq2[teams.TeamResponse, error]
+
+	// CreateTeam создает новую команду
+	CreateTeam(request *teams.CreateTeamRequest, opts ...base_http_client.RequestOption) (*teams.TeamResponse, error)
+	CreateTeamCtx(ctx context.Context, request *teams.CreateTeamRequest, opts ...base_http_client.RequestOption) (*teams.TeamResponse, error)
+
+	// GetTeam возвращает информацию о команде по ID
+	GetTeam(teamID string, opts ...base_http_client.RequestOption) (*teams.TeamResponse, error)
+	GetTeamCtx(ctx context.Context, teamID string, opts ...base_http_client.RequestOption) (*teams.TeamResponse, error)
+
+	// DeleteTeam удаляет команду по ID
+	DeleteTeam(teamID string, opts ...base_http_client.RequestOption) (*teams.SuccessResponse, error)
+	DeleteTeamCtx(ctx context.Context, teamID string, opts ...base_http_client.RequestOption) (*teams.SuccessResponse, error)
+
+	// UpdateTeam обновляет информацию о команде
+	UpdateTeam(teamID string, request *teams.UpdateTeamRequest, opts ...base_http_client.RequestOption) (*teams.TeamResponse, error)
+	UpdateTeamCtx(ctx context.Context, teamID string, request *teams.UpdateTeamRequest, opts ...base_http_client.RequestOption) (*teams.TeamResponse, error)
+
+	// ListTeamMembersProfiles возвращает профили участников команды
+	ListTeamMembersProfiles(options *teams.ListTeamMembersProfilesOptions, opts ...base_http_client.RequestOption) (*teams.ListTeamMembersResponse, error)
+	ListTeamMembersProfilesCtx(ctx context.Context, options *teams.ListTeamMembersProfilesOptions, opts ...base_http_client.RequestOption) (*teams.ListTeamMembersResponse, error)
+
+	// AllTeamMembersProfiles возвращает итератор по всем профилям участников команды, автоматически переходящий по курсорам страниц
+	AllTeamMembersProfiles(ctx context.Context, options *teams.ListTeamMembersProfilesOptions, opts ...base_http_client.RequestOption) iter.Seq2[teams.TeamMemberProfileResponse, error]
+
+	// SendInviteEmail отправляет приглашение в команду по email
+	SendInviteEmail(request *teams.SendInviteEmailRequest, opts ...base_http_client.RequestOption) (*teams.SuccessResponse, error)
+	SendInviteEmailCtx(ctx context.Context, request *teams.SendInviteEmailRequest, opts ...base_http_client.RequestOption) (*teams.SuccessResponse, error)
+
+	// AcceptInvite принимает приглашение в команду
+	AcceptInvite(request *teams.AcceptInviteRequest, opts ...base_http_client.RequestOption) error
+	AcceptInviteCtx(ctx context.Context, request *teams.AcceptInviteRequest, opts ...base_http_client.RequestOption) error
+
+	// AddTeamMember добавляет пользователя в команду
+	AddTeamMember(teamID, userID string, opts ...base_http_client.RequestOption) (*teams.TeamMembershipResponse, error)
+	AddTeamMemberCtx(ctx context.Context, teamID, userID string, opts ...base_http_client.RequestOption) (*teams.TeamMembershipResponse, error)
+
+	// RemoveTeamMember удаляет пользователя из команды
+	RemoveTeamMember(teamID, userID string, opts ...base_http_client.RequestOption) (*teams.SuccessResponse, error)
+	RemoveTeamMemberCtx(ctx context.Context, teamID, userID string, opts ...base_http_client.RequestOption) (*teams.SuccessResponse, error)
+
+	// GetTeamMemberProfile возвращает профиль участника команды
+	GetTeamMemberProfile(teamID, userID string, opts ...base_http_client.RequestOption) (*teams.TeamMemberProfileResponse, error)
+	GetTeamMemberProfileCtx(ctx context.Context, teamID, userID string, opts ...base_http_client.RequestOption) (*teams.TeamMemberProfileResponse, error)
+
+	// UpdateTeamMemberProfile обновляет профиль участника команды
+	UpdateTeamMemberProfile(teamID, userID string, request *teams.UpdateTeamMemberProfileRequest, opts ...base_http_client.RequestOption) (*teams.TeamMemberProfileResponse, error)
+	UpdateTeamMemberProfileCtx(ctx context.Context, teamID, userID string, request *teams.UpdateTeamMemberProfileRequest, opts ...base_http_client.RequestOption) (*teams.TeamMemberProfileResponse, error)
+
+	// GetInvitationDetails возвращает информацию о приглашении
+	GetInvitationDetails(code string, opts ...base_http_client.RequestOption) (*teams.InvitationDetailsResponse, error)
+	GetInvitationDetailsCtx(ctx context.Context, code string, opts ...base_http_client.RequestOption) (*teams.InvitationDetailsResponse, error)
+
+	// CheckInviteCode проверяет валидность кода приглашения
+	CheckInviteCode(code string, opts ...base_http_client.RequestOption) (*teams.CheckCodeResponse, error)
+	CheckInviteCodeCtx(ctx context.Context, code string, opts ...base_http_client.RequestOption) (*teams.CheckCodeResponse, error)
+}
+
+// UsersAPI описывает операции раздела users. Интерфейс реализуют users.Client и mocks.Users
+type UsersAPI interface {
+	// ListUsers возвращает список пользователей проекта
+	ListUsers(options *users.ListUsersOptions, opts ...base_http_client.RequestOption) (*users.ListUsersResponse, error)
+	ListUsersCtx(ctx context.Context, options *users.ListUsersOptions, opts ...base_http_client.RequestOption) (*users.ListUsersResponse, error)
+
+	// AllUsers возвращает итератор по всем пользователям проекта, автоматически переходящий по курсорам страниц
+	AllUsers(ctx context.Context, options *users.ListUsersOptions, opts ...base_http_client.RequestOption) iter.Seq2[users.User, error]
+
+	// CreateUser создает нового пользователя
+	CreateUser(request *users.CreateUserRequest, opts ...base_http_client.RequestOption) (*users.UserResponse, error)
+	CreateUserCtx(ctx context.Context, request *users.CreateUserRequest, opts ...base_http_client.RequestOption) (*users.UserResponse, error)
+
+	// GetCurrentUser возвращает данные текущего аутентифицированного пользователя
+	GetCurrentUser(opts ...base_http_client.RequestOption) (*users.UserResponse, error)
+	GetCurrentUserCtx(ctx context.Context, opts ...base_http_client.RequestOption) (*users.UserResponse, error)
+
+	// DeleteCurrentUser удаляет текущего аутентифицированного пользователя
+	DeleteCurrentUser(opts ...base_http_client.RequestOption) (*users.SuccessResponse, error)
+	DeleteCurrentUserCtx(ctx context.Context, opts ...base_http_client.RequestOption) (*users.SuccessResponse, error)
+
+	// UpdateCurrentUser обновляет данные текущего пользователя
+	UpdateCurrentUser(request *users.UpdateUserRequest, opts ...base_http_client.RequestOption) (*users.UserResponse, error)
+	UpdateCurrentUserCtx(ctx context.Context, request *users.UpdateUserRequest, opts ...base_http_client.RequestOption) (*users.UserResponse, error)
+
+	// GetUser возвращает пользователя по ID
+	GetUser(userID string, opts ...base_http_client.RequestOption) (*users.UserResponse, error)
+	GetUserCtx(ctx context.Context, userID string, opts ...base_http_client.RequestOption) (*users.UserResponse, error)
+
+	// DeleteUser удаляет пользователя по ID
+	DeleteUser(userID string, opts ...base_http_client.RequestOption) (*users.SuccessResponse, error)
+	DeleteUserCtx(ctx context.Context, userID string, opts ...base_http_client.RequestOption) (*users.SuccessResponse, error)
+
+	// UpdateUser обновляет данные пользователя по ID
+	UpdateUser(userID string, request *users.UpdateUserRequest, opts ...base_http_client.RequestOption) (*users.UserResponse, error)
+	UpdateUserCtx(ctx context.Context, userID string, request *users.UpdateUserRequest, opts ...base_http_client.RequestOption) (*users.UserResponse, error)
+}
+
+// Проверка того, что клиенты разделов реализуют интерфейсы
+var (
+	_ ContactChannelsAPI = (*contactchannels.Client)(nil)
+	_ OAuthAPI           = (*oauth.Client)(nil)
+	_ OthersAPI          = (*others.Client)(nil)
+	_ OTPAPI             = (*otp.Client)(nil)
+	_ PasswordAPI        = (*password.Client)(nil)
+	_ PermissionsAPI     = (*permissions.Client)(nil)
+	_ ProjectsAPI        = (*projects.Client)(nil)
+	_ SessionsAPI        = (*sessions.Client)(nil)
+	_ TeamsAPI           = (*teams.Client)(nil)
+	_ UsersAPI           = (*users.Client)(nil)
+)
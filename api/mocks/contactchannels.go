@@ -0,0 +1,129 @@
+package mocks
+
+import (
+	"context"
+	"iter"
+
+	"github.com/BlaisePopov/stack-auth/api/contactchannels"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+// ContactChannels - заглушка api.ContactChannelsAPI с записью вызовов.
+// Поведение метода задается полем <Метод>Func, незаданный метод возвращает ErrNotMocked.
+type ContactChannels struct {
+	Recorder
+
+	ListContactChannelsFunc        func(ctx context.Context, userID, contactChannelID string, opts ...base_http_client.RequestOption) (*contactchannels.ListContactChannelsResponse, error)
+	AllContactChannelsFunc         func(ctx context.Context, userID string, opts ...base_http_client.RequestOption) iter.Seq2[contactchannels.ContactChannel, error]
+	CreateContactChannelFunc       func(ctx context.Context, request *contactchannels.CreateContactChannelRequest, opts ...base_http_client.RequestOption) (*contactchannels.ContactChannelResponse, error)
+	VerifyEmailFunc                func(ctx context.Context, request *contactchannels.VerifyRequest, opts ...base_http_client.RequestOption) (*contactchannels.VerifyResponse, error)
+	CheckEmailVerificationCodeFunc func(ctx context.Context, request *contactchannels.CheckCodeRequest, opts ...base_http_client.RequestOption) (*contactchannels.CheckCodeResponse, error)
+	GetContactChannelFunc          func(ctx context.Context, userID, contactChannelID string, opts ...base_http_client.RequestOption) (*contactchannels.ContactChannelResponse, error)
+	DeleteContactChannelFunc       func(ctx context.Context, userID, contactChannelID string, opts ...base_http_client.RequestOption) (*contactchannels.DeleteResponse, error)
+	UpdateContactChannelFunc       func(ctx context.Context, userID, contactChannelID string, request *contactchannels.UpdateContactChannelRequest, opts ...base_http_client.RequestOption) (*contactchannels.ContactChannelResponse, error)
+	SendVerificationCodeFunc       func(ctx context.Context, userID, contactChannelID string, request *contactchannels.SendCodeRequest, opts ...base_http_client.RequestOption) (*contactchannels.SendCodeResponse, error)
+}
+
+func (m *ContactChannels) ListContactChannels(userID, contactChannelID string, opts ...base_http_client.RequestOption) (*contactchannels.ListContactChannelsResponse, error) {
+	return m.ListContactChannelsCtx(context.Background(), userID, contactChannelID, opts...)
+}
+
+func (m *ContactChannels) ListContactChannelsCtx(ctx context.Context, userID, contactChannelID string, opts ...base_http_client.RequestOption) (*contactchannels.ListContactChannelsResponse, error) {
+	m.record("ListContactChannels", userID, contactChannelID)
+	if m.ListContactChannelsFunc == nil {
+		return nil, notMocked("contactchannels.ListContactChannels")
+	}
+	return m.ListContactChannelsFunc(ctx, userID, contactChannelID, opts...)
+}
+
+func (m *ContactChannels) AllContactChannels(ctx context.Context, userID string, opts ...base_http_client.RequestOption) iter.Seq2[contactchannels.ContactChannel, error] {
+	m.record("AllContactChannels", userID)
+	if m.AllContactChannelsFunc == nil {
+		return notMockedSeq[contactchannels.ContactChannel]("contactchannels.AllContactChannels")
+	}
+	return m.AllContactChannelsFunc(ctx, userID, opts...)
+}
+
+func (m *ContactChannels) CreateContactChannel(request *contactchannels.CreateContactChannelRequest, opts ...base_http_client.RequestOption) (*contactchannels.ContactChannelResponse, error) {
+	return m.CreateContactChannelCtx(context.Background(), request, opts...)
+}
+
+func (m *ContactChannels) CreateContactChannelCtx(ctx context.Context, request *contactchannels.CreateContactChannelRequest, opts ...base_http_client.RequestOption) (*contactchannels.ContactChannelResponse, error) {
+	m.record("CreateContactChannel", request)
+	if m.CreateContactChannelFunc == nil {
+		return nil, notMocked("contactchannels.CreateContactChannel")
+	}
+	return m.CreateContactChannelFunc(ctx, request, opts...)
+}
+
+func (m *ContactChannels) VerifyEmail(request *contactchannels.VerifyRequest, opts ...base_http_client.RequestOption) (*contactchannels.VerifyResponse, error) {
+	return m.VerifyEmailCtx(context.Background(), request, opts...)
+}
+
+func (m *ContactChannels) VerifyEmailCtx(ctx context.Context, request *contactchannels.VerifyRequest, opts ...base_http_client.RequestOption) (*contactchannels.VerifyResponse, error) {
+	m.record("VerifyEmail", request)
+	if m.VerifyEmailFunc == nil {
+		return nil, notMocked("contactchannels.VerifyEmail")
+	}
+	return m.VerifyEmailFunc(ctx, request, opts...)
+}
+
+func (m *ContactChannels) CheckEmailVerificationCode(request *contactchannels.CheckCodeRequest, opts ...base_http_client.RequestOption) (*contactchannels.CheckCodeResponse, error) {
+	return m.CheckEmailVerificationCodeCtx(context.Background(), request, opts...)
+}
+
+func (m *ContactChannels) CheckEmailVerificationCodeCtx(ctx context.Context, request *contactchannels.CheckCodeRequest, opts ...base_http_client.RequestOption) (*contactchannels.CheckCodeResponse, error) {
+	m.record("CheckEmailVerificationCode", request)
+	if m.CheckEmailVerificationCodeFunc == nil {
+		return nil, notMocked("contactchannels.CheckEmailVerificationCode")
+	}
+	return m.CheckEmailVerificationCodeFunc(ctx, request, opts...)
+}
+
+func (m *ContactChannels) GetContactChannel(userID, contactChannelID string, opts ...base_http_client.RequestOption) (*contactchannels.ContactChannelResponse, error) {
+	return m.GetContactChannelCtx(context.Background(), userID, contactChannelID, opts...)
+}
+
+func (m *ContactChannels) GetContactChannelCtx(ctx context.Context, userID, contactChannelID string, opts ...base_http_client.RequestOption) (*contactchannels.ContactChannelResponse, error) {
+	m.record("GetContactChannel", userID, contactChannelID)
+	if m.GetContactChannelFunc == nil {
+		return nil, notMocked("contactchannels.GetContactChannel")
+	}
+	return m.GetContactChannelFunc(ctx, userID, contactChannelID, opts...)
+}
+
+func (m *ContactChannels) DeleteContactChannel(userID, contactChannelID string, opts ...base_http_client.RequestOption) (*contactchannels.DeleteResponse, error) {
+	return m.DeleteContactChannelCtx(context.Background(), userID, contactChannelID, opts...)
+}
+
+func (m *ContactChannels) DeleteContactChannelCtx(ctx context.Context, userID, contactChannelID string, opts ...base_http_client.RequestOption) (*contactchannels.DeleteResponse, error) {
+	m.record("DeleteContactChannel", userID, contactChannelID)
+	if m.DeleteContactChannelFunc == nil {
+		return nil, notMocked("contactchannels.DeleteContactChannel")
+	}
+	return m.DeleteContactChannelFunc(ctx, userID, contactChannelID, opts...)
+}
+
+func (m *ContactChannels) UpdateContactChannel(userID, contactChannelID string, request *contactchannels.UpdateContactChannelRequest, opts ...base_http_client.RequestOption) (*contactchannels.ContactChannelResponse, error) {
+	return m.UpdateContactChannelCtx(context.Background(), userID, contactChannelID, request, opts...)
+}
+
+func (m *ContactChannels) UpdateContactChannelCtx(ctx context.Context, userID, contactChannelID string, request *contactchannels.UpdateContactChannelRequest, opts ...base_http_client.RequestOption) (*contactchannels.ContactChannelResponse, error) {
+	m.record("UpdateContactChannel", userID, contactChannelID, request)
+	if m.UpdateContactChannelFunc == nil {
+		return nil, notMocked("contactchannels.UpdateContactChannel")
+	}
+	return m.UpdateContactChannelFunc(ctx, userID, contactChannelID, request, opts...)
+}
+
+func (m *ContactChannels) SendVerificationCode(userID, contactChannelID string, request *contactchannels.SendCodeRequest, opts ...base_http_client.RequestOption) (*contactchannels.SendCodeResponse, error) {
+	return m.SendVerificationCodeCtx(context.Background(), userID, contactChannelID, request, opts...)
+}
+
+func (m *ContactChannels) SendVerificationCodeCtx(ctx context.Context, userID, contactChannelID string, request *contactchannels.SendCodeRequest, opts ...base_http_client.RequestOption) (*contactchannels.SendCodeResponse, error) {
+	m.record("SendVerificationCode", userID, contactChannelID, request)
+	if m.SendVerificationCodeFunc == nil {
+		return nil, notMocked("contactchannels.SendVerificationCode")
+	}
+	return m.SendVerificationCodeFunc(ctx, userID, contactChannelID, request, opts...)
+}
@@ -0,0 +1,104 @@
+// Package mocks содержит заглушки разделов API с записью вызовов для модульных тестов.
+//
+// Каждая заглушка реализует соответствующий интерфейс пакета api (UsersAPI, TeamsAPI и т.д.).
+// Поведение метода задается полем с суффиксом Func, а все вызовы сохраняются во встроенном Recorder:
+//
+//	stub := &mocks.Users{
+//		GetUserFunc: func(ctx context.Context, userID string, opts ...base_http_client.RequestOption) (*users.UserResponse, error) {
+//			return &users.UserResponse{User: users.User{ID: userID}}, nil
+//		},
+//	}
+//	handler := NewHandler(&api.Services{Users: stub})
+//	...
+//	calls := stub.CallsTo("GetUser")
+package mocks
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"sync"
+
+	"github.com/BlaisePopov/stack-auth/api"
+)
+
+// ErrNotMocked возвращается методом заглушки, для которого не задана функция
+var ErrNotMocked = errors.New("метод заглушки не задан")
+
+// notMocked формирует ошибку для незаданного метода операции
+func notMocked(operation string) error {
+	return fmt.Errorf("%w: %s", ErrNotMocked, operation)
+}
+
+// notMockedSeq возвращает итератор, выдающий ошибку ErrNotMocked для незаданного метода операции
+func notMockedSeq[T any](operation string) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		yield(zero, notMocked(operation))
+	}
+}
+
+// Call описывает вызов метода заглушки
+type Call struct {
+	// Method - имя метода без суффикса Ctx
+	Method string
+	// Args - аргументы вызова без контекста и опций запроса
+	Args []interface{}
+}
+
+// Recorder сохраняет вызовы методов заглушки. Методы Recorder безопасны для конкурентного использования
+type Recorder struct {
+	mu    sync.Mutex
+	calls []Call
+}
+
+// Calls возвращает все вызовы в порядке их выполнения
+func (r *Recorder) Calls() []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Call(nil), r.calls...)
+}
+
+// CallsTo возвращает вызовы метода в порядке их выполнения
+func (r *Recorder) CallsTo(method string) []Call {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var calls []Call
+	for _, call := range r.calls {
+		if call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset удаляет сохраненные вызовы
+func (r *Recorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = nil
+}
+
+func (r *Recorder) record(method string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, Call{Method: method, Args: args})
+}
+
+// Проверка того, что заглушки реализуют интерфейсы разделов API
+var (
+	_ api.ContactChannelsAPI = (*ContactChannels)(nil)
+	_ api.OAuthAPI           = (*OAuth)(nil)
+	_ api.OthersAPI          = (*Others)(nil)
+	_ api.OTPAPI             = (*OTP)(nil)
+	_ api.PasswordAPI        = (*Password)(nil)
+	_ api.PermissionsAPI     = (*Permissions)(nil)
+	_ api.ProjectsAPI        = (*Projects)(nil)
+	_ api.SessionsAPI        = (*Sessions)(nil)
+	_ api.TeamsAPI           = (*Teams)(nil)
+	_ api.UsersAPI           = (*Users)(nil)
+)
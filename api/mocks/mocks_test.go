@@ -0,0 +1,93 @@
+package mocks
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"testing"
+
+	"github.com/BlaisePopov/stack-auth/api"
+	"github.com/BlaisePopov/stack-auth/api/teams"
+	"github.com/BlaisePopov/stack-auth/api/users"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+	"github.com/stretchr/testify/assert"
+)
+
+// greet - пример потребителя, зависящего только от интерфейсов
+func greet(ctx context.Context, services *api.Services, userID string) (string, error) {
+	user, err := services.Users.GetUserCtx(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return "Hello, " + user.DisplayName, nil
+}
+
+func TestUsers_RecordsCallsAndDelegates(t *testing.T) {
+	stub := &Users{
+		GetUserFunc: func(ctx context.Context, userID string, opts ...base_http_client.RequestOption) (*users.UserResponse, error) {
+			return &users.UserResponse{User: users.User{ID: userID, DisplayName: "John"}}, nil
+		},
+	}
+
+	greeting, err := greet(context.Background(), &api.Services{Users: stub}, "user-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "Hello, John", greeting)
+
+	_, err = stub.GetUser("user-2", base_http_client.WithTimeout(0))
+	assert.NoError(t, err)
+	assert.Equal(t, []Call{
+		{Method: "GetUser", Args: []interface{}{"user-1"}},
+		{Method: "GetUser", Args: []interface{}{"user-2"}},
+	}, stub.CallsTo("GetUser"))
+
+	stub.Reset()
+	assert.Empty(t, stub.Calls())
+}
+
+func TestUsers_AllUsers(t *testing.T) {
+	stub := &Users{
+		AllUsersFunc: func(ctx context.Context, options *users.ListUsersOptions, opts ...base_http_client.RequestOption) iter.Seq2[users.User, error] {
+			return func(yield func(users.User, error) bool) {
+				for _, id := range []string{"user-1", "user-2"} {
+					if !yield(users.User{ID: id}, nil) {
+						return
+					}
+				}
+			}
+		},
+	}
+	var services api.UsersAPI = stub
+
+	var ids []string
+	for user, err := range services.AllUsers(context.Background(), nil) {
+		assert.NoError(t, err)
+		ids = append(ids, user.ID)
+	}
+	assert.Equal(t, []string{"user-1", "user-2"}, ids)
+	assert.Len(t, stub.CallsTo("AllUsers"), 1)
+
+	for _, err := range (&Teams{}).AllTeams(context.Background(), nil) {
+		assert.ErrorIs(t, err, ErrNotMocked)
+		assert.Contains(t, err.Error(), "teams.AllTeams")
+	}
+}
+
+func TestTeams_NotMocked(t *testing.T) {
+	stub := &Teams{}
+
+	_, err := stub.GetTeam("team-1")
+	assert.True(t, errors.Is(err, ErrNotMocked))
+	assert.Contains(t, err.Error(), "teams.GetTeam")
+
+	err = stub.AcceptInvite(&teams.AcceptInviteRequest{Code: "code"})
+	assert.ErrorIs(t, err, ErrNotMocked)
+	assert.Len(t, stub.Calls(), 2)
+}
+
+func TestClientServices_UsesConcreteClients(t *testing.T) {
+	client := api.NewClient(base_http_client.Config{})
+	services := client.Services()
+
+	assert.Same(t, client.Users, services.Users)
+	assert.Same(t, client.Teams, services.Teams)
+}
@@ -0,0 +1,41 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/BlaisePopov/stack-auth/api/oauth"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+// OAuth - заглушка api.OAuthAPI с записью вызовов.
+// Поведение метода задается полем <Метод>Func, незаданный метод возвращает ErrNotMocked.
+type OAuth struct {
+	Recorder
+
+	TokenFunc     func(ctx context.Context, request *oauth.TokenRequest, opts ...base_http_client.RequestOption) (*oauth.TokenResponse, error)
+	AuthorizeFunc func(ctx context.Context, providerID string, query *oauth.AuthorizeQuery, opts ...base_http_client.RequestOption) error
+}
+
+func (m *OAuth) Token(request *oauth.TokenRequest, opts ...base_http_client.RequestOption) (*oauth.TokenResponse, error) {
+	return m.TokenCtx(context.Background(), request, opts...)
+}
+
+func (m *OAuth) TokenCtx(ctx context.Context, request *oauth.TokenRequest, opts ...base_http_client.RequestOption) (*oauth.TokenResponse, error) {
+	m.record("Token", request)
+	if m.TokenFunc == nil {
+		return nil, notMocked("oauth.Token")
+	}
+	return m.TokenFunc(ctx, request, opts...)
+}
+
+func (m *OAuth) Authorize(providerID string, query *oauth.AuthorizeQuery, opts ...base_http_client.RequestOption) error {
+	return m.AuthorizeCtx(context.Background(), providerID, query, opts...)
+}
+
+func (m *OAuth) AuthorizeCtx(ctx context.Context, providerID string, query *oauth.AuthorizeQuery, opts ...base_http_client.RequestOption) error {
+	m.record("Authorize", providerID, query)
+	if m.AuthorizeFunc == nil {
+		return notMocked("oauth.Authorize")
+	}
+	return m.AuthorizeFunc(ctx, providerID, query, opts...)
+}
@@ -0,0 +1,64 @@
+package mocks
+
+import (
+	"context"
+	"iter"
+
+	"github.com/BlaisePopov/stack-auth/api/others"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+// Others - заглушка api.OthersAPI с записью вызовов.
+// Поведение метода задается полем <Метод>Func, незаданный метод возвращает ErrNotMocked.
+type Others struct {
+	Recorder
+
+	ListTeamInvitationsFunc      func(ctx context.Context, opts ...base_http_client.RequestOption) (*others.ListTeamInvitationsResponse, error)
+	AllTeamInvitationsFunc       func(ctx context.Context, opts ...base_http_client.RequestOption) iter.Seq2[others.TeamInvitation, error]
+	DeleteTeamInvitationFunc     func(ctx context.Context, id string, opts ...base_http_client.RequestOption) (*others.DeleteTeamInvitationResponse, error)
+	ConfirmNeonTransferCheckFunc func(ctx context.Context, request *others.ConfirmNeonTransferCheckRequest, opts ...base_http_client.RequestOption) (*others.ConfirmNeonTransferCheckResponse, error)
+}
+
+func (m *Others) ListTeamInvitations(opts ...base_http_client.RequestOption) (*others.ListTeamInvitationsResponse, error) {
+	return m.ListTeamInvitationsCtx(context.Background(), opts...)
+}
+
+func (m *Others) ListTeamInvitationsCtx(ctx context.Context, opts ...base_http_client.RequestOption) (*others.ListTeamInvitationsResponse, error) {
+	m.record("ListTeamInvitations")
+	if m.ListTeamInvitationsFunc == nil {
+		return nil, notMocked("others.ListTeamInvitations")
+	}
+	return m.ListTeamInvitationsFunc(ctx, opts...)
+}
+
+func (m *Others) AllTeamInvitations(ctx context.Context, opts ...base_http_client.RequestOption) iter.Seq2[others.TeamInvitation, error] {
+	m.record("AllTeamInvitations")
+	if m.AllTeamInvitationsFunc == nil {
+		return notMockedSeq[others.TeamInvitation]("others.AllTeamInvitations")
+	}
+	return m.AllTeamInvitationsFunc(ctx, opts...)
+}
+
+func (m *Others) DeleteTeamInvitation(id string, opts ...base_http_client.RequestOption) (*others.DeleteTeamInvitationResponse, error) {
+	return m.DeleteTeamInvitationCtx(context.Background(), id, opts...)
+}
+
+func (m *Others) DeleteTeamInvitationCtx(ctx context.Context, id string, opts ...base_http_client.RequestOption) (*others.DeleteTeamInvitationResponse, error) {
+	m.record("DeleteTeamInvitation", id)
+	if m.DeleteTeamInvitationFunc == nil {
+		return nil, notMocked("others.DeleteTeamInvitation")
+	}
+	return m.DeleteTeamInvitationFunc(ctx, id, opts...)
+}
+
+func (m *Others) ConfirmNeonTransferCheck(request *others.ConfirmNeonTransferCheckRequest, opts ...base_http_client.RequestOption) (*others.ConfirmNeonTransferCheckResponse, error) {
+	return m.ConfirmNeonTransferCheckCtx(context.Background(), request, opts...)
+}
+
+func (m *Others) ConfirmNeonTransferCheckCtx(ctx context.Context, request *others.ConfirmNeonTransferCheckRequest, opts ...base_http_client.RequestOption) (*others.ConfirmNeonTransferCheckResponse, error) {
+	m.record("ConfirmNeonTransferCheck", request)
+	if m.ConfirmNeonTransferCheckFunc == nil {
+		return nil, notMocked("others.ConfirmNeonTransferCheck")
+	}
+	return m.ConfirmNeonTransferCheckFunc(ctx, request, opts...)
+}
@@ -0,0 +1,67 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/BlaisePopov/stack-auth/api/otp"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+// OTP - заглушка api.OTPAPI с записью вызовов.
+// Поведение метода задается полем <Метод>Func, незаданный метод возвращает ErrNotMocked.
+type OTP struct {
+	Recorder
+
+	SignInWithCodeFunc  func(ctx context.Context, request *otp.SignInWithCodeRequest, opts ...base_http_client.RequestOption) (*otp.AuthResponse, error)
+	SendSignInCodeFunc  func(ctx context.Context, request *otp.SendSignInCodeRequest, opts ...base_http_client.RequestOption) (*otp.SendSignInCodeResponse, error)
+	MFASignInFunc       func(ctx context.Context, request *otp.MFASignInRequest, opts ...base_http_client.RequestOption) (*otp.AuthResponse, error)
+	CheckSignInCodeFunc func(ctx context.Context, request *otp.CheckSignInCodeRequest, opts ...base_http_client.RequestOption) (*otp.CheckSignInCodeResponse, error)
+}
+
+func (m *OTP) SignInWithCode(request *otp.SignInWithCodeRequest, opts ...base_http_client.RequestOption) (*otp.AuthResponse, error) {
+	return m.SignInWithCodeCtx(context.Background(), request, opts...)
+}
+
+func (m *OTP) SignInWithCodeCtx(ctx context.Context, request *otp.SignInWithCodeRequest, opts ...base_http_client.RequestOption) (*otp.AuthResponse, error) {
+	m.record("SignInWithCode", request)
+	if m.SignInWithCodeFunc == nil {
+		return nil, notMocked("otp.SignInWithCode")
+	}
+	return m.SignInWithCodeFunc(ctx, request, opts...)
+}
+
+func (m *OTP) SendSignInCode(request *otp.SendSignInCodeRequest, opts ...base_http_client.RequestOption) (*otp.SendSignInCodeResponse, error) {
+	return m.SendSignInCodeCtx(context.Background(), request, opts...)
+}
+
+func (m *OTP) SendSignInCodeCtx(ctx context.Context, request *otp.SendSignInCodeRequest, opts ...base_http_client.RequestOption) (*otp.SendSignInCodeResponse, error) {
+	m.record("SendSignInCode", request)
+	if m.SendSignInCodeFunc == nil {
+		return nil, notMocked("otp.SendSignInCode")
+	}
+	return m.SendSignInCodeFunc(ctx, request, opts...)
+}
+
+func (m *OTP) MFASignIn(request *otp.MFASignInRequest, opts ...base_http_client.RequestOption) (*otp.AuthResponse, error) {
+	return m.MFASignInCtx(context.Background(), request, opts...)
+}
+
+func (m *OTP) MFASignInCtx(ctx context.Context, request *otp.MFASignInRequest, opts ...base_http_client.RequestOption) (*otp.AuthResponse, error) {
+	m.record("MFASignIn", request)
+	if m.MFASignInFunc == nil {
+		return nil, notMocked("otp.MFASignIn")
+	}
+	return m.MFASignInFunc(ctx, request, opts...)
+}
+
+func (m *OTP) CheckSignInCode(request *otp.CheckSignInCodeRequest, opts ...base_http_client.RequestOption) (*otp.CheckSignInCodeResponse, error) {
+	return m.CheckSignInCodeCtx(context.Background(), request, opts...)
+}
+
+func (m *OTP) CheckSignInCodeCtx(ctx context.Context, request *otp.CheckSignInCodeRequest, opts ...base_http_client.RequestOption) (*otp.CheckSignInCodeResponse, error) {
+	m.record("CheckSignInCode", request)
+	if m.CheckSignInCodeFunc == nil {
+		return nil, notMocked("otp.CheckSignInCode")
+	}
+	return m.CheckSignInCodeFunc(ctx, request, opts...)
+}
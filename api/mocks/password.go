@@ -0,0 +1,106 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/BlaisePopov/stack-auth/api/password"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+// Password - заглушка api.PasswordAPI с записью вызовов.
+// Поведение метода задается полем <Метод>Func, незаданный метод возвращает ErrNotMocked.
+type Password struct {
+	Recorder
+
+	UpdatePasswordFunc         func(ctx context.Context, request *password.UpdatePasswordRequest, opts ...base_http_client.RequestOption) (*password.UpdatePasswordResponse, error)
+	SignUpWithEmailFunc        func(ctx context.Context, request *password.SignUpWithEmailRequest, opts ...base_http_client.RequestOption) (*password.SignUpResponse, error)
+	SignInWithEmailFunc        func(ctx context.Context, request *password.SignInRequest, opts ...base_http_client.RequestOption) (*password.SignInResponse, error)
+	SetPasswordFunc            func(ctx context.Context, request *password.SetPasswordRequest, opts ...base_http_client.RequestOption) (*password.SetPasswordResponse, error)
+	SendResetPasswordCodeFunc  func(ctx context.Context, request *password.SendResetCodeRequest, opts ...base_http_client.RequestOption) (*password.SendResetCodeResponse, error)
+	ResetPasswordWithCodeFunc  func(ctx context.Context, request *password.ResetPasswordRequest, opts ...base_http_client.RequestOption) (*password.ResetPasswordResponse, error)
+	CheckResetPasswordCodeFunc func(ctx context.Context, request *password.CheckCodeRequest, opts ...base_http_client.RequestOption) (*password.CheckCodeResponse, error)
+}
+
+func (m *Password) UpdatePassword(request *password.UpdatePasswordRequest, opts ...base_http_client.RequestOption) (*password.UpdatePasswordResponse, error) {
+	return m.UpdatePasswordCtx(context.Background(), request, opts...)
+}
+
+func (m *Password) UpdatePasswordCtx(ctx context.Context, request *password.UpdatePasswordRequest, opts ...base_http_client.RequestOption) (*password.UpdatePasswordResponse, error) {
+	m.record("UpdatePassword", request)
+	if m.UpdatePasswordFunc == nil {
+		return nil, notMocked("password.UpdatePassword")
+	}
+	return m.UpdatePasswordFunc(ctx, request, opts...)
+}
+
+func (m *Password) SignUpWithEmail(request *password.SignUpWithEmailRequest, opts ...base_http_client.RequestOption) (*password.SignUpResponse, error) {
+	return m.SignUpWithEmailCtx(context.Background(), request, opts...)
+}
+
+func (m *Password) SignUpWithEmailCtx(ctx context.Context, request *password.SignUpWithEmailRequest, opts ...base_http_client.RequestOption) (*password.SignUpResponse, error) {
+	m.record("SignUpWithEmail", request)
+	if m.SignUpWithEmailFunc == nil {
+		return nil, notMocked("password.SignUpWithEmail")
+	}
+	return m.SignUpWithEmailFunc(ctx, request, opts...)
+}
+
+func (m *Password) SignInWithEmail(request *password.SignInRequest, opts ...base_http_client.RequestOption) (*password.SignInResponse, error) {
+	return m.SignInWithEmailCtx(context.Background(), request, opts...)
+}
+
+func (m *Password) SignInWithEmailCtx(ctx context.Context, request *password.SignInRequest, opts ...base_http_client.RequestOption) (*password.SignInResponse, error) {
+	m.record("SignInWithEmail", request)
+	if m.SignInWithEmailFunc == nil {
+		return nil, notMocked("password.SignInWithEmail")
+	}
+	return m.SignInWithEmailFunc(ctx, request, opts...)
+}
+
+func (m *Password) SetPassword(request *password.SetPasswordRequest, opts ...base_http_client.RequestOption) (*password.SetPasswordResponse, error) {
+	return m.SetPasswordCtx(context.Background(), request, opts...)
+}
+
+func (m *Password) SetPasswordCtx(ctx context.Context, request *password.SetPasswordRequest, opts ...base_http_client.RequestOption) (*password.SetPasswordResponse, error) {
+	m.record("SetPassword", request)
+	if m.SetPasswordFunc == nil {
+		return nil, notMocked("password.SetPassword")
+	}
+	return m.SetPasswordFunc(ctx, request, opts...)
+}
+
+func (m *Password) SendResetPasswordCode(request *password.SendResetCodeRequest, opts ...base_http_client.RequestOption) (*password.SendResetCodeResponse, error) {
+	return m.SendResetPasswordCodeCtx(context.Background(), request, opts...)
+}
+
+func (m *Password) SendResetPasswordCodeCtx(ctx context.Context, request *password.SendResetCodeRequest, opts ...base_http_client.RequestOption) (*password.SendResetCodeResponse, error) {
+	m.record("SendResetPasswordCode", request)
+	if m.SendResetPasswordCodeFunc == nil {
+		return nil, notMocked("password.SendResetPasswordCode")
+	}
+	return m.SendResetPasswordCodeFunc(ctx, request, opts...)
+}
+
+func (m *Password) ResetPasswordWithCode(request *password.ResetPasswordRequest, opts ...base_http_client.RequestOption) (*password.ResetPasswordResponse, error) {
+	return m.ResetPasswordWithCodeCtx(context.Background(), request, opts...)
+}
+
+func (m *Password) ResetPasswordWithCodeCtx(ctx context.Context, request *password.ResetPasswordRequest, opts ...base_http_client.RequestOption) (*password.ResetPasswordResponse, error) {
+	m.record("ResetPasswordWithCode", request)
+	if m.ResetPasswordWithCodeFunc == nil {
+		return nil, notMocked("password.ResetPasswordWithCode")
+	}
+	return m.ResetPasswordWithCodeFunc(ctx, request, opts...)
+}
+
+func (m *Password) CheckResetPasswordCode(request *password.CheckCodeRequest, opts ...base_http_client.RequestOption) (*password.CheckCodeResponse, error) {
+	return m.CheckResetPasswordCodeCtx(context.Background(), request, opts...)
+}
+
+func (m *Password) CheckResetPasswordCodeCtx(ctx context.Context, request *password.CheckCodeRequest, opts ...base_http_client.RequestOption) (*password.CheckCodeResponse, error) {
+	m.record("CheckResetPasswordCode", request)
+	if m.CheckResetPasswordCodeFunc == nil {
+		return nil, notMocked("password.CheckResetPasswordCode")
+	}
+	return m.CheckResetPasswordCodeFunc(ctx, request, opts...)
+}
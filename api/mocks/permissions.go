@@ -0,0 +1,64 @@
+package mocks
+
+import (
+	"context"
+	"iter"
+
+	"github.com/BlaisePopov/stack-auth/api/permissions"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+// Permissions - заглушка api.PermissionsAPI с записью вызовов.
+// Поведение метода задается полем <Метод>Func, незаданный метод возвращает ErrNotMocked.
+type Permissions struct {
+	Recorder
+
+	ListTeamPermissionsFunc          func(ctx context.Context, options *permissions.ListTeamPermissionsOptions, opts ...base_http_client.RequestOption) (*permissions.ListTeamPermissionsResponse, error)
+	AllTeamPermissionsFunc           func(ctx context.Context, options *permissions.ListTeamPermissionsOptions, opts ...base_http_client.RequestOption) iter.Seq2[permissions.TeamPermission, error]
+	GrantTeamPermissionToUserFunc    func(ctx context.Context, teamID, userID, permissionID, recursive string, opts ...base_http_client.RequestOption) (*permissions.GrantTeamPermissionResponse, error)
+	RevokeTeamPermissionFromUserFunc func(ctx context.Context, teamID, userID, permissionID, recursive string, opts ...base_http_client.RequestOption) (*permissions.RevokeTeamPermissionResponse, error)
+}
+
+func (m *Permissions) ListTeamPermissions(options *permissions.ListTeamPermissionsOptions, opts ...base_http_client.RequestOption) (*permissions.ListTeamPermissionsResponse, error) {
+	return m.ListTeamPermissionsCtx(context.Background(), options, opts...)
+}
+
+func (m *Permissions) ListTeamPermissionsCtx(ctx context.Context, options *permissions.ListTeamPermissionsOptions, opts ...base_http_client.RequestOption) (*permissions.ListTeamPermissionsResponse, error) {
+	m.record("ListTeamPermissions", options)
+	if m.ListTeamPermissionsFunc == nil {
+		return nil, notMocked("permissions.ListTeamPermissions")
+	}
+	return m.ListTeamPermissionsFunc(ctx, options, opts...)
+}
+
+func (m *Permissions) AllTeamPermissions(ctx context.Context, options *permissions.ListTeamPermissionsOptions, opts ...base_http_client.RequestOption) iter.Seq2[permissions.TeamPermission, error] {
+	m.record("AllTeamPermissions", options)
+	if m.AllTeamPermissionsFunc == nil {
+		return notMockedSeq[permissions.TeamPermission]("permissions.AllTeamPermissions")
+	}
+	return m.AllTeamPermissionsFunc(ctx, options, opts...)
+}
+
+func (m *Permissions) GrantTeamPermissionToUser(teamID, userID, permissionID, recursive string, opts ...base_http_client.RequestOption) (*permissions.GrantTeamPermissionResponse, error) {
+	return m.GrantTeamPermissionToUserCtx(context.Background(), teamID, userID, permissionID, recursive, opts...)
+}
+
+func (m *Permissions) GrantTeamPermissionToUserCtx(ctx context.Context, teamID, userID, permissionID, recursive string, opts ...base_http_client.RequestOption) (*permissions.GrantTeamPermissionResponse, error) {
+	m.record("GrantTeamPermissionToUser", teamID, userID, permissionID, recursive)
+	if m.GrantTeamPermissionToUserFunc == nil {
+		return nil, notMocked("permissions.GrantTeamPermissionToUser")
+	}
+	return m.GrantTeamPermissionToUserFunc(ctx, teamID, userID, permissionID, recursive, opts...)
+}
+
+func (m *Permissions) RevokeTeamPermissionFromUser(teamID, userID, permissionID, recursive string, opts ...base_http_client.RequestOption) (*permissions.RevokeTeamPermissionResponse, error) {
+	return m.RevokeTeamPermissionFromUserCtx(context.Background(), teamID, userID, permissionID, recursive, opts...)
+}
+
+func (m *Permissions) RevokeTeamPermissionFromUserCtx(ctx context.Context, teamID, userID, permissionID, recursive string, opts ...base_http_client.RequestOption) (*permissions.RevokeTeamPermissionResponse, error) {
+	m.record("RevokeTeamPermissionFromUser", teamID, userID, permissionID, recursive)
+	if m.RevokeTeamPermissionFromUserFunc == nil {
+		return nil, notMocked("permissions.RevokeTeamPermissionFromUser")
+	}
+	return m.RevokeTeamPermissionFromUserFunc(ctx, teamID, userID, permissionID, recursive, opts...)
+}
@@ -0,0 +1,28 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/BlaisePopov/stack-auth/api/projects"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+// Projects - заглушка api.ProjectsAPI с записью вызовов.
+// Поведение метода задается полем <Метод>Func, незаданный метод возвращает ErrNotMocked.
+type Projects struct {
+	Recorder
+
+	GetCurrentProjectFunc func(ctx context.Context, opts ...base_http_client.RequestOption) (*projects.GetCurrentProjectResponse, error)
+}
+
+func (m *Projects) GetCurrentProject(opts ...base_http_client.RequestOption) (*projects.GetCurrentProjectResponse, error) {
+	return m.GetCurrentProjectCtx(context.Background(), opts...)
+}
+
+func (m *Projects) GetCurrentProjectCtx(ctx context.Context, opts ...base_http_client.RequestOption) (*projects.GetCurrentProjectResponse, error) {
+	m.record("GetCurrentProject")
+	if m.GetCurrentProjectFunc == nil {
+		return nil, notMocked("projects.GetCurrentProject")
+	}
+	return m.GetCurrentProjectFunc(ctx, opts...)
+}
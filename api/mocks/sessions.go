@@ -0,0 +1,54 @@
+package mocks
+
+import (
+	"context"
+
+	"github.com/BlaisePopov/stack-auth/api/sessions"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+// Sessions - заглушка api.SessionsAPI с записью вызовов.
+// Поведение метода задается полем <Метод>Func, незаданный метод возвращает ErrNotMocked.
+type Sessions struct {
+	Recorder
+
+	CreateSessionFunc      func(ctx context.Context, request *sessions.CreateSessionRequest, opts ...base_http_client.RequestOption) (*sessions.CreateSessionResponse, error)
+	SignOutFunc            func(ctx context.Context, refreshToken string, opts ...base_http_client.RequestOption) (*sessions.SignOutResponse, error)
+	RefreshAccessTokenFunc func(ctx context.Context, refreshToken string, opts ...base_http_client.RequestOption) (*sessions.RefreshAccessTokenResponse, error)
+}
+
+func (m *Sessions) CreateSession(request *sessions.CreateSessionRequest, opts ...base_http_client.RequestOption) (*sessions.CreateSessionResponse, error) {
+	return m.CreateSessionCtx(context.Background(), request, opts...)
+}
+
+func (m *Sessions) CreateSessionCtx(ctx context.Context, request *sessions.CreateSessionRequest, opts ...base_http_client.RequestOption) (*sessions.CreateSessionResponse, error) {
+	m.record("CreateSession", request)
+	if m.CreateSessionFunc == nil {
+		return nil, notMocked("sessions.CreateSession")
+	}
+	return m.CreateSessionFunc(ctx, request, opts...)
+}
+
+func (m *Sessions) SignOut(refreshToken string, opts ...base_http_client.RequestOption) (*sessions.SignOutResponse, error) {
+	return m.SignOutCtx(context.Background(), refreshToken, opts...)
+}
+
+func (m *Sessions) SignOutCtx(ctx context.Context, refreshToken string, opts ...base_http_client.RequestOption) (*sessions.SignOutResponse, error) {
+	m.record("SignOut", refreshToken)
+	if m.SignOutFunc == nil {
+		return nil, notMocked("sessions.SignOut")
+	}
+	return m.SignOutFunc(ctx, refreshToken, opts...)
+}
+
+func (m *Sessions) RefreshAccessToken(refreshToken string, opts ...base_http_client.RequestOption) (*sessions.RefreshAccessTokenResponse, error) {
+	return m.RefreshAccessTokenCtx(context.Background(), refreshToken, opts...)
+}
+
+func (m *Sessions) RefreshAccessTokenCtx(ctx context.Context, refreshToken string, opts ...base_http_client.RequestOption) (*sessions.RefreshAccessTokenResponse, error) {
+	m.record("RefreshAccessToken", refreshToken)
+	if m.RefreshAccessTokenFunc == nil {
+		return nil, notMocked("sessions.RefreshAccessToken")
+	}
+	return m.RefreshAccessTokenFunc(ctx, refreshToken, opts...)
+}
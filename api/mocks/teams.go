@@ -0,0 +1,216 @@
+package mocks
+
+import (
+	"context"
+	"iter"
+
+	"github.com/BlaisePopov/stack-auth/api/teams"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+// Teams - заглушка api.TeamsAPI с записью вызовов.
+// Поведение метода задается полем <Метод>Func, незаданный метод возвращает ErrNotMocked.
+type Teams struct {
+	Recorder
+
+	ListTeamsFunc               func(ctx context.Context, options *teams.ListTeamsOptions, opts ...base_http_client.RequestOption) (*teams.ListTeamsResponse, error)
+	AllTeamsFunc                func(ctx context.Context, options *teams.ListTeamsOptions, opts ...base_http_client.RequestOption) iter.Seq2[teams.TeamResponse, error]
+	CreateTeamFunc              func(ctx context.Context, request *teams.CreateTeamRequest, opts ...base_http_client.RequestOption) (*teams.TeamResponse, error)
+	GetTeamFunc                 func(ctx context.Context, teamID string, opts ...base_http_client.RequestOption) (*teams.TeamResponse, error)
+	DeleteTeamFunc              func(ctx context.Context, teamID string, opts ...base_http_client.RequestOption) (*teams.SuccessResponse, error)
+	UpdateTeamFunc              func(ctx context.Context, teamID string, request *teams.UpdateTeamRequest, opts ...base_http_client.RequestOption) (*teams.TeamResponse, error)
+	ListTeamMembersProfilesFunc func(ctx context.Context, options *teams.ListTeamMembersProfilesOptions, opts ...base_http_client.RequestOption) (*teams.ListTeamMembersResponse, error)
+	AllTeamMembersProfilesFunc  func(ctx context.Context, options *teams.ListTeamMembersProfilesOptions, opts ...base_http_client.RequestOption) iter.Seq2[teams.TeamMemberProfileResponse, error]
+	SendInviteEmailFunc         func(ctx context.Context, request *teams.SendInviteEmailRequest, opts ...base_http_client.RequestOption) (*teams.SuccessResponse, error)
+	AcceptInviteFunc            func(ctx context.Context, request *teams.AcceptInviteRequest, opts ...base_http_client.RequestOption) error
+	AddTeamMemberFunc           func(ctx context.Context, teamID, userID string, opts ...base_http_client.RequestOption) (*teams.TeamMembershipResponse, error)
+	RemoveTeamMemberFunc        func(ctx context.Context, teamID, userID string, opts ...base_http_client.RequestOption) (*teams.SuccessResponse, error)
+	GetTeamMemberProfileFunc    func(ctx context.Context, teamID, userID string, opts ...base_http_client.RequestOption) (*teams.TeamMemberProfileResponse, error)
+	UpdateTeamMemberProfileFunc func(ctx context.Context, teamID, userID string, request *teams.UpdateTeamMemberProfileRequest, opts ...base_http_client.RequestOption) (*teams.TeamMemberProfileResponse, error)
+	GetInvitationDetailsFunc    func(ctx context.Context, code string, opts ...base_http_client.RequestOption) (*teams.InvitationDetailsResponse, error)
+	CheckInviteCodeFunc         func(ctx context.Context, code string, opts ...base_http_client.RequestOption) (*teams.CheckCodeResponse, error)
+}
+
+func (m *Teams) ListTeams(options *teams.ListTeamsOptions, opts ...base_http_client.RequestOption) (*teams.ListTeamsResponse, error) {
+	return m.ListTeamsCtx(context.Background(), options, opts...)
+}
+
+func (m *Teams) ListTeamsCtx(ctx context.Context, options *teams.ListTeamsOptions, opts ...base_http_client.RequestOption) (*teams.ListTeamsResponse, error) {
+	m.record("ListTeams", options)
+	if m.ListTeamsFunc == nil {
+		return nil, notMocked("teams.ListTeams")
+	}
+	return m.ListTeamsFunc(ctx, options, opts...)
+}
+
+func (m *Teams) AllTeams(ctx context.Context, options *teams.ListTeamsOptions, opts ...base_http_client.RequestOption) iter.Seq2[teams.TeamResponse, error] {
+	m.record("AllTeams", options)
+	if m.AllTeamsFunc == nil {
+		return notMockedSeq[teams.TeamResponse]("teams.AllTeams")
+	}
+	return m.AllTeamsFunc(ctx, options, opts...)
+}
+
+func (m *Teams) CreateTeam(request *teams.CreateTeamRequest, opts ...base_http_client.RequestOption) (*teams.TeamResponse, error) {
+	return m.CreateTeamCtx(context.Background(), request, opts...)
+}
+
+func (m *Teams) CreateTeamCtx(ctx context.Context, request *teams.CreateTeamRequest, opts ...base_http_client.RequestOption) (*teams.TeamResponse, error) {
+	m.record("CreateTeam", request)
+	if m.CreateTeamFunc == nil {
+		return nil, notMocked("teams.CreateTeam")
+	}
+	return m.CreateTeamFunc(ctx, request, opts...)
+}
+
+func (m *Teams) GetTeam(teamID string, opts ...base_http_client.RequestOption) (*teams.TeamResponse, error) {
+	return m.GetTeamCtx(context.Background(), teamID, opts...)
+}
+
+func (m *Teams) GetTeamCtx(ctx context.Context, teamID string, opts ...base_http_client.RequestOption) (*teams.TeamResponse, error) {
+	m.record("GetTeam", teamID)
+	if m.GetTeamFunc == nil {
+		return nil, notMocked("teams.GetTeam")
+	}
+	return m.GetTeamFunc(ctx, teamID, opts...)
+}
+
+func (m *Teams) DeleteTeam(teamID string, opts ...base_http_client.RequestOption) (*teams.SuccessResponse, error) {
+	return m.DeleteTeamCtx(context.Background(), teamID, opts...)
+}
+
+func (m *Teams) DeleteTeamCtx(ctx context.Context, teamID string, opts ...base_http_client.RequestOption) (*teams.SuccessResponse, error) {
+	m.record("DeleteTeam", teamID)
+	if m.DeleteTeamFunc == nil {
+		return nil, notMocked("teams.DeleteTeam")
+	}
+	return m.DeleteTeamFunc(ctx, teamID, opts...)
+}
+
+func (m *Teams) UpdateTeam(teamID string, request *teams.UpdateTeamRequest, opts ...base_http_client.RequestOption) (*teams.TeamResponse, error) {
+	return m.UpdateTeamCtx(context.Background(), teamID, request, opts...)
+}
+
+func (m *Teams) UpdateTeamCtx(ctx context.Context, teamID string, request *teams.UpdateTeamRequest, opts ...base_http_client.RequestOption) (*teams.TeamResponse, error) {
+	m.record("UpdateTeam", teamID, request)
+	if m.UpdateTeamFunc == nil {
+		return nil, notMocked("teams.UpdateTeam")
+	}
+	return m.UpdateTeamFunc(ctx, teamID, request, opts...)
+}
+
+func (m *Teams) ListTeamMembersProfiles(options *teams.ListTeamMembersProfilesOptions, opts ...base_http_client.RequestOption) (*teams.ListTeamMembersResponse, error) {
+	return m.ListTeamMembersProfilesCtx(context.Background(), options, opts...)
+}
+
+func (m *Teams) ListTeamMembersProfilesCtx(ctx context.Context, options *teams.ListTeamMembersProfilesOptions, opts ...base_http_client.RequestOption) (*teams.ListTeamMembersResponse, error) {
+	m.record("ListTeamMembersProfiles", options)
+	if m.ListTeamMembersProfilesFunc == nil {
+		return nil, notMocked("teams.ListTeamMembersProfiles")
+	}
+	return m.ListTeamMembersProfilesFunc(ctx, options, opts...)
+}
+
+func (m *Teams) AllTeamMembersProfiles(ctx context.Context, options *teams.ListTeamMembersProfilesOptions, opts ...base_http_client.RequestOption) iter.Seq2[teams.TeamMemberProfileResponse, error] {
+	m.record("AllTeamMembersProfiles", options)
+	if m.AllTeamMembersProfilesFunc == nil {
+		return notMockedSeq[teams.TeamMemberProfileResponse]("teams.AllTeamMembersProfiles")
+	}
+	return m.AllTeamMembersProfilesFunc(ctx, options, opts...)
+}
+
+func (m *Teams) SendInviteEmail(request *teams.SendInviteEmailRequest, opts ...base_http_client.RequestOption) (*teams.SuccessResponse, error) {
+	return m.SendInviteEmailCtx(context.Background(), request, opts...)
+}
+
+func (m *Teams) SendInviteEmailCtx(ctx context.Context, request *teams.SendInviteEmailRequest, opts ...base_http_client.RequestOption) (*teams.SuccessResponse, error) {
+	m.record("SendInviteEmail", request)
+	if m.SendInviteEmailFunc == nil {
+		return nil, notMocked("teams.SendInviteEmail")
+	}
+	return m.SendInviteEmailFunc(ctx, request, opts...)
+}
+
+func (m *Teams) AcceptInvite(request *teams.AcceptInviteRequest, opts ...base_http_client.RequestOption) error {
+	return m.AcceptInviteCtx(context.Background(), request, opts...)
+}
+
+func (m *Teams) AcceptInviteCtx(ctx context.Context, request *teams.AcceptInviteRequest, opts ...base_http_client.RequestOption) error {
+	m.record("AcceptInvite", request)
+	if m.AcceptInviteFunc == nil {
+		return notMocked("teams.AcceptInvite")
+	}
+	return m.AcceptInviteFunc(ctx, request, opts...)
+}
+
+func (m *Teams) AddTeamMember(teamID, userID string, opts ...base_http_client.RequestOption) (*teams.TeamMembershipResponse, error) {
+	return m.AddTeamMemberCtx(context.Background(), teamID, userID, opts...)
+}
+
+func (m *Teams) AddTeamMemberCtx(ctx context.Context, teamID, userID string, opts ...base_http_client.RequestOption) (*teams.TeamMembershipResponse, error) {
+	m.record("AddTeamMember", teamID, userID)
+	if m.AddTeamMemberFunc == nil {
+		return nil, notMocked("teams.AddTeamMember")
+	}
+	return m.AddTeamMemberFunc(ctx, teamID, userID, opts...)
+}
+
+func (m *Teams) RemoveTeamMember(teamID, userID string, opts ...base_http_client.RequestOption) (*teams.SuccessResponse, error) {
+	return m.RemoveTeamMemberCtx(context.Background(), teamID, userID, opts...)
+}
+
+func (m *Teams) RemoveTeamMemberCtx(ctx context.Context, teamID, userID string, opts ...base_http_client.RequestOption) (*teams.SuccessResponse, error) {
+	m.record("RemoveTeamMember", teamID, userID)
+	if m.RemoveTeamMemberFunc == nil {
+		return nil, notMocked("teams.RemoveTeamMember")
+	}
+	return m.RemoveTeamMemberFunc(ctx, teamID, userID, opts...)
+}
+
+func (m *Teams) GetTeamMemberProfile(teamID, userID string, opts ...base_http_client.RequestOption) (*teams.TeamMemberProfileResponse, error) {
+	return m.GetTeamMemberProfileCtx(context.Background(), teamID, userID, opts...)
+}
+
+func (m *Teams) GetTeamMemberProfileCtx(ctx context.Context, teamID, userID string, opts ...base_http_client.RequestOption) (*teams.TeamMemberProfileResponse, error) {
+	m.record("GetTeamMemberProfile", teamID, userID)
+	if m.GetTeamMemberProfileFunc == nil {
+		return nil, notMocked("teams.GetTeamMemberProfile")
+	}
+	return m.GetTeamMemberProfileFunc(ctx, teamID, userID, opts...)
+}
+
+func (m *Teams) UpdateTeamMemberProfile(teamID, userID string, request *teams.UpdateTeamMemberProfileRequest, opts ...base_http_client.RequestOption) (*teams.TeamMemberProfileResponse, error) {
+	return m.UpdateTeamMemberProfileCtx(context.Background(), teamID, userID, request, opts...)
+}
+
+func (m *Teams) UpdateTeamMemberProfileCtx(ctx context.Context, teamID, userID string, request *teams.UpdateTeamMemberProfileRequest, opts ...base_http_client.RequestOption) (*teams.TeamMemberProfileResponse, error) {
+	m.record("UpdateTeamMemberProfile", teamID, userID, request)
+	if m.UpdateTeamMemberProfileFunc == nil {
+		return nil, notMocked("teams.UpdateTeamMemberProfile")
+	}
+	return m.UpdateTeamMemberProfileFunc(ctx, teamID, userID, request, opts...)
+}
+
+func (m *Teams) GetInvitationDetails(code string, opts ...base_http_client.RequestOption) (*teams.InvitationDetailsResponse, error) {
+	return m.GetInvitationDetailsCtx(context.Background(), code, opts...)
+}
+
+func (m *Teams) GetInvitationDetailsCtx(ctx context.Context, code string, opts ...base_http_client.RequestOption) (*teams.InvitationDetailsResponse, error) {
+	m.record("GetInvitationDetails", code)
+	if m.GetInvitationDetailsFunc == nil {
+		return nil, notMocked("teams.GetInvitationDetails")
+	}
+	return m.GetInvitationDetailsFunc(ctx, code, opts...)
+}
+
+func (m *Teams) CheckInviteCode(code string, opts ...base_http_client.RequestOption) (*teams.CheckCodeResponse, error) {
+	return m.CheckInviteCodeCtx(context.Background(), code, opts...)
+}
+
+func (m *Teams) CheckInviteCodeCtx(ctx context.Context, code string, opts ...base_http_client.RequestOption) (*teams.CheckCodeResponse, error) {
+	m.record("CheckInviteCode", code)
+	if m.CheckInviteCodeFunc == nil {
+		return nil, notMocked("teams.CheckInviteCode")
+	}
+	return m.CheckInviteCodeFunc(ctx, code, opts...)
+}
@@ -0,0 +1,129 @@
+package mocks
+
+import (
+	"context"
+	"iter"
+
+	"github.com/BlaisePopov/stack-auth/api/users"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+// Users - заглушка api.UsersAPI с записью вызовов.
+// Поведение метода задается полем <Метод>Func, незаданный метод возвращает ErrNotMocked.
+type Users struct {
+	Recorder
+
+	ListUsersFunc         func(ctx context.Context, options *users.ListUsersOptions, opts ...base_http_client.RequestOption) (*users.ListUsersResponse, error)
+	AllUsersFunc          func(ctx context.Context, options *users.ListUsersOptions, opts ...base_http_client.RequestOption) iter.Seq2[users.User, error]
+	CreateUserFunc        func(ctx context.Context, request *users.CreateUserRequest, opts ...base_http_client.RequestOption) (*users.UserResponse, error)
+	GetCurrentUserFunc    func(ctx context.Context, opts ...base_http_client.RequestOption) (*users.UserResponse, error)
+	DeleteCurrentUserFunc func(ctx context.Context, opts ...base_http_client.RequestOption) (*users.SuccessResponse, error)
+	UpdateCurrentUserFunc func(ctx context.Context, request *users.UpdateUserRequest, opts ...base_http_client.RequestOption) (*users.UserResponse, error)
+	GetUserFunc           func(ctx context.Context, userID string, opts ...base_http_client.RequestOption) (*users.UserResponse, error)
+	DeleteUserFunc        func(ctx context.Context, userID string, opts ...base_http_client.RequestOption) (*users.SuccessResponse, error)
+	UpdateUserFunc        func(ctx context.Context, userID string, request *users.UpdateUserRequest, opts ...base_http_client.RequestOption) (*users.UserResponse, error)
+}
+
+func (m *Users) ListUsers(options *users.ListUsersOptions, opts ...base_http_client.RequestOption) (*users.ListUsersResponse, error) {
+	return m.ListUsersCtx(context.Background(), options, opts...)
+}
+
+func (m *Users) ListUsersCtx(ctx context.Context, options *users.ListUsersOptions, opts ...base_http_client.RequestOption) (*users.ListUsersResponse, error) {
+	m.record("ListUsers", options)
+	if m.ListUsersFunc == nil {
+		return nil, notMocked("users.ListUsers")
+	}
+	return m.ListUsersFunc(ctx, options, opts...)
+}
+
+func (m *Users) AllUsers(ctx context.Context, options *users.ListUsersOptions, opts ...base_http_client.RequestOption) iter.Seq2[users.User, error] {
+	m.record("AllUsers", options)
+	if m.AllUsersFunc == nil {
+		return notMockedSeq[users.User]("users.AllUsers")
+	}
+	return m.AllUsersFunc(ctx, options, opts...)
+}
+
+func (m *Users) CreateUser(request *users.CreateUserRequest, opts ...base_http_client.RequestOption) (*users.UserResponse, error) {
+	return m.CreateUserCtx(context.Background(), request, opts...)
+}
+
+func (m *Users) CreateUserCtx(ctx context.Context, request *users.CreateUserRequest, opts ...base_http_client.RequestOption) (*users.UserResponse, error) {
+	m.record("CreateUser", request)
+	if m.CreateUserFunc == nil {
+		return nil, notMocked("users.CreateUser")
+	}
+	return m.CreateUserFunc(ctx, request, opts...)
+}
+
+func (m *Users) GetCurrentUser(opts ...base_http_client.RequestOption) (*users.UserResponse, error) {
+	return m.GetCurrentUserCtx(context.Background(), opts...)
+}
+
+func (m *Users) GetCurrentUserCtx(ctx context.Context, opts ...base_http_client.RequestOption) (*users.UserResponse, error) {
+	m.record("GetCurrentUser")
+	if m.GetCurrentUserFunc == nil {
+		return nil, notMocked("users.GetCurrentUser")
+	}
+	return m.GetCurrentUserFunc(ctx, opts...)
+}
+
+func (m *Users) DeleteCurrentUser(opts ...base_http_client.RequestOption) (*users.SuccessResponse, error) {
+	return m.DeleteCurrentUserCtx(context.Background(), opts...)
+}
+
+func (m *Users) DeleteCurrentUserCtx(ctx context.Context, opts ...base_http_client.RequestOption) (*users.SuccessResponse, error) {
+	m.record("DeleteCurrentUser")
+	if m.DeleteCurrentUserFunc == nil {
+		return nil, notMocked("users.DeleteCurrentUser")
+	}
+	return m.DeleteCurrentUserFunc(ctx, opts...)
+}
+
+func (m *Users) UpdateCurrentUser(request *users.UpdateUserRequest, opts ...base_http_client.RequestOption) (*users.UserResponse, error) {
+	return m.UpdateCurrentUserCtx(context.Background(), request, opts...)
+}
+
+func (m *Users) UpdateCurrentUserCtx(ctx context.Context, request *users.UpdateUserRequest, opts ...base_http_client.RequestOption) (*users.UserResponse, error) {
+	m.record("UpdateCurrentUser", request)
+	if m.UpdateCurrentUserFunc == nil {
+		return nil, notMocked("users.UpdateCurrentUser")
+	}
+	return m.UpdateCurrentUserFunc(ctx, request, opts...)
+}
+
+func (m *Users) GetUser(userID string, opts ...base_http_client.RequestOption) (*users.UserResponse, error) {
+	return m.GetUserCtx(context.Background(), userID, opts...)
+}
+
+func (m *Users) GetUserCtx(ctx context.Context, userID string, opts ...base_http_client.RequestOption) (*users.UserResponse, error) {
+	m.record("GetUser", userID)
+	if m.GetUserFunc == nil {
+		return nil, notMocked("users.GetUser")
+	}
+	return m.GetUserFunc(ctx, userID, opts...)
+}
+
+func (m *Users) DeleteUser(userID string, opts ...base_http_client.RequestOption) (*users.SuccessResponse, error) {
+	return m.DeleteUserCtx(context.Background(), userID, opts...)
+}
+
+func (m *Users) DeleteUserCtx(ctx context.Context, userID string, opts ...base_http_client.RequestOption) (*users.SuccessResponse, error) {
+	m.record("DeleteUser", userID)
+	if m.DeleteUserFunc == nil {
+		return nil, notMocked("users.DeleteUser")
+	}
+	return m.DeleteUserFunc(ctx, userID, opts...)
+}
+
+func (m *Users) UpdateUser(userID string, request *users.UpdateUserRequest, opts ...base_http_client.RequestOption) (*users.UserResponse, error) {
+	return m.UpdateUserCtx(context.Background(), userID, request, opts...)
+}
+
+func (m *Users) UpdateUserCtx(ctx context.Context, userID string, request *users.UpdateUserRequest, opts ...base_http_client.RequestOption) (*users.UserResponse, error) {
+	m.record("UpdateUser", userID, request)
+	if m.UpdateUserFunc == nil {
+		return nil, notMocked("users.UpdateUser")
+	}
+	return m.UpdateUserFunc(ctx, userID, request, opts...)
+}
@@ -1,10 +1,11 @@
 package oauth
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client/interface"
 	"github.com/BlaisePopov/stack-auth/base-http-client/utils"
+	"github.com/BlaisePopov/stack-auth/internal/transport"
 	"net/url"
 )
 
@@ -22,25 +23,20 @@ func NewClient(httpClient base_http_client.BaseHTTPClient) *Client {
 //
 // Входные параметры:
 //   - request: данные запроса для получения токена
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект TokenResponse и ошибка, если она возникла
-func (c *Client) Token(request *TokenRequest) (*TokenResponse, error) {
-	response := &TokenResponse{}
-
-	body, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка сериализации запроса: %w", err)
-	}
-
-	rawResponse, err := c.HTTPClient.SendRequest("POST", "/auth/oauth/token", nil, body)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) Token(request *TokenRequest, opts ...base_http_client.RequestOption) (*TokenResponse, error) {
+	return c.TokenCtx(context.Background(), request, opts...)
+}
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+// TokenCtx выполняет Token с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) TokenCtx(ctx context.Context, request *TokenRequest, opts ...base_http_client.RequestOption) (*TokenResponse, error) {
+	return transport.Do[TokenRequest, TokenResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "oauth.Token",
+		Method:    "POST",
+		Path:      "/auth/oauth/token",
+	}, request, opts...)
 }
 
 // Authorize инициирует OAuth авторизацию или связывание аккаунта [https://docs.stack-auth.com/next/rest-api/server/oauth/o-auth-authorize-endpoint]
@@ -48,9 +44,15 @@ func (c *Client) Token(request *TokenRequest) (*TokenResponse, error) {
 // Входные параметры:
 //   - providerID: идентификатор OAuth-провайдера
 //   - request: параметры запроса для авторизации
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: ошибка, если она возникла
-func (c *Client) Authorize(providerID string, query *AuthorizeQuery) error {
+func (c *Client) Authorize(providerID string, query *AuthorizeQuery, opts ...base_http_client.RequestOption) error {
+	return c.AuthorizeCtx(context.Background(), providerID, query, opts...)
+}
+
+// AuthorizeCtx выполняет Authorize с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) AuthorizeCtx(ctx context.Context, providerID string, query *AuthorizeQuery, opts ...base_http_client.RequestOption) error {
 	path := fmt.Sprintf("/auth/oauth/authorize/%s", url.PathEscape(providerID))
 
 	queryParams := url.Values{}
@@ -70,6 +72,11 @@ func (c *Client) Authorize(providerID string, query *AuthorizeQuery) error {
 	queryParams.Add("code_challenge_method", query.CodeChallengeMethod)
 	queryParams.Add("response_type", query.ResponseType)
 
-	_, err := c.HTTPClient.SendRequest("GET", path, queryParams, nil)
-	return err
+	return transport.Send[transport.NoBody](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation:    "oauth.Authorize",
+		Method:       "GET",
+		PathTemplate: "/auth/oauth/authorize/{provider_id}",
+		Path:         path,
+		Query:        queryParams,
+	}, nil, opts...)
 }
@@ -1,74 +1,109 @@
 package others
 
 import (
-    "encoding/json"
-    "fmt"
-    base_http_client "github.com/BlaisePopov/stack-auth/base-http-client/interface"
-    "net/url"
+	"context"
+	"fmt"
+	"github.com/BlaisePopov/stack-auth/api/pagination"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client/interface"
+	"github.com/BlaisePopov/stack-auth/base-http-client/utils"
+	"github.com/BlaisePopov/stack-auth/internal/transport"
+	"iter"
+	"net/url"
 )
 
 // Client представляет клиент для работы с дополнительными методами API
 type Client struct {
-    HTTPClient base_http_client.BaseHTTPClient
+	HTTPClient base_http_client.BaseHTTPClient
 }
 
 // NewClient создает новый экземпляр клиента для работы с дополнительными методами
 func NewClient(httpClient base_http_client.BaseHTTPClient) *Client {
-    return &Client{HTTPClient: httpClient}
+	return &Client{HTTPClient: httpClient}
 }
 
 // ListTeamInvitations возвращает список приглашений в команду. [https://docs.stack-auth.com/next/rest-api/server/others/get-team-invitations]
 //
+// Входные параметры:
+//   - opts: параметры отдельного запроса (опционально)
+//
 // Возвращаемое значение: объект ListTeamInvitationsResponse и ошибка, если она возникла
-func (c *Client) ListTeamInvitations() (*ListTeamInvitationsResponse, error) {
-    response := &ListTeamInvitationsResponse{}
-    rawResponse, err := c.HTTPClient.SendRequest("GET", "/team-invitations", url.Values{}, nil)
-    if err != nil {
-        return nil, err
-    }
-    if err := json.Unmarshal(rawResponse, response); err != nil {
-        return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-    }
-    return response, nil
+func (c *Client) ListTeamInvitations(opts ...base_http_client.RequestOption) (*ListTeamInvitationsResponse, error) {
+	return c.ListTeamInvitationsCtx(context.Background(), opts...)
+}
+
+// ListTeamInvitationsCtx выполняет ListTeamInvitations с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) ListTeamInvitationsCtx(ctx context.Context, opts ...base_http_client.RequestOption) (*ListTeamInvitationsResponse, error) {
+	return c.listTeamInvitations(ctx, "", opts...)
+}
+
+// AllTeamInvitations возвращает итератор по всем приглашениям в команду, автоматически переходящий по курсорам страниц.
+//
+// Входные параметры:
+//   - ctx: контекст, отмена которого завершает перебор
+//   - opts: параметры запросов страниц (опционально)
+//
+// Возвращаемое значение: итератор пар (приглашение, ошибка)
+func (c *Client) AllTeamInvitations(ctx context.Context, opts ...base_http_client.RequestOption) iter.Seq2[TeamInvitation, error] {
+	return pagination.Seq(ctx, func(ctx context.Context, cursor string) ([]TeamInvitation, string, error) {
+		response, err := c.listTeamInvitations(ctx, cursor, opts...)
+		if err != nil {
+			return nil, "", err
+		}
+		return response.Items, response.Pagination.NextCursor, nil
+	})
+}
+
+// listTeamInvitations загружает страницу приглашений, начиная с курсора
+func (c *Client) listTeamInvitations(ctx context.Context, cursor string, opts ...base_http_client.RequestOption) (*ListTeamInvitationsResponse, error) {
+	queryParams := url.Values{}
+	utils.AddOptionalStringParam(queryParams, "cursor", cursor)
+
+	return transport.Do[transport.NoBody, ListTeamInvitationsResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "others.ListTeamInvitations",
+		Method:    "GET",
+		Path:      "/team-invitations",
+		Query:     queryParams,
+	}, nil, opts...)
 }
 
 // DeleteTeamInvitation удаляет приглашение в команду по ID. [https://docs.stack-auth.com/next/rest-api/server/others/delete-team-invitations-id]
 //
 // Входные параметры:
 //   - id: идентификатор приглашения
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект DeleteTeamInvitationResponse и ошибка, если она возникла
-func (c *Client) DeleteTeamInvitation(id string) (*DeleteTeamInvitationResponse, error) {
-    response := &DeleteTeamInvitationResponse{}
-    path := fmt.Sprintf("/team-invitations/%s", id)
-    rawResponse, err := c.HTTPClient.SendRequest("DELETE", path, url.Values{}, nil)
-    if err != nil {
-        return nil, err
-    }
-    if err := json.Unmarshal(rawResponse, response); err != nil {
-        return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-    }
-    return response, nil
+func (c *Client) DeleteTeamInvitation(id string, opts ...base_http_client.RequestOption) (*DeleteTeamInvitationResponse, error) {
+	return c.DeleteTeamInvitationCtx(context.Background(), id, opts...)
+}
+
+// DeleteTeamInvitationCtx выполняет DeleteTeamInvitation с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) DeleteTeamInvitationCtx(ctx context.Context, id string, opts ...base_http_client.RequestOption) (*DeleteTeamInvitationResponse, error) {
+	path := fmt.Sprintf("/team-invitations/%s", id)
+	return transport.Do[transport.NoBody, DeleteTeamInvitationResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation:    "others.DeleteTeamInvitation",
+		Method:       "DELETE",
+		PathTemplate: "/team-invitations/{invitation_id}",
+		Path:         path,
+	}, nil, opts...)
 }
 
 // ConfirmNeonTransferCheck подтверждает проверку передачи проекта Neon. [https://docs.stack-auth.com/next/rest-api/server/others/post-integrations-neon-projects-transfer-confirm-check]
 //
 // Входные параметры:
 //   - request: данные для подтверждения проверки
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект ConfirmNeonTransferCheckResponse и ошибка, если она возникла
-func (c *Client) ConfirmNeonTransferCheck(request *ConfirmNeonTransferCheckRequest) (*ConfirmNeonTransferCheckResponse, error) {
-    response := &ConfirmNeonTransferCheckResponse{}
-    body, err := json.Marshal(request)
-    if err != nil {
-        return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-    }
-    rawResponse, err := c.HTTPClient.SendRequest("POST", "/integrations/neon/projects/transfer/confirm/check", url.Values{}, body)
-    if err != nil {
-        return nil, err
-    }
-    if err := json.Unmarshal(rawResponse, response); err != nil {
-        return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-    }
-    return response, nil
+func (c *Client) ConfirmNeonTransferCheck(request *ConfirmNeonTransferCheckRequest, opts ...base_http_client.RequestOption) (*ConfirmNeonTransferCheckResponse, error) {
+	return c.ConfirmNeonTransferCheckCtx(context.Background(), request, opts...)
+}
+
+// ConfirmNeonTransferCheckCtx выполняет ConfirmNeonTransferCheck с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) ConfirmNeonTransferCheckCtx(ctx context.Context, request *ConfirmNeonTransferCheckRequest, opts ...base_http_client.RequestOption) (*ConfirmNeonTransferCheckResponse, error) {
+	return transport.Do[ConfirmNeonTransferCheckRequest, ConfirmNeonTransferCheckResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "others.ConfirmNeonTransferCheck",
+		Method:    "POST",
+		Path:      "/integrations/neon/projects/transfer/confirm/check",
+	}, request, opts...)
 }
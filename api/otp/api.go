@@ -1,113 +1,97 @@
 package otp
 
 import (
-    "encoding/json"
-    "fmt"
-    base_http_client "github.com/BlaisePopov/stack-auth/base-http-client/interface"
+	"context"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client/interface"
+	"github.com/BlaisePopov/stack-auth/internal/transport"
 )
 
 // Client представляет клиент для работы с OTP аутентификацией
 type Client struct {
-    HTTPClient base_http_client.BaseHTTPClient
+	HTTPClient base_http_client.BaseHTTPClient
 }
 
 // NewClient создает новый экземпляр клиента для работы с OTP
 func NewClient(httpClient base_http_client.BaseHTTPClient) *Client {
-    return &Client{HTTPClient: httpClient}
+	return &Client{HTTPClient: httpClient}
 }
 
 // SignInWithCode выполняет вход с использованием одноразового кода [https://docs.stack-auth.com/next/rest-api/server/otp/sign-in-with-a-code]
 //
 // Входные параметры:
 //   - request: данные запроса содержащие код
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект AuthResponse и ошибка, если она возникла
-func (c *Client) SignInWithCode(request *SignInWithCodeRequest) (*AuthResponse, error) {
-    response := &AuthResponse{}
-    bodyBytes, err := json.Marshal(request)
-    if err != nil {
-        return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-    }
-
-    rawResponse, err := c.HTTPClient.SendRequest("POST", "/auth/otp/sign-in", nil, bodyBytes)
-    if err != nil {
-        return nil, err
-    }
+func (c *Client) SignInWithCode(request *SignInWithCodeRequest, opts ...base_http_client.RequestOption) (*AuthResponse, error) {
+	return c.SignInWithCodeCtx(context.Background(), request, opts...)
+}
 
-    if err := json.Unmarshal(rawResponse, response); err != nil {
-        return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-    }
-    return response, nil
+// SignInWithCodeCtx выполняет SignInWithCode с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) SignInWithCodeCtx(ctx context.Context, request *SignInWithCodeRequest, opts ...base_http_client.RequestOption) (*AuthResponse, error) {
+	return transport.Do[SignInWithCodeRequest, AuthResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "otp.SignInWithCode",
+		Method:    "POST",
+		Path:      "/auth/otp/sign-in",
+	}, request, opts...)
 }
 
 // SendSignInCode отправляет код для входа на email пользователя [https://docs.stack-auth.com/next/rest-api/server/otp/send-sign-in-code]
 //
 // Входные параметры:
 //   - request: данные запроса содержащие email и callback URL
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект SendSignInCodeResponse и ошибка, если она возникла
-func (c *Client) SendSignInCode(request *SendSignInCodeRequest) (*SendSignInCodeResponse, error) {
-    response := &SendSignInCodeResponse{}
-    bodyBytes, err := json.Marshal(request)
-    if err != nil {
-        return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-    }
-
-    rawResponse, err := c.HTTPClient.SendRequest("POST", "/auth/otp/send-sign-in-code", nil, bodyBytes)
-    if err != nil {
-        return nil, err
-    }
+func (c *Client) SendSignInCode(request *SendSignInCodeRequest, opts ...base_http_client.RequestOption) (*SendSignInCodeResponse, error) {
+	return c.SendSignInCodeCtx(context.Background(), request, opts...)
+}
 
-    if err := json.Unmarshal(rawResponse, response); err != nil {
-        return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-    }
-    return response, nil
+// SendSignInCodeCtx выполняет SendSignInCode с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) SendSignInCodeCtx(ctx context.Context, request *SendSignInCodeRequest, opts ...base_http_client.RequestOption) (*SendSignInCodeResponse, error) {
+	return transport.Do[SendSignInCodeRequest, SendSignInCodeResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "otp.SendSignInCode",
+		Method:    "POST",
+		Path:      "/auth/otp/send-sign-in-code",
+	}, request, opts...)
 }
 
 // MFASignIn выполняет MFA аутентификацию с использованием TOTP [https://docs.stack-auth.com/next/rest-api/server/otp/mfa-sign-in]
 //
 // Входные параметры:
 //   - request: данные запроса содержащие тип аутентификации, TOTP и код
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект AuthResponse и ошибка, если она возникла
-func (c *Client) MFASignIn(request *MFASignInRequest) (*AuthResponse, error) {
-    response := &AuthResponse{}
-    bodyBytes, err := json.Marshal(request)
-    if err != nil {
-        return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-    }
-
-    rawResponse, err := c.HTTPClient.SendRequest("POST", "/auth/mfa/sign-in", nil, bodyBytes)
-    if err != nil {
-        return nil, err
-    }
+func (c *Client) MFASignIn(request *MFASignInRequest, opts ...base_http_client.RequestOption) (*AuthResponse, error) {
+	return c.MFASignInCtx(context.Background(), request, opts...)
+}
 
-    if err := json.Unmarshal(rawResponse, response); err != nil {
-        return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-    }
-    return response, nil
+// MFASignInCtx выполняет MFASignIn с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) MFASignInCtx(ctx context.Context, request *MFASignInRequest, opts ...base_http_client.RequestOption) (*AuthResponse, error) {
+	return transport.Do[MFASignInRequest, AuthResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "otp.MFASignIn",
+		Method:    "POST",
+		Path:      "/auth/mfa/sign-in",
+	}, request, opts...)
 }
 
 // CheckSignInCode проверяет валидность кода для входа [https://docs.stack-auth.com/next/rest-api/server/otp/check-sign-in-code]
 //
 // Входные параметры:
 //   - request: данные запроса содержащие проверяемый код
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект CheckSignInCodeResponse и ошибка, если она возникла
-func (c *Client) CheckSignInCode(request *CheckSignInCodeRequest) (*CheckSignInCodeResponse, error) {
-    response := &CheckSignInCodeResponse{}
-    bodyBytes, err := json.Marshal(request)
-    if err != nil {
-        return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-    }
-
-    rawResponse, err := c.HTTPClient.SendRequest("POST", "/auth/otp/sign-in/check-code", nil, bodyBytes)
-    if err != nil {
-        return nil, err
-    }
+func (c *Client) CheckSignInCode(request *CheckSignInCodeRequest, opts ...base_http_client.RequestOption) (*CheckSignInCodeResponse, error) {
+	return c.CheckSignInCodeCtx(context.Background(), request, opts...)
+}
 
-    if err := json.Unmarshal(rawResponse, response); err != nil {
-        return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-    }
-    return response, nil
+// CheckSignInCodeCtx выполняет CheckSignInCode с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) CheckSignInCodeCtx(ctx context.Context, request *CheckSignInCodeRequest, opts ...base_http_client.RequestOption) (*CheckSignInCodeResponse, error) {
+	return transport.Do[CheckSignInCodeRequest, CheckSignInCodeResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "otp.CheckSignInCode",
+		Method:    "POST",
+		Path:      "/auth/otp/sign-in/check-code",
+	}, request, opts...)
 }
@@ -0,0 +1,96 @@
+// Package pagination содержит итераторы по спискам Stack Auth с курсорной пагинацией.
+//
+// Итератор запрашивает страницы по мере перебора, переходит по Pagination.NextCursor
+// и прекращает работу, когда курсор следующей страницы пуст или контекст отменен:
+//
+//	for user, err := range client.Users.AllUsers(ctx, &users.ListUsersOptions{Limit: 100}) {
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}
+package pagination
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+)
+
+// DefaultMaxItems - ограничение CollectAll, если maxItems не задан
+const DefaultMaxItems = 10000
+
+var (
+	// ErrMaxItemsExceeded возвращается CollectAll, если список содержит больше элементов, чем разрешено
+	ErrMaxItemsExceeded = errors.New("превышено максимальное количество элементов списка")
+	// ErrCursorLoop возвращается итератором, если сервер повторно вернул уже пройденный курсор
+	ErrCursorLoop = errors.New("курсор пагинации указывает на уже загруженную страницу")
+)
+
+// PageFunc загружает страницу списка, начиная с курсора. Пустой курсор означает первую страницу.
+// Возвращает элементы страницы и курсор следующей страницы, пустой для последней страницы.
+type PageFunc[T any] func(ctx context.Context, cursor string) (items []T, nextCursor string, err error)
+
+// Seq возвращает итератор по всем элементам списка.
+//
+// Ошибка загрузки страницы или отмена контекста передаются итератору вместе с нулевым элементом,
+// после чего перебор завершается. Страницы запрашиваются только по мере перебора,
+// поэтому прерывание цикла не приводит к лишним запросам.
+func Seq[T any](ctx context.Context, fetch PageFunc[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		seen := make(map[string]bool)
+		cursor := ""
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(zero, err)
+				return
+			}
+
+			items, next, err := fetch(ctx, cursor)
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+			for _, item := range items {
+				if !yield(item, nil) {
+					return
+				}
+			}
+
+			if next == "" {
+				return
+			}
+			seen[cursor] = true
+			if seen[next] {
+				yield(zero, fmt.Errorf("%w: %s", ErrCursorLoop, next))
+				return
+			}
+			cursor = next
+		}
+	}
+}
+
+// CollectAll собирает все элементы итератора в срез.
+//
+// maxItems ограничивает размер результата, значение 0 или меньше означает DefaultMaxItems.
+// Если элементов больше, возвращаются первые maxItems элементов и ErrMaxItemsExceeded.
+// При ошибке итератора возвращаются уже собранные элементы и эта ошибка.
+func CollectAll[T any](seq iter.Seq2[T, error], maxItems int) ([]T, error) {
+	if maxItems <= 0 {
+		maxItems = DefaultMaxItems
+	}
+
+	var items []T
+	for item, err := range seq {
+		if err != nil {
+			return items, err
+		}
+		if len(items) == maxItems {
+			return items, fmt.Errorf("%w: больше %d", ErrMaxItemsExceeded, maxItems)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
@@ -0,0 +1,103 @@
+package pagination
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// pages возвращает PageFunc, отдающую страницы по порядку и считающую запросы
+func pages(calls *int, items ...[]int) PageFunc[int] {
+	return func(ctx context.Context, cursor string) ([]int, string, error) {
+		*calls++
+		index := 0
+		if cursor != "" {
+			index, _ = strconv.Atoi(cursor)
+		}
+		next := ""
+		if index+1 < len(items) {
+			next = strconv.Itoa(index + 1)
+		}
+		return items[index], next, nil
+	}
+}
+
+func TestSeqFollowsCursors(t *testing.T) {
+	calls := 0
+	var got []int
+	for item, err := range Seq(context.Background(), pages(&calls, []int{1, 2}, []int{3}, []int{4, 5})) {
+		assert.NoError(t, err)
+		got = append(got, item)
+	}
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, got)
+	assert.Equal(t, 3, calls)
+}
+
+func TestSeqStopsWithoutExtraRequests(t *testing.T) {
+	calls := 0
+	for item := range Seq(context.Background(), pages(&calls, []int{1, 2}, []int{3})) {
+		if item == 2 {
+			break
+		}
+	}
+	assert.Equal(t, 1, calls)
+}
+
+func TestSeqReturnsPageError(t *testing.T) {
+	pageErr := errors.New("boom")
+	seq := Seq(context.Background(), func(ctx context.Context, cursor string) ([]int, string, error) {
+		if cursor == "" {
+			return []int{1}, "next", nil
+		}
+		return nil, "", pageErr
+	})
+
+	items, err := CollectAll(seq, 0)
+	assert.ErrorIs(t, err, pageErr)
+	assert.Equal(t, []int{1}, items)
+}
+
+func TestSeqStopsOnContextCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	calls := 0
+	var errs []error
+	for item, err := range Seq(ctx, pages(&calls, []int{1}, []int{2}, []int{3})) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if item == 1 {
+			cancel()
+		}
+	}
+	assert.Equal(t, 1, calls)
+	assert.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], context.Canceled)
+}
+
+func TestSeqDetectsCursorLoop(t *testing.T) {
+	seq := Seq(context.Background(), func(ctx context.Context, cursor string) ([]int, string, error) {
+		return []int{1}, "same", nil
+	})
+
+	items, err := CollectAll(seq, 0)
+	assert.ErrorIs(t, err, ErrCursorLoop)
+	assert.Equal(t, []int{1, 1}, items)
+}
+
+func TestCollectAllMaxItems(t *testing.T) {
+	calls := 0
+	items, err := CollectAll(Seq(context.Background(), pages(&calls, []int{1, 2}, []int{3, 4})), 3)
+	assert.ErrorIs(t, err, ErrMaxItemsExceeded)
+	assert.Equal(t, []int{1, 2, 3}, items)
+
+	calls = 0
+	items, err = CollectAll(Seq(context.Background(), pages(&calls, []int{1, 2}, []int{3, 4})), 4)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3, 4}, items)
+}
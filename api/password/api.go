@@ -1,186 +1,157 @@
 package password
 
 import (
-    "encoding/json"
-    "fmt"
-    base_http_client "github.com/BlaisePopov/stack-auth/base-http-client/interface"
-    "net/url"
+	"context"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client/interface"
+	"github.com/BlaisePopov/stack-auth/internal/transport"
 )
 
 // Client представляет клиент для работы с аутентификацией по паролю
 type Client struct {
-    HTTPClient base_http_client.BaseHTTPClient
+	HTTPClient base_http_client.BaseHTTPClient
 }
 
 // NewClient создает новый экземпляр клиента для работы с паролями
 func NewClient(httpClient base_http_client.BaseHTTPClient) *Client {
-    return &Client{HTTPClient: httpClient}
+	return &Client{HTTPClient: httpClient}
 }
 
 // UpdatePassword обновляет пароль текущего пользователя [https://docs.stack-auth.com/next/rest-api/server/password/update-password]
 //
 // Входные параметры:
 //   - request: данные для обновления пароля
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект UpdatePasswordResponse и ошибка, если она возникла
-func (c *Client) UpdatePassword(request *UpdatePasswordRequest) (*UpdatePasswordResponse, error) {
-    response := &UpdatePasswordResponse{}
-    body, err := json.Marshal(request)
-    if err != nil {
-        return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-    }
-
-    rawResponse, err := c.HTTPClient.SendRequest("POST", "/auth/password/update", url.Values{}, body)
-    if err != nil {
-        return nil, err
-    }
+func (c *Client) UpdatePassword(request *UpdatePasswordRequest, opts ...base_http_client.RequestOption) (*UpdatePasswordResponse, error) {
+	return c.UpdatePasswordCtx(context.Background(), request, opts...)
+}
 
-    if err := json.Unmarshal(rawResponse, response); err != nil {
-        return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-    }
-    return response, nil
+// UpdatePasswordCtx выполняет UpdatePassword с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) UpdatePasswordCtx(ctx context.Context, request *UpdatePasswordRequest, opts ...base_http_client.RequestOption) (*UpdatePasswordResponse, error) {
+	return transport.Do[UpdatePasswordRequest, UpdatePasswordResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "password.UpdatePassword",
+		Method:    "POST",
+		Path:      "/auth/password/update",
+	}, request, opts...)
 }
 
 // SignUpWithEmail создает новую учетную запись с email и паролем [https://docs.stack-auth.com/next/rest-api/server/password/sign-up-with-email-and-password]
 //
 // Входные параметры:
 //   - request: данные для регистрации
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект SignUpResponse и ошибка, если она возникла
-func (c *Client) SignUpWithEmail(request *SignUpWithEmailRequest) (*SignUpResponse, error) {
-    response := &SignUpResponse{}
-    body, err := json.Marshal(request)
-    if err != nil {
-        return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-    }
-
-    rawResponse, err := c.HTTPClient.SendRequest("POST", "/auth/password/sign-up", url.Values{}, body)
-    if err != nil {
-        return nil, err
-    }
+func (c *Client) SignUpWithEmail(request *SignUpWithEmailRequest, opts ...base_http_client.RequestOption) (*SignUpResponse, error) {
+	return c.SignUpWithEmailCtx(context.Background(), request, opts...)
+}
 
-    if err := json.Unmarshal(rawResponse, response); err != nil {
-        return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-    }
-    return response, nil
+// SignUpWithEmailCtx выполняет SignUpWithEmail с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) SignUpWithEmailCtx(ctx context.Context, request *SignUpWithEmailRequest, opts ...base_http_client.RequestOption) (*SignUpResponse, error) {
+	return transport.Do[SignUpWithEmailRequest, SignUpResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "password.SignUpWithEmail",
+		Method:    "POST",
+		Path:      "/auth/password/sign-up",
+	}, request, opts...)
 }
 
 // SignInWithEmail выполняет вход в учетную запись с email и паролем [https://docs.stack-auth.com/next/rest-api/server/password/sign-in-with-email-and-password]
 //
 // Входные параметры:
 //   - request: данные для входа
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект SignInResponse и ошибка, если она возникла
-func (c *Client) SignInWithEmail(request *SignInRequest) (*SignInResponse, error) {
-    response := &SignInResponse{}
-    body, err := json.Marshal(request)
-    if err != nil {
-        return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-    }
-
-    rawResponse, err := c.HTTPClient.SendRequest("POST", "/auth/password/sign-in", url.Values{}, body)
-    if err != nil {
-        return nil, err
-    }
+func (c *Client) SignInWithEmail(request *SignInRequest, opts ...base_http_client.RequestOption) (*SignInResponse, error) {
+	return c.SignInWithEmailCtx(context.Background(), request, opts...)
+}
 
-    if err := json.Unmarshal(rawResponse, response); err != nil {
-        return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-    }
-    return response, nil
+// SignInWithEmailCtx выполняет SignInWithEmail с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) SignInWithEmailCtx(ctx context.Context, request *SignInRequest, opts ...base_http_client.RequestOption) (*SignInResponse, error) {
+	return transport.Do[SignInRequest, SignInResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "password.SignInWithEmail",
+		Method:    "POST",
+		Path:      "/auth/password/sign-in",
+	}, request, opts...)
 }
 
 // SetPassword устанавливает новый пароль для текущего пользователя [https://docs.stack-auth.com/next/rest-api/server/password/set-password]
 //
 // Входные параметры:
 //   - request: данные для установки пароля
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект SetPasswordResponse и ошибка, если она возникла
-func (c *Client) SetPassword(request *SetPasswordRequest) (*SetPasswordResponse, error) {
-    response := &SetPasswordResponse{}
-    body, err := json.Marshal(request)
-    if err != nil {
-        return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-    }
-
-    rawResponse, err := c.HTTPClient.SendRequest("POST", "/auth/password/set", url.Values{}, body)
-    if err != nil {
-        return nil, err
-    }
+func (c *Client) SetPassword(request *SetPasswordRequest, opts ...base_http_client.RequestOption) (*SetPasswordResponse, error) {
+	return c.SetPasswordCtx(context.Background(), request, opts...)
+}
 
-    if err := json.Unmarshal(rawResponse, response); err != nil {
-        return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-    }
-    return response, nil
+// SetPasswordCtx выполняет SetPassword с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) SetPasswordCtx(ctx context.Context, request *SetPasswordRequest, opts ...base_http_client.RequestOption) (*SetPasswordResponse, error) {
+	return transport.Do[SetPasswordRequest, SetPasswordResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "password.SetPassword",
+		Method:    "POST",
+		Path:      "/auth/password/set",
+	}, request, opts...)
 }
 
 // SendResetPasswordCode отправляет код сброса пароля на email [https://docs.stack-auth.com/next/rest-api/server/password/send-reset-password-code]
 //
 // Входные параметры:
 //   - request: данные для отправки кода
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект SendResetCodeResponse и ошибка, если она возникла
-func (c *Client) SendResetPasswordCode(request *SendResetCodeRequest) (*SendResetCodeResponse, error) {
-    response := &SendResetCodeResponse{}
-    body, err := json.Marshal(request)
-    if err != nil {
-        return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-    }
-
-    rawResponse, err := c.HTTPClient.SendRequest("POST", "/auth/password/send-reset-code", url.Values{}, body)
-    if err != nil {
-        return nil, err
-    }
+func (c *Client) SendResetPasswordCode(request *SendResetCodeRequest, opts ...base_http_client.RequestOption) (*SendResetCodeResponse, error) {
+	return c.SendResetPasswordCodeCtx(context.Background(), request, opts...)
+}
 
-    if err := json.Unmarshal(rawResponse, response); err != nil {
-        return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-    }
-    return response, nil
+// SendResetPasswordCodeCtx выполняет SendResetPasswordCode с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) SendResetPasswordCodeCtx(ctx context.Context, request *SendResetCodeRequest, opts ...base_http_client.RequestOption) (*SendResetCodeResponse, error) {
+	return transport.Do[SendResetCodeRequest, SendResetCodeResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "password.SendResetPasswordCode",
+		Method:    "POST",
+		Path:      "/auth/password/send-reset-code",
+	}, request, opts...)
 }
 
 // ResetPasswordWithCode сбрасывает пароль с использованием кода [https://docs.stack-auth.com/next/rest-api/server/password/reset-password-with-a-code]
 //
 // Входные параметры:
 //   - request: данные для сброса пароля
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект ResetPasswordResponse и ошибка, если она возникла
-func (c *Client) ResetPasswordWithCode(request *ResetPasswordRequest) (*ResetPasswordResponse, error) {
-    response := &ResetPasswordResponse{}
-    body, err := json.Marshal(request)
-    if err != nil {
-        return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-    }
-
-    rawResponse, err := c.HTTPClient.SendRequest("POST", "/auth/password/reset", url.Values{}, body)
-    if err != nil {
-        return nil, err
-    }
+func (c *Client) ResetPasswordWithCode(request *ResetPasswordRequest, opts ...base_http_client.RequestOption) (*ResetPasswordResponse, error) {
+	return c.ResetPasswordWithCodeCtx(context.Background(), request, opts...)
+}
 
-    if err := json.Unmarshal(rawResponse, response); err != nil {
-        return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-    }
-    return response, nil
+// ResetPasswordWithCodeCtx выполняет ResetPasswordWithCode с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) ResetPasswordWithCodeCtx(ctx context.Context, request *ResetPasswordRequest, opts ...base_http_client.RequestOption) (*ResetPasswordResponse, error) {
+	return transport.Do[ResetPasswordRequest, ResetPasswordResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "password.ResetPasswordWithCode",
+		Method:    "POST",
+		Path:      "/auth/password/reset",
+	}, request, opts...)
 }
 
 // CheckResetPasswordCode проверяет валидность кода сброса пароля [https://docs.stack-auth.com/next/rest-api/server/password/check-reset-password-code]
 //
 // Входные параметры:
 //   - request: данные для проверки кода
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект CheckCodeResponse и ошибка, если она возникла
-func (c *Client) CheckResetPasswordCode(request *CheckCodeRequest) (*CheckCodeResponse, error) {
-    response := &CheckCodeResponse{}
-    body, err := json.Marshal(request)
-    if err != nil {
-        return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-    }
-
-    rawResponse, err := c.HTTPClient.SendRequest("POST", "/auth/password/reset/check-code", url.Values{}, body)
-    if err != nil {
-        return nil, err
-    }
-
-    if err := json.Unmarshal(rawResponse, response); err != nil {
-        return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-    }
-    return response, nil
+func (c *Client) CheckResetPasswordCode(request *CheckCodeRequest, opts ...base_http_client.RequestOption) (*CheckCodeResponse, error) {
+	return c.CheckResetPasswordCodeCtx(context.Background(), request, opts...)
+}
+
+// CheckResetPasswordCodeCtx выполняет CheckResetPasswordCode с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) CheckResetPasswordCodeCtx(ctx context.Context, request *CheckCodeRequest, opts ...base_http_client.RequestOption) (*CheckCodeResponse, error) {
+	return transport.Do[CheckCodeRequest, CheckCodeResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "password.CheckResetPasswordCode",
+		Method:    "POST",
+		Path:      "/auth/password/reset/check-code",
+	}, request, opts...)
 }
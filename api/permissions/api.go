@@ -1,9 +1,12 @@
 package permissions
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"github.com/BlaisePopov/stack-auth/api/pagination"
 	"github.com/BlaisePopov/stack-auth/base-http-client/utils"
+	"github.com/BlaisePopov/stack-auth/internal/transport"
+	"iter"
 	"net/url"
 
 	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client/interface"
@@ -21,40 +24,60 @@ func NewClient(httpClient base_http_client.BaseHTTPClient) *Client {
 
 // ListTeamPermissions возвращает список командных разрешений пользователя [https://docs.stack-auth.com/next/rest-api/server/permissions/list-team-permissions-of-a-user]
 //
-// Параметры:
-//   - teamID: идентификатор команды (опционально)
-//   - userID: идентификатор пользователя (опционально)
-//   - permissionID: идентификатор разрешения (опционально)
-//   - recursive: флаг рекурсивного поиска (опционально)
-func (c *Client) ListTeamPermissions(teamID, userID, permissionID, recursive string) (*ListTeamPermissionsResponse, error) {
-	response := &ListTeamPermissionsResponse{}
-	queryParams := url.Values{}
-
-	utils.AddOptionalStringParam(queryParams, "team_id", teamID)
-	utils.AddOptionalStringParam(queryParams, "user_id", userID)
-	utils.AddOptionalStringParam(queryParams, "permission_id", permissionID)
-	utils.AddOptionalStringParam(queryParams, "recursive", recursive)
+// Входные параметры:
+//   - options: фильтры по команде, пользователю и разрешению, рекурсивный поиск и пагинация (опционально)
+//   - opts: параметры отдельного запроса (опционально)
+func (c *Client) ListTeamPermissions(options *ListTeamPermissionsOptions, opts ...base_http_client.RequestOption) (*ListTeamPermissionsResponse, error) {
+	return c.ListTeamPermissionsCtx(context.Background(), options, opts...)
+}
 
-	rawResponse, err := c.HTTPClient.SendRequest("GET", "/team-permissions", queryParams, nil)
-	if err != nil {
-		return nil, err
-	}
+// ListTeamPermissionsCtx выполняет ListTeamPermissions с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) ListTeamPermissionsCtx(ctx context.Context, options *ListTeamPermissionsOptions, opts ...base_http_client.RequestOption) (*ListTeamPermissionsResponse, error) {
+	return transport.Do[transport.NoBody, ListTeamPermissionsResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "permissions.ListTeamPermissions",
+		Method:    "GET",
+		Path:      "/team-permissions",
+		Query:     options.values(),
+	}, nil, opts...)
+}
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+// AllTeamPermissions возвращает итератор по всем командным разрешениям, автоматически переходящий по курсорам страниц
+//
+// Входные параметры:
+//   - ctx: контекст, отмена которого завершает перебор
+//   - options: фильтры и размер страницы (опционально). Cursor задает начальную страницу
+//   - opts: параметры запросов страниц (опционально)
+func (c *Client) AllTeamPermissions(ctx context.Context, options *ListTeamPermissionsOptions, opts ...base_http_client.RequestOption) iter.Seq2[TeamPermission, error] {
+	return pagination.Seq(ctx, func(ctx context.Context, cursor string) ([]TeamPermission, string, error) {
+		page := ListTeamPermissionsOptions{}
+		if options != nil {
+			page = *options
+		}
+		if cursor != "" {
+			page.Cursor = cursor
+		}
+		response, err := c.ListTeamPermissionsCtx(ctx, &page, opts...)
+		if err != nil {
+			return nil, "", err
+		}
+		return response.Items, response.Pagination.NextCursor, nil
+	})
 }
 
 // GrantTeamPermissionToUser выдает пользователю командное разрешение [https://docs.stack-auth.com/next/rest-api/server/permissions/grant-a-team-permission-to-a-user]
 //
-// Параметры:
+// Входные параметры:
 //   - teamID: идентификатор команды
 //   - userID: идентификатор пользователя
 //   - permissionID: идентификатор разрешения
 //   - recursive: флаг рекурсивного применения (опционально)
-func (c *Client) GrantTeamPermissionToUser(teamID, userID, permissionID, recursive string) (*GrantTeamPermissionResponse, error) {
-	response := &GrantTeamPermissionResponse{}
+//   - opts: параметры отдельного запроса (опционально)
+func (c *Client) GrantTeamPermissionToUser(teamID, userID, permissionID, recursive string, opts ...base_http_client.RequestOption) (*GrantTeamPermissionResponse, error) {
+	return c.GrantTeamPermissionToUserCtx(context.Background(), teamID, userID, permissionID, recursive, opts...)
+}
+
+// GrantTeamPermissionToUserCtx выполняет GrantTeamPermissionToUser с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) GrantTeamPermissionToUserCtx(ctx context.Context, teamID, userID, permissionID, recursive string, opts ...base_http_client.RequestOption) (*GrantTeamPermissionResponse, error) {
 	path := fmt.Sprintf("/team-permissions/%s/%s/%s", teamID, userID, permissionID)
 	queryParams := url.Values{}
 
@@ -63,26 +86,29 @@ func (c *Client) GrantTeamPermissionToUser(teamID, userID, permissionID, recursi
 	utils.AddOptionalStringParam(queryParams, "permission_id", permissionID)
 	utils.AddOptionalStringParam(queryParams, "recursive", recursive)
 
-	rawResponse, err := c.HTTPClient.SendRequest("POST", path, queryParams, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+	return transport.Do[transport.NoBody, GrantTeamPermissionResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation:    "permissions.GrantTeamPermissionToUser",
+		Method:       "POST",
+		PathTemplate: "/team-permissions/{team_id}/{user_id}/{permission_id}",
+		Path:         path,
+		Query:        queryParams,
+	}, nil, opts...)
 }
 
 // RevokeTeamPermissionFromUser отзывает командное разрешение у пользователя [https://docs.stack-auth.com/next/rest-api/server/permissions/revoke-a-team-permission-from-a-user]
 //
-// Параметры:
+// Входные параметры:
 //   - teamID: идентификатор команды
 //   - userID: идентификатор пользователя
 //   - permissionID: идентификатор разрешения
 //   - recursive: флаг рекурсивного отзыва (опционально)
-func (c *Client) RevokeTeamPermissionFromUser(teamID, userID, permissionID, recursive string) (*RevokeTeamPermissionResponse, error) {
-	response := &RevokeTeamPermissionResponse{}
+//   - opts: параметры отдельного запроса (опционально)
+func (c *Client) RevokeTeamPermissionFromUser(teamID, userID, permissionID, recursive string, opts ...base_http_client.RequestOption) (*RevokeTeamPermissionResponse, error) {
+	return c.RevokeTeamPermissionFromUserCtx(context.Background(), teamID, userID, permissionID, recursive, opts...)
+}
+
+// RevokeTeamPermissionFromUserCtx выполняет RevokeTeamPermissionFromUser с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) RevokeTeamPermissionFromUserCtx(ctx context.Context, teamID, userID, permissionID, recursive string, opts ...base_http_client.RequestOption) (*RevokeTeamPermissionResponse, error) {
 	path := fmt.Sprintf("/team-permissions/%s/%s/%s", teamID, userID, permissionID)
 	queryParams := url.Values{}
 
@@ -91,13 +117,11 @@ func (c *Client) RevokeTeamPermissionFromUser(teamID, userID, permissionID, recu
 	utils.AddOptionalStringParam(queryParams, "permission_id", permissionID)
 	utils.AddOptionalStringParam(queryParams, "recursive", recursive)
 
-	rawResponse, err := c.HTTPClient.SendRequest("DELETE", path, queryParams, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+	return transport.Do[transport.NoBody, RevokeTeamPermissionResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation:    "permissions.RevokeTeamPermissionFromUser",
+		Method:       "DELETE",
+		PathTemplate: "/team-permissions/{team_id}/{user_id}/{permission_id}",
+		Path:         path,
+		Query:        queryParams,
+	}, nil, opts...)
 }
@@ -36,6 +36,7 @@ func TestListTeamPermissions(t *testing.T) {
 		assert.Equal(t, "GET", r.Method)
 		assert.Equal(t, "test_team", r.URL.Query().Get("team_id"))
 		assert.Equal(t, "test_user", r.URL.Query().Get("user_id"))
+		assert.Equal(t, "true", r.URL.Query().Get("recursive"))
 
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
@@ -44,7 +45,7 @@ func TestListTeamPermissions(t *testing.T) {
 	defer server.Close()
 
 	client := setupTestClient(server.URL)
-	response, err := client.ListTeamPermissions("test_team", "test_user", "", "")
+	response, err := client.ListTeamPermissions(&ListTeamPermissionsOptions{TeamID: "test_team", UserID: "test_user", Recursive: true})
 
 	assert.NoError(t, err)
 	assert.Len(t, response.Items, 1)
@@ -1,3 +1,42 @@
 package permissions
 
+import (
+	"net/url"
+
+	"github.com/BlaisePopov/stack-auth/base-http-client/utils"
+)
+
 // Тело запроса для всех методов пустое согласно документации
+
+// ListTeamPermissionsOptions содержит параметры запроса списка командных разрешений.
+// Нулевое значение поля означает, что параметр не передается.
+type ListTeamPermissionsOptions struct {
+	// TeamID оставляет только разрешения в команде
+	TeamID string
+	// UserID оставляет только разрешения пользователя
+	UserID string
+	// PermissionID оставляет только указанное разрешение
+	PermissionID string
+	// Recursive включает разрешения, полученные через вложенные разрешения
+	Recursive bool
+	// Cursor - курсор страницы из Pagination.NextCursor предыдущего ответа
+	Cursor string
+	// Limit ограничивает количество разрешений на странице
+	Limit int
+}
+
+// values возвращает параметры строки запроса
+func (o *ListTeamPermissionsOptions) values() url.Values {
+	queryParams := url.Values{}
+	if o == nil {
+		return queryParams
+	}
+
+	utils.AddOptionalStringParam(queryParams, "team_id", o.TeamID)
+	utils.AddOptionalStringParam(queryParams, "user_id", o.UserID)
+	utils.AddOptionalStringParam(queryParams, "permission_id", o.PermissionID)
+	utils.AddOptionalBoolParam(queryParams, "recursive", o.Recursive)
+	utils.AddOptionalStringParam(queryParams, "cursor", o.Cursor)
+	utils.AddOptionalIntParam(queryParams, "limit", o.Limit)
+	return queryParams
+}
@@ -1,38 +1,38 @@
 package projects
 
 import (
-    "encoding/json"
-    "fmt"
-    base_http_client "github.com/BlaisePopov/stack-auth/base-http-client/interface"
-    "net/url"
+	"context"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client/interface"
+	"github.com/BlaisePopov/stack-auth/internal/transport"
 )
 
 // Client представляет клиент для работы с проектами
 type Client struct {
-    HTTPClient base_http_client.BaseHTTPClient
+	HTTPClient base_http_client.BaseHTTPClient
 }
 
 // NewClient создает новый экземпляр клиента для работы с проектами
 func NewClient(httpClient base_http_client.BaseHTTPClient) *Client {
-    return &Client{HTTPClient: httpClient}
+	return &Client{HTTPClient: httpClient}
 }
 
 // GetCurrentProject возвращает информацию о текущем проекте. [https://docs.stack-auth.com/next/rest-api/server/projects/get-the-current-project]
 //
+// Входные параметры:
+//   - opts: параметры отдельного запроса (опционально)
+//
 // Возвращаемое значение:
 //   - объект GetCurrentProjectResponse с данными проекта
 //   - ошибка, если возникла при выполнении запроса или декодировании ответа
-func (c *Client) GetCurrentProject() (*GetCurrentProjectResponse, error) {
-    response := &GetCurrentProjectResponse{}
-
-    rawResponse, err := c.HTTPClient.SendRequest("GET", "/projects/current", url.Values{}, nil)
-    if err != nil {
-        return nil, err
-    }
+func (c *Client) GetCurrentProject(opts ...base_http_client.RequestOption) (*GetCurrentProjectResponse, error) {
+	return c.GetCurrentProjectCtx(context.Background(), opts...)
+}
 
-    if err := json.Unmarshal(rawResponse, response); err != nil {
-        return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-    }
-    
-    return response, nil
+// GetCurrentProjectCtx выполняет GetCurrentProject с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) GetCurrentProjectCtx(ctx context.Context, opts ...base_http_client.RequestOption) (*GetCurrentProjectResponse, error) {
+	return transport.Do[transport.NoBody, GetCurrentProjectResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "projects.GetCurrentProject",
+		Method:    "GET",
+		Path:      "/projects/current",
+	}, nil, opts...)
 }
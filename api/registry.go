@@ -0,0 +1,184 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+// CredentialResolver загружает конфигурацию клиента для проекта Stack Auth
+type CredentialResolver interface {
+	Resolve(ctx context.Context, projectID string) (base_http_client.Config, error)
+}
+
+// CredentialResolverFunc позволяет использовать функцию как CredentialResolver
+type CredentialResolverFunc func(ctx context.Context, projectID string) (base_http_client.Config, error)
+
+// Resolve вызывает f(ctx, projectID)
+func (f CredentialResolverFunc) Resolve(ctx context.Context, projectID string) (base_http_client.Config, error) {
+	return f(ctx, projectID)
+}
+
+// StaticResolver возвращает CredentialResolver с фиксированным набором конфигураций по идентификатору проекта
+func StaticResolver(configs map[string]base_http_client.Config) CredentialResolver {
+	return CredentialResolverFunc(func(_ context.Context, projectID string) (base_http_client.Config, error) {
+		cfg, ok := configs[projectID]
+		if !ok {
+			return base_http_client.Config{}, fmt.Errorf("учетные данные проекта %q не найдены", projectID)
+		}
+		return cfg, nil
+	})
+}
+
+// NewSharedHTTPClient создает HTTP-клиент с пулом соединений, рассчитанным на запросы множества проектов к одному хосту
+func NewSharedHTTPClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConns = 256
+	transport.MaxIdleConnsPerHost = 256
+	transport.IdleConnTimeout = 90 * time.Second
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   base_http_client.DefaultRequestTimeout,
+	}
+}
+
+// RegistryConfig описывает реестр клиентов
+type RegistryConfig struct {
+	// Resolver загружает учетные данные проекта. Обязательное поле
+	Resolver CredentialResolver
+	// HTTPClient - общий HTTP-клиент для всех проектов. По умолчанию NewSharedHTTPClient()
+	HTTPClient *http.Client
+	// Configure дополняет конфигурацию проекта общими настройками (политика повторов, логирование, метрики)
+	Configure func(cfg *base_http_client.Config)
+}
+
+// registryEntry - клиент проекта или ожидание его создания
+type registryEntry struct {
+	ready  chan struct{}
+	client *Client
+	err    error
+}
+
+// Registry лениво создает и хранит клиенты api.Client для множества проектов Stack Auth.
+// Все клиенты используют общий пул соединений.
+type Registry struct {
+	config  RegistryConfig
+	mu      sync.Mutex
+	entries map[string]*registryEntry
+}
+
+// NewRegistry создает реестр клиентов
+func NewRegistry(cfg RegistryConfig) *Registry {
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = NewSharedHTTPClient()
+	}
+	return &Registry{
+		config:  cfg,
+		entries: map[string]*registryEntry{},
+	}
+}
+
+// Client возвращает клиент проекта, создавая его при первом обращении.
+// Одновременные обращения к новому проекту загружают учетные данные один раз.
+// Если загрузка прервана отменой контекста вызвавшего ее обращения, ожидающие обращения повторяют ее со своим контекстом.
+func (r *Registry) Client(ctx context.Context, projectID string) (*Client, error) {
+	for {
+		client, leader, err := r.client(ctx, projectID)
+		if leader || ctx.Err() != nil || !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			return client, err
+		}
+	}
+}
+
+// client возвращает клиент проекта или создает его. leader сообщает, что клиент создавался этим обращением
+func (r *Registry) client(ctx context.Context, projectID string) (client *Client, leader bool, err error) {
+	r.mu.Lock()
+	entry, ok := r.entries[projectID]
+	if !ok {
+		entry = &registryEntry{ready: make(chan struct{})}
+		r.entries[projectID] = entry
+		r.mu.Unlock()
+
+		entry.client, entry.err = r.build(ctx, projectID)
+		if entry.err != nil {
+			r.mu.Lock()
+			if r.entries[projectID] == entry {
+				delete(r.entries, projectID)
+			}
+			r.mu.Unlock()
+		}
+		close(entry.ready)
+		return entry.client, true, entry.err
+	}
+	r.mu.Unlock()
+
+	select {
+	case <-entry.ready:
+		return entry.client, false, entry.err
+	case <-ctx.Done():
+		return nil, false, ctx.Err()
+	}
+}
+
+// Rotate заново загружает учетные данные проекта и заменяет его клиент.
+// Запросы, начатые через прежний клиент, завершаются с прежними учетными данными.
+func (r *Registry) Rotate(ctx context.Context, projectID string) (*Client, error) {
+	client, err := r.build(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &registryEntry{ready: make(chan struct{}), client: client}
+	close(entry.ready)
+
+	r.mu.Lock()
+	r.entries[projectID] = entry
+	r.mu.Unlock()
+	return client, nil
+}
+
+// Evict удаляет клиент проекта. Следующее обращение загрузит учетные данные заново
+func (r *Registry) Evict(projectID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.entries, projectID)
+}
+
+// Len возвращает число проектов в реестре
+func (r *Registry) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.entries)
+}
+
+// build загружает учетные данные проекта и создает клиент
+func (r *Registry) build(ctx context.Context, projectID string) (*Client, error) {
+	if r.config.Resolver == nil {
+		return nil, &base_http_client.ConfigError{Field: "Resolver", Message: "не задан источник учетных данных"}
+	}
+
+	cfg, err := r.config.Resolver.Resolve(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ProjectID == "" {
+		cfg.ProjectID = projectID
+	}
+	if cfg.ProjectID != projectID {
+		return nil, &base_http_client.ConfigError{Field: "ProjectID", Message: fmt.Sprintf("ожидается %q, получено %q", projectID, cfg.ProjectID)}
+	}
+	cfg.HTTPClient = r.config.HTTPClient
+	if r.config.Configure != nil {
+		r.config.Configure(&cfg)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return NewClient(cfg), nil
+}
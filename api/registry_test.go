@@ -0,0 +1,168 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/BlaisePopov/stack-auth/api/projects"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+	"github.com/stretchr/testify/assert"
+)
+
+func newProjectServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/projects/current", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(&projects.GetCurrentProjectResponse{
+			ID:          r.Header.Get("X-Stack-Project-Id"),
+			DisplayName: r.Header.Get("X-Stack-Secret-Server-Key"),
+		})
+	}))
+}
+
+func TestRegistry_LazyClientsPerProject(t *testing.T) {
+	server := newProjectServer(t)
+	defer server.Close()
+
+	var resolved int32
+	registry := NewRegistry(RegistryConfig{
+		Resolver: CredentialResolverFunc(func(ctx context.Context, projectID string) (base_http_client.Config, error) {
+			atomic.AddInt32(&resolved, 1)
+			return base_http_client.Config{BaseURL: server.URL, SecretServerKey: "secret-" + projectID}, nil
+		}),
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := registry.Client(context.Background(), "tenant-a")
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(&resolved))
+
+	clientA, _ := registry.Client(context.Background(), "tenant-a")
+	clientB, err := registry.Client(context.Background(), "tenant-b")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, registry.Len())
+
+	project, err := clientB.Projects.GetCurrentProject()
+	assert.NoError(t, err)
+	assert.Equal(t, "tenant-b", project.ID)
+	assert.Equal(t, "secret-tenant-b", project.DisplayName)
+
+	project, err = clientA.Projects.GetCurrentProject()
+	assert.NoError(t, err)
+	assert.Equal(t, "tenant-a", project.ID)
+}
+
+func TestRegistry_RotateAndEvict(t *testing.T) {
+	server := newProjectServer(t)
+	defer server.Close()
+
+	keys := map[string]base_http_client.Config{
+		"tenant-a": {BaseURL: server.URL, SecretServerKey: "old-secret"},
+	}
+	var mu sync.Mutex
+	registry := NewRegistry(RegistryConfig{
+		Resolver: CredentialResolverFunc(func(ctx context.Context, projectID string) (base_http_client.Config, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			return StaticResolver(keys).Resolve(ctx, projectID)
+		}),
+	})
+
+	client, err := registry.Client(context.Background(), "tenant-a")
+	assert.NoError(t, err)
+	project, _ := client.Projects.GetCurrentProject()
+	assert.Equal(t, "old-secret", project.DisplayName)
+
+	mu.Lock()
+	keys["tenant-a"] = base_http_client.Config{BaseURL: server.URL, SecretServerKey: "new-secret"}
+	mu.Unlock()
+
+	rotated, err := registry.Rotate(context.Background(), "tenant-a")
+	assert.NoError(t, err)
+	project, _ = rotated.Projects.GetCurrentProject()
+	assert.Equal(t, "new-secret", project.DisplayName)
+
+	current, _ := registry.Client(context.Background(), "tenant-a")
+	assert.Same(t, rotated, current)
+
+	registry.Evict("tenant-a")
+	assert.Equal(t, 0, registry.Len())
+}
+
+func TestRegistry_ResolverErrorsAreNotCached(t *testing.T) {
+	var calls int32
+	registry := NewRegistry(RegistryConfig{
+		Resolver: CredentialResolverFunc(func(ctx context.Context, projectID string) (base_http_client.Config, error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				return base_http_client.Config{}, errors.New("vault unavailable")
+			}
+			return base_http_client.Config{SecretServerKey: "secret"}, nil
+		}),
+	})
+
+	_, err := registry.Client(context.Background(), "tenant-a")
+	assert.EqualError(t, err, "vault unavailable")
+	_, err = registry.Client(context.Background(), "tenant-a")
+	assert.NoError(t, err)
+
+	_, err = registry.Client(context.Background(), "unknown")
+	assert.NoError(t, err)
+
+	invalid := NewRegistry(RegistryConfig{Resolver: StaticResolver(map[string]base_http_client.Config{
+		"tenant-a": {ProjectID: "tenant-a"},
+	})})
+	_, err = invalid.Client(context.Background(), "tenant-a")
+	var configError *base_http_client.ConfigError
+	assert.True(t, errors.As(err, &configError))
+	assert.Equal(t, "SecretServerKey", configError.Field)
+}
+
+func TestRegistry_WaitersRetryAfterLeaderCanceled(t *testing.T) {
+	var calls int32
+	started := make(chan struct{})
+	registry := NewRegistry(RegistryConfig{
+		Resolver: CredentialResolverFunc(func(ctx context.Context, projectID string) (base_http_client.Config, error) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				close(started)
+				<-ctx.Done()
+				return base_http_client.Config{}, ctx.Err()
+			}
+			return base_http_client.Config{SecretServerKey: "secret"}, nil
+		}),
+	})
+
+	leaderCtx, cancel := context.WithCancel(context.Background())
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := registry.Client(leaderCtx, "tenant-a")
+		leaderErr <- err
+	}()
+	<-started
+
+	waiter := make(chan error, 1)
+	go func() {
+		_, err := registry.Client(context.Background(), "tenant-a")
+		waiter <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	assert.ErrorIs(t, <-leaderErr, context.Canceled)
+	assert.NoError(t, <-waiter)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
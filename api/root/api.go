@@ -1,34 +1,36 @@
 package root
 
 import (
-    "encoding/json"
-    "fmt"
-    base_http_client "github.com/BlaisePopov/stack-auth/base-http-client/interface"
+	"context"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client/interface"
+	"github.com/BlaisePopov/stack-auth/internal/transport"
 )
 
 // Client представляет клиент для работы с корневым эндпоинтом API
 type Client struct {
-    HTTPClient base_http_client.BaseHTTPClient
+	HTTPClient base_http_client.BaseHTTPClient
 }
 
 // NewClient создаёт новый экземпляр клиента для корневого эндпоинта
 func NewClient(httpClient base_http_client.BaseHTTPClient) *Client {
-    return &Client{HTTPClient: httpClient}
+	return &Client{HTTPClient: httpClient}
 }
 
 // GetAPIInfo возвращает информацию о API. [https://docs.stack-auth.com/next/rest-api/server//api-v-1]
 //
+// Входные параметры:
+//   - opts: параметры отдельного запроса (опционально)
+//
 // Возвращаемое значение: объект GetAPIInfoResponse и ошибка, если она возникла
-func (c *Client) GetAPIInfo() (*GetAPIInfoResponse, error) {
-    response := &GetAPIInfoResponse{}
-
-    rawResponse, err := c.HTTPClient.SendRequest("GET", "", nil, nil)
-    if err != nil {
-        return nil, err
-    }
+func (c *Client) GetAPIInfo(opts ...base_http_client.RequestOption) (*GetAPIInfoResponse, error) {
+	return c.GetAPIInfoCtx(context.Background(), opts...)
+}
 
-    if err := json.Unmarshal(rawResponse, response); err != nil {
-        return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-    }
-    return response, nil
+// GetAPIInfoCtx выполняет GetAPIInfo с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) GetAPIInfoCtx(ctx context.Context, opts ...base_http_client.RequestOption) (*GetAPIInfoResponse, error) {
+	return transport.Do[transport.NoBody, GetAPIInfoResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "root.GetAPIInfo",
+		Method:    "GET",
+		Path:      "",
+	}, nil, opts...)
 }
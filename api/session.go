@@ -0,0 +1,245 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/BlaisePopov/stack-auth/api/sessions"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+	"github.com/BlaisePopov/stack-auth/stackauthjwt"
+)
+
+// DefaultRefreshBefore - запас времени до истечения access token, за который сессия обновляет его заранее
+const DefaultRefreshBefore = 30 * time.Second
+
+// SessionTokens - токены сессии пользователя
+type SessionTokens struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// SessionConfig описывает сессию пользователя
+type SessionConfig struct {
+	// AccessToken - текущий access token. Если не задан, он будет получен по RefreshToken при первом запросе
+	AccessToken string
+	// RefreshToken - refresh token сессии, обязательное поле
+	RefreshToken string
+	// RefreshBefore - запас времени до истечения access token, за который он обновляется заранее.
+	// По умолчанию DefaultRefreshBefore
+	RefreshBefore time.Duration
+	// RefreshTimeout ограничивает время обновления access token. По умолчанию base_http_client.DefaultRequestTimeout
+	RefreshTimeout time.Duration
+	// OnRotate вызывается после каждого обновления токенов, например чтобы сохранить их.
+	// Вызывается синхронно в горутине, выполнившей обновление
+	OnRotate func(tokens SessionTokens)
+	// Now возвращает текущее время. По умолчанию time.Now
+	Now func() time.Time
+}
+
+// sessionRefresh - выполняющееся обновление access token
+type sessionRefresh struct {
+	done chan struct{}
+	err  error
+}
+
+// Session выполняет запросы от имени пользователя и сама поддерживает его access token действующим.
+// Токен обновляется заранее, незадолго до истечения срока действия, а запрос, получивший ответ 401,
+// повторяется один раз после обновления. Одновременные обновления объединяются в один запрос.
+// Методы Session безопасны для конкурентного использования.
+type Session struct {
+	base     *base_http_client.Client
+	sessions *sessions.Client
+	config   SessionConfig
+	client   *Client
+
+	mu         sync.Mutex
+	tokens     SessionTokens
+	expiresAt  time.Time
+	refreshing *sessionRefresh
+}
+
+// NewSession создает сессию пользователя поверх базового HTTP-клиента c.
+// Тип доступа и ключи проекта берутся из конфигурации c, токены - из config
+func (c *Client) NewSession(config SessionConfig) (*Session, error) {
+	if config.RefreshToken == "" {
+		return nil, &base_http_client.ConfigError{Field: "RefreshToken", Message: "обязателен для сессии"}
+	}
+	if config.RefreshBefore <= 0 {
+		config.RefreshBefore = DefaultRefreshBefore
+	}
+	if config.RefreshTimeout <= 0 {
+		config.RefreshTimeout = base_http_client.DefaultRequestTimeout
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+
+	s := &Session{
+		base:     c.HTTPClient,
+		sessions: sessions.NewClient(c.HTTPClient),
+		config:   config,
+		tokens:   SessionTokens{AccessToken: config.AccessToken, RefreshToken: config.RefreshToken},
+	}
+	s.expiresAt = expiryOf(config.AccessToken)
+	s.client = newClientView(c.HTTPClient, s)
+	return s, nil
+}
+
+// Client возвращает клиент, все разделы которого выполняют запросы от имени пользователя сессии
+func (s *Session) Client() *Client {
+	return s.client
+}
+
+// Tokens возвращает текущие токены сессии
+func (s *Session) Tokens() SessionTokens {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens
+}
+
+// AccessToken возвращает действующий access token, при необходимости обновляя его.
+// Если заблаговременное обновление не удалось, а текущий токен еще не истек, возвращается текущий токен
+func (s *Session) AccessToken(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	token, expiresAt := s.tokens.AccessToken, s.expiresAt
+	s.mu.Unlock()
+
+	now := s.config.Now()
+	if token != "" && (expiresAt.IsZero() || now.Before(expiresAt.Add(-s.config.RefreshBefore))) {
+		return token, nil
+	}
+	if err := s.refresh(ctx, token); err != nil {
+		if token != "" && now.Before(expiresAt) {
+			return token, nil
+		}
+		return "", err
+	}
+	return s.Tokens().AccessToken, nil
+}
+
+// Refresh принудительно обновляет access token
+func (s *Session) Refresh(ctx context.Context) error {
+	return s.refresh(ctx, s.Tokens().AccessToken)
+}
+
+// refresh обновляет access token, если текущий токен все еще равен stale.
+// Если обновление уже выполняется, дожидается его результата.
+// Обновление общее для всех ожидающих вызовов, поэтому выполняется с контекстом без отмены,
+// ограниченным RefreshTimeout: отмена ctx прекращает только ожидание
+func (s *Session) refresh(ctx context.Context, stale string) error {
+	s.mu.Lock()
+	if s.tokens.AccessToken != stale {
+		s.mu.Unlock()
+		return nil
+	}
+	call := s.refreshing
+	if call == nil {
+		call = &sessionRefresh{done: make(chan struct{})}
+		s.refreshing = call
+		refreshToken := s.tokens.RefreshToken
+		go func() {
+			rotateCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.config.RefreshTimeout)
+			defer cancel()
+			call.err = s.rotate(rotateCtx, refreshToken)
+			close(call.done)
+		}()
+	}
+	s.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// rotate получает новый access token по refresh token и сообщает о смене токенов
+func (s *Session) rotate(ctx context.Context, refreshToken string) error {
+	resp, err := s.sessions.RefreshAccessTokenCtx(ctx, refreshToken, base_http_client.WithUserTokens("", refreshToken))
+
+	s.mu.Lock()
+	s.refreshing = nil
+	if err != nil {
+		s.mu.Unlock()
+		return fmt.Errorf("не удалось обновить access token: %w", err)
+	}
+	s.tokens.AccessToken = resp.AccessToken
+	s.expiresAt = expiryOf(resp.AccessToken)
+	tokens := s.tokens
+	s.mu.Unlock()
+
+	if s.config.OnRotate != nil {
+		s.config.OnRotate(tokens)
+	}
+	return nil
+}
+
+// SendRequest отправляет HTTP-запрос к API от имени пользователя сессии
+func (s *Session) SendRequest(method, path string, queryParams url.Values, body []byte, opts ...base_http_client.RequestOption) ([]byte, error) {
+	return s.SendRequestContext(context.Background(), method, path, queryParams, body, opts...)
+}
+
+// SendRequestContext отправляет HTTP-запрос к API от имени пользователя сессии с учетом контекста
+func (s *Session) SendRequestContext(ctx context.Context, method, path string, queryParams url.Values, body []byte, opts ...base_http_client.RequestOption) ([]byte, error) {
+	return s.Do(ctx, &base_http_client.Request{
+		Method: method,
+		Path:   path,
+		Query:  queryParams,
+		Body:   body,
+	}, opts...)
+}
+
+// Do выполняет запрос с токенами сессии. При ответе 401 обновляет access token и повторяет запрос один раз.
+// Токены, переданные в opts, имеют приоритет над токенами сессии
+func (s *Session) Do(ctx context.Context, request *base_http_client.Request, opts ...base_http_client.RequestOption) ([]byte, error) {
+	token, err := s.AccessToken(ctx)
+	if err != nil {
+		return nil, err
+	}
+	body, err := s.base.Do(ctx, request, s.withTokens(token, opts)...)
+	if !isUnauthorized(err) {
+		return body, err
+	}
+
+	if refreshErr := s.refresh(ctx, token); refreshErr != nil {
+		return nil, errors.Join(err, refreshErr)
+	}
+	return s.base.Do(ctx, request, s.withTokens(s.Tokens().AccessToken, opts)...)
+}
+
+// withTokens добавляет токены сессии перед опциями запроса.
+// Токены пользователя из конфигурации клиента и Config.TokenStore в запросах сессии не используются
+func (s *Session) withTokens(accessToken string, opts []base_http_client.RequestOption) []base_http_client.RequestOption {
+	return append([]base_http_client.RequestOption{
+		base_http_client.WithUserTokens(accessToken, s.Tokens().RefreshToken),
+	}, opts...)
+}
+
+// isUnauthorized сообщает, что запрос отклонен из-за access token и его стоит повторить после обновления.
+// Ответы 401 с другими кодами, например INVALID_SECRET_SERVER_KEY, обновлением токена не исправить
+func isUnauthorized(err error) bool {
+	var apiErr *base_http_client.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		return false
+	}
+	return apiErr.Code == "" || errors.Is(apiErr, base_http_client.ErrAccessTokenExpired) ||
+		errors.Is(apiErr, base_http_client.ErrUnparsableAccessToken)
+}
+
+// expiryOf возвращает время истечения access token или нулевое время, если токен не удалось разобрать
+func expiryOf(accessToken string) time.Time {
+	if accessToken == "" {
+		return time.Time{}
+	}
+	claims, err := stackauthjwt.ParseUnverified(accessToken)
+	if err != nil {
+		return time.Time{}
+	}
+	return claims.ExpiresAt
+}
@@ -0,0 +1,193 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/BlaisePopov/stack-auth/api/password"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+	"github.com/BlaisePopov/stack-auth/stackauthtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// sessionClock - управляемые часы, общие для fake-сервера и сессии
+type sessionClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *sessionClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *sessionClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// newTestSession запускает fake-сервер, входит новым пользователем и создает сессию с клиентским доступом
+func newTestSession(t *testing.T, clock *sessionClock, config SessionConfig) (*stackauthtest.Server, *Session, *int32) {
+	server := stackauthtest.NewServer(stackauthtest.WithClock(clock.Now))
+	t.Cleanup(server.Close)
+
+	accessToken, refreshToken, err := server.SignIn(server.CreateUser("john@example.com", ""))
+	assert.NoError(t, err)
+
+	var rotations int32
+	onRotate := config.OnRotate
+	config.AccessToken, config.RefreshToken, config.Now = accessToken, refreshToken, clock.Now
+	config.OnRotate = func(tokens SessionTokens) {
+		atomic.AddInt32(&rotations, 1)
+		if onRotate != nil {
+			onRotate(tokens)
+		}
+	}
+	session, err := NewClient(server.ClientConfig("", "")).NewSession(config)
+	assert.NoError(t, err)
+	return server, session, &rotations
+}
+
+func TestSession_ProactiveRefresh(t *testing.T) {
+	clock := &sessionClock{now: time.Unix(1700000000, 0)}
+	var rotated SessionTokens
+	_, session, rotations := newTestSession(t, clock, SessionConfig{OnRotate: func(tokens SessionTokens) { rotated = tokens }})
+	initial := session.Tokens()
+
+	user, err := session.Client().Users.GetCurrentUser()
+	assert.NoError(t, err)
+	assert.Equal(t, "john@example.com", user.PrimaryEmail)
+	assert.Equal(t, int32(0), atomic.LoadInt32(rotations))
+
+	clock.Advance(stackauthtest.DefaultAccessTokenTTL - DefaultRefreshBefore/2)
+	_, err = session.Client().Users.GetCurrentUser()
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(rotations))
+	assert.NotEqual(t, initial.AccessToken, session.Tokens().AccessToken)
+	assert.Equal(t, initial.RefreshToken, session.Tokens().RefreshToken)
+	assert.Equal(t, session.Tokens(), rotated)
+}
+
+func TestSession_RetriesAfterUnauthorized(t *testing.T) {
+	clock := &sessionClock{now: time.Unix(1700000000, 0)}
+	server, session, rotations := newTestSession(t, clock, SessionConfig{})
+	server.ExpireAccessToken(session.Tokens().AccessToken)
+
+	user, err := session.Client().Users.GetCurrentUser()
+	assert.NoError(t, err)
+	assert.Equal(t, "john@example.com", user.PrimaryEmail)
+	assert.Equal(t, int32(1), atomic.LoadInt32(rotations))
+}
+
+func TestSession_ConcurrentRefreshDeduplicated(t *testing.T) {
+	clock := &sessionClock{now: time.Unix(1700000000, 0)}
+	server, session, rotations := newTestSession(t, clock, SessionConfig{})
+	server.ExpireAccessToken(session.Tokens().AccessToken)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := session.Client().Users.GetCurrentUser()
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), atomic.LoadInt32(rotations))
+}
+
+func TestSession_RefreshSurvivesCallerCancel(t *testing.T) {
+	clock := &sessionClock{now: time.Unix(1700000000, 0)}
+	release := make(chan struct{})
+	_, session, rotations := newTestSession(t, clock, SessionConfig{OnRotate: func(SessionTokens) { <-release }})
+	initial := session.Tokens()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, session.Refresh(ctx), context.Canceled)
+
+	close(release)
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(rotations) == 1 }, time.Second, time.Millisecond)
+	assert.NotEqual(t, initial.AccessToken, session.Tokens().AccessToken)
+}
+
+func TestSession_RefreshTokenRevoked(t *testing.T) {
+	clock := &sessionClock{now: time.Unix(1700000000, 0)}
+	server, session, rotations := newTestSession(t, clock, SessionConfig{})
+	_, err := NewClient(server.Config()).Sessions.SignOut(session.Tokens().RefreshToken)
+	assert.NoError(t, err)
+
+	_, err = session.Client().Users.GetCurrentUser()
+	assert.ErrorIs(t, err, base_http_client.ErrRefreshTokenNotFoundOrExpired)
+	assert.Equal(t, int32(0), atomic.LoadInt32(rotations))
+	assert.ErrorIs(t, session.Refresh(context.Background()), base_http_client.ErrRefreshTokenNotFoundOrExpired)
+}
+
+func TestSession_WithoutAccessToken(t *testing.T) {
+	clock := &sessionClock{now: time.Unix(1700000000, 0)}
+	server, session, _ := newTestSession(t, clock, SessionConfig{})
+
+	lazy, err := NewClient(server.ClientConfig("", "")).NewSession(SessionConfig{RefreshToken: session.Tokens().RefreshToken, Now: clock.Now})
+	assert.NoError(t, err)
+	token, err := lazy.AccessToken(context.Background())
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+
+	_, err = NewClient(server.ClientConfig("", "")).NewSession(SessionConfig{AccessToken: token})
+	var configErr *base_http_client.ConfigError
+	assert.ErrorAs(t, err, &configErr)
+}
+
+func TestIsUnauthorized(t *testing.T) {
+	cases := map[*base_http_client.APIError]bool{
+		{StatusCode: 401}: true,
+		{StatusCode: 401, Code: "ACCESS_TOKEN_EXPIRED"}:               true,
+		{StatusCode: 401, Code: "UNPARSABLE_ACCESS_TOKEN"}:            true,
+		{StatusCode: 401, Code: "INVALID_SECRET_SERVER_KEY"}:          false,
+		{StatusCode: 401, Code: "INVALID_PUBLISHABLE_CLIENT_KEY"}:     false,
+		{StatusCode: 401, Code: "INSUFFICIENT_ACCESS_TYPE"}:           false,
+		{StatusCode: 401, Code: "REFRESH_TOKEN_NOT_FOUND_OR_EXPIRED"}: false,
+		{StatusCode: 403, Code: "ACCESS_TOKEN_EXPIRED"}:               false,
+	}
+	for err, expected := range cases {
+		assert.Equal(t, expected, isUnauthorized(fmt.Errorf("wrapped: %w", err)), err.Code)
+	}
+}
+
+func TestSession_DoesNotUseConfigTokenStore(t *testing.T) {
+	server := stackauthtest.NewServer()
+	defer server.Close()
+	server.CreateUser("alice@example.com", "alice-password")
+	server.CreateUser("bob@example.com", "bob-password")
+
+	shared := base_http_client.Tokens{AccessToken: "alice-access", RefreshToken: "alice-refresh"}
+	store := base_http_client.NewMemoryTokenStore(shared)
+	config := server.Config()
+	config.TokenStore = store
+	client := NewClient(config)
+
+	bob, err := NewClient(server.Config()).Password.SignInWithEmail(&password.SignInRequest{Email: "bob@example.com", Password: "bob-password"})
+	assert.NoError(t, err)
+	session, err := client.NewSession(SessionConfig{RefreshToken: bob.RefreshToken})
+	assert.NoError(t, err)
+
+	user, err := session.Client().Users.GetCurrentUser()
+	assert.NoError(t, err)
+	if assert.NotNil(t, user) {
+		assert.Equal(t, "bob@example.com", user.PrimaryEmail)
+	}
+	_, err = session.Client().Password.SignInWithEmail(&password.SignInRequest{Email: "alice@example.com", Password: "alice-password"})
+	assert.NoError(t, err)
+	assert.NoError(t, session.Refresh(context.Background()))
+
+	stored, err := store.Load(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, shared, stored)
+}
@@ -1,10 +1,9 @@
 package sessions
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
 	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client/interface"
-	"net/url"
+	"github.com/BlaisePopov/stack-auth/internal/transport"
 )
 
 // Client представляет клиент для работы с сессиями пользователя
@@ -21,63 +20,66 @@ func NewClient(httpClient base_http_client.BaseHTTPClient) *Client {
 //
 // Входные параметры:
 //   - request: данные для создания сессии
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект CreateSessionResponse и ошибка, если она возникла
-func (c *Client) CreateSession(request *CreateSessionRequest) (*CreateSessionResponse, error) {
-	response := &CreateSessionResponse{}
-
-	body, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-	}
-
-	rawResponse, err := c.HTTPClient.SendRequest("POST", "/auth/sessions", url.Values{}, body)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) CreateSession(request *CreateSessionRequest, opts ...base_http_client.RequestOption) (*CreateSessionResponse, error) {
+	return c.CreateSessionCtx(context.Background(), request, opts...)
+}
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+// CreateSessionCtx выполняет CreateSession с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) CreateSessionCtx(ctx context.Context, request *CreateSessionRequest, opts ...base_http_client.RequestOption) (*CreateSessionResponse, error) {
+	return transport.Do[CreateSessionRequest, CreateSessionResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "sessions.CreateSession",
+		Method:    "POST",
+		Path:      "/auth/sessions",
+	}, request, opts...)
 }
 
 // SignOut завершает текущую сессию пользователя [https://docs.stack-auth.com/next/rest-api/server/sessions/sign-out-of-the-current-session]
 //
 // Входные параметры:
-//   - refreshToken: refresh token из заголовка запроса
+//   - refreshToken: refresh token завершаемой сессии. Если пустой, используется refresh token из конфигурации клиента
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект SignOutResponse и ошибка, если она возникла
-func (c *Client) SignOut(refreshToken string) (*SignOutResponse, error) {
-	response := &SignOutResponse{}
-
-	rawResponse, err := c.HTTPClient.SendRequest("DELETE", "/auth/sessions/current", url.Values{}, nil)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) SignOut(refreshToken string, opts ...base_http_client.RequestOption) (*SignOutResponse, error) {
+	return c.SignOutCtx(context.Background(), refreshToken, opts...)
+}
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+// SignOutCtx выполняет SignOut с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) SignOutCtx(ctx context.Context, refreshToken string, opts ...base_http_client.RequestOption) (*SignOutResponse, error) {
+	return transport.Do[transport.NoBody, SignOutResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "sessions.SignOut",
+		Method:    "DELETE",
+		Path:      "/auth/sessions/current",
+	}, nil, withRefreshToken(refreshToken, opts)...)
 }
 
 // RefreshAccessToken обновляет access token с использованием refresh token [https://docs.stack-auth.com/next/rest-api/server/sessions/refresh-access-token]
 //
 // Входные параметры:
-//   - refreshToken: refresh token из заголовка запроса
+//   - refreshToken: refresh token сессии. Если пустой, используется refresh token из конфигурации клиента
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект RefreshAccessTokenResponse и ошибка, если она возникла
-func (c *Client) RefreshAccessToken(refreshToken string) (*RefreshAccessTokenResponse, error) {
-	response := &RefreshAccessTokenResponse{}
+func (c *Client) RefreshAccessToken(refreshToken string, opts ...base_http_client.RequestOption) (*RefreshAccessTokenResponse, error) {
+	return c.RefreshAccessTokenCtx(context.Background(), refreshToken, opts...)
+}
 
-	rawResponse, err := c.HTTPClient.SendRequest("POST", "/auth/sessions/current/refresh", url.Values{}, nil)
-	if err != nil {
-		return nil, err
-	}
+// RefreshAccessTokenCtx выполняет RefreshAccessToken с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) RefreshAccessTokenCtx(ctx context.Context, refreshToken string, opts ...base_http_client.RequestOption) (*RefreshAccessTokenResponse, error) {
+	return transport.Do[transport.NoBody, RefreshAccessTokenResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "sessions.RefreshAccessToken",
+		Method:    "POST",
+		Path:      "/auth/sessions/current/refresh",
+	}, nil, withRefreshToken(refreshToken, opts)...)
+}
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
+// withRefreshToken добавляет refresh token перед опциями запроса, чтобы опции могли его переопределить
+func withRefreshToken(refreshToken string, opts []base_http_client.RequestOption) []base_http_client.RequestOption {
+	if refreshToken == "" {
+		return opts
 	}
-	return response, nil
+	return append([]base_http_client.RequestOption{base_http_client.WithRefreshToken(refreshToken)}, opts...)
 }
@@ -51,6 +51,7 @@ func TestSignOut(t *testing.T) {
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "/auth/sessions/current", r.URL.Path)
+		assert.Equal(t, testRefreshToken, r.Header.Get("X-Stack-Refresh-Token"))
 		assert.Equal(t, "DELETE", r.Method)
 
 		w.Header().Set("Content-Type", "application/json")
@@ -72,6 +73,7 @@ func TestRefreshAccessToken(t *testing.T) {
 
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		assert.Equal(t, "/auth/sessions/current/refresh", r.URL.Path)
+		assert.Equal(t, testRefreshToken, r.Header.Get("X-Stack-Refresh-Token"))
 		assert.Equal(t, "POST", r.Method)
 
 		w.Header().Set("Content-Type", "application/json")
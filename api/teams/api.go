@@ -1,10 +1,11 @@
 package teams
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
-	"github.com/BlaisePopov/stack-auth/base-http-client/utils"
-	"net/url"
+	"github.com/BlaisePopov/stack-auth/api/pagination"
+	"github.com/BlaisePopov/stack-auth/internal/transport"
+	"iter"
 
 	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client/interface"
 )
@@ -22,89 +23,113 @@ func NewClient(httpClient base_http_client.BaseHTTPClient) *Client {
 // ListTeams возвращает список команд проекта [https://docs.stack-auth.com/next/rest-api/server/teams/list-teams]
 //
 // Входные параметры:
-//   - userID: идентификатор пользователя
+//   - options: фильтр по пользователю и пагинация (опционально, nil - параметры по умолчанию)
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект ListTeamsResponse и ошибка
-func (c *Client) ListTeams(userID string) (*ListTeamsResponse, error) {
-	response := &ListTeamsResponse{}
-	queryParams := url.Values{}
-	utils.AddOptionalStringParam(queryParams, "user_id", userID)
+func (c *Client) ListTeams(options *ListTeamsOptions, opts ...base_http_client.RequestOption) (*ListTeamsResponse, error) {
+	return c.ListTeamsCtx(context.Background(), options, opts...)
+}
 
-	rawResponse, err := c.HTTPClient.SendRequest("GET", "/teams", queryParams, nil)
-	if err != nil {
-		return nil, err
-	}
+// ListTeamsCtx выполняет ListTeams с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) ListTeamsCtx(ctx context.Context, options *ListTeamsOptions, opts ...base_http_client.RequestOption) (*ListTeamsResponse, error) {
+	return transport.Do[transport.NoBody, ListTeamsResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "teams.ListTeams",
+		Method:    "GET",
+		Path:      "/teams",
+		Query:     options.values(),
+	}, nil, opts...)
+}
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+// AllTeams возвращает итератор по всем командам, автоматически переходящий по курсорам страниц
+//
+// Входные параметры:
+//   - ctx: контекст, отмена которого завершает перебор
+//   - options: фильтры и размер страницы (опционально). Cursor задает начальную страницу
+//   - opts: параметры запросов страниц (опционально)
+//
+// Возвращаемое значение: итератор пар (команда, ошибка)
+func (c *Client) AllTeams(ctx context.Context, options *ListTeamsOptions, opts ...base_http_client.RequestOption) iter.Seq2[TeamResponse, error] {
+	return pagination.Seq(ctx, func(ctx context.Context, cursor string) ([]TeamResponse, string, error) {
+		page := ListTeamsOptions{}
+		if options != nil {
+			page = *options
+		}
+		if cursor != "" {
+			page.Cursor = cursor
+		}
+		response, err := c.ListTeamsCtx(ctx, &page, opts...)
+		if err != nil {
+			return nil, "", err
+		}
+		return response.Items, response.Pagination.NextCursor, nil
+	})
 }
 
 // CreateTeam создает новую команду [https://docs.stack-auth.com/next/rest-api/server/teams/create-a-team]
 //
 // Входные параметры:
 //   - request: данные для создания команды
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект TeamResponse и ошибка
-func (c *Client) CreateTeam(request *CreateTeamRequest) (*TeamResponse, error) {
-	response := &TeamResponse{}
-	body, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-	}
-
-	rawResponse, err := c.HTTPClient.SendRequest("POST", "/teams", nil, body)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) CreateTeam(request *CreateTeamRequest, opts ...base_http_client.RequestOption) (*TeamResponse, error) {
+	return c.CreateTeamCtx(context.Background(), request, opts...)
+}
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+// CreateTeamCtx выполняет CreateTeam с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) CreateTeamCtx(ctx context.Context, request *CreateTeamRequest, opts ...base_http_client.RequestOption) (*TeamResponse, error) {
+	return transport.Do[CreateTeamRequest, TeamResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "teams.CreateTeam",
+		Method:    "POST",
+		Path:      "/teams",
+	}, request, opts...)
 }
 
 // GetTeam возвращает информацию о команде по ID [https://docs.stack-auth.com/next/rest-api/server/teams/get-a-team]
 //
 // Входные параметры:
 //   - teamID: идентификатор команды
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект TeamResponse и ошибка
-func (c *Client) GetTeam(teamID string) (*TeamResponse, error) {
-	response := &TeamResponse{}
-	path := fmt.Sprintf("/teams/%s", teamID)
+func (c *Client) GetTeam(teamID string, opts ...base_http_client.RequestOption) (*TeamResponse, error) {
+	return c.GetTeamCtx(context.Background(), teamID, opts...)
+}
 
-	rawResponse, err := c.HTTPClient.SendRequest("GET", path, nil, nil)
-	if err != nil {
-		return nil, err
-	}
+// GetTeamCtx выполняет GetTeam с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) GetTeamCtx(ctx context.Context, teamID string, opts ...base_http_client.RequestOption) (*TeamResponse, error) {
+	path := fmt.Sprintf("/teams/%s", teamID)
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+	return transport.Do[transport.NoBody, TeamResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation:    "teams.GetTeam",
+		Method:       "GET",
+		PathTemplate: "/teams/{team_id}",
+		Path:         path,
+	}, nil, opts...)
 }
 
 // DeleteTeam удаляет команду по ID [https://docs.stack-auth.com/next/rest-api/server/teams/delete-a-team]
 //
 // Входные параметры:
 //   - teamID: идентификатор команды
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект SuccessResponse и ошибка
-func (c *Client) DeleteTeam(teamID string) (*SuccessResponse, error) {
-	response := &SuccessResponse{}
-	path := fmt.Sprintf("/teams/%s", teamID)
+func (c *Client) DeleteTeam(teamID string, opts ...base_http_client.RequestOption) (*SuccessResponse, error) {
+	return c.DeleteTeamCtx(context.Background(), teamID, opts...)
+}
 
-	rawResponse, err := c.HTTPClient.SendRequest("DELETE", path, nil, nil)
-	if err != nil {
-		return nil, err
-	}
+// DeleteTeamCtx выполняет DeleteTeam с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) DeleteTeamCtx(ctx context.Context, teamID string, opts ...base_http_client.RequestOption) (*SuccessResponse, error) {
+	path := fmt.Sprintf("/teams/%s", teamID)
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+	return transport.Do[transport.NoBody, SuccessResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation:    "teams.DeleteTeam",
+		Method:       "DELETE",
+		PathTemplate: "/teams/{team_id}",
+		Path:         path,
+	}, nil, opts...)
 }
 
 // UpdateTeam обновляет информацию о команде [https://docs.stack-auth.com/next/rest-api/server/teams/update-a-team]
@@ -112,92 +137,109 @@ func (c *Client) DeleteTeam(teamID string) (*SuccessResponse, error) {
 // Входные параметры:
 //   - teamID: идентификатор команды
 //   - request: данные для обновления
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект TeamResponse и ошибка
-func (c *Client) UpdateTeam(teamID string, request *UpdateTeamRequest) (*TeamResponse, error) {
-	response := &TeamResponse{}
-	path := fmt.Sprintf("/teams/%s", teamID)
-
-	body, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-	}
+func (c *Client) UpdateTeam(teamID string, request *UpdateTeamRequest, opts ...base_http_client.RequestOption) (*TeamResponse, error) {
+	return c.UpdateTeamCtx(context.Background(), teamID, request, opts...)
+}
 
-	rawResponse, err := c.HTTPClient.SendRequest("PATCH", path, nil, body)
-	if err != nil {
-		return nil, err
-	}
+// UpdateTeamCtx выполняет UpdateTeam с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) UpdateTeamCtx(ctx context.Context, teamID string, request *UpdateTeamRequest, opts ...base_http_client.RequestOption) (*TeamResponse, error) {
+	path := fmt.Sprintf("/teams/%s", teamID)
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+	return transport.Do[UpdateTeamRequest, TeamResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation:    "teams.UpdateTeam",
+		Method:       "PATCH",
+		PathTemplate: "/teams/{team_id}",
+		Path:         path,
+	}, request, opts...)
 }
 
 // ListTeamMembersProfiles возвращает профили участников команды [https://docs.stack-auth.com/next/rest-api/server/teams/list-team-members-profiles]
 //
 // Входные параметры:
-//   - teamID: идентификатор команды (опционально)
-//   - userID: идентификатор пользователя (опционально)
+//   - options: фильтры по команде и пользователю, пагинация (опционально, nil - параметры по умолчанию)
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект ListTeamMembersResponse и ошибка
-func (c *Client) ListTeamMembersProfiles(teamID, userID string) (*ListTeamMembersResponse, error) {
-	response := &ListTeamMembersResponse{}
-	queryParams := url.Values{}
-
-	utils.AddOptionalStringParam(queryParams, "team_id", teamID)
-	utils.AddOptionalStringParam(queryParams, "user_id", userID)
+func (c *Client) ListTeamMembersProfiles(options *ListTeamMembersProfilesOptions, opts ...base_http_client.RequestOption) (*ListTeamMembersResponse, error) {
+	return c.ListTeamMembersProfilesCtx(context.Background(), options, opts...)
+}
 
-	rawResponse, err := c.HTTPClient.SendRequest("GET", "/team-member-profiles", queryParams, nil)
-	if err != nil {
-		return nil, err
-	}
+// ListTeamMembersProfilesCtx выполняет ListTeamMembersProfiles с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) ListTeamMembersProfilesCtx(ctx context.Context, options *ListTeamMembersProfilesOptions, opts ...base_http_client.RequestOption) (*ListTeamMembersResponse, error) {
+	return transport.Do[transport.NoBody, ListTeamMembersResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "teams.ListTeamMembersProfiles",
+		Method:    "GET",
+		Path:      "/team-member-profiles",
+		Query:     options.values(),
+	}, nil, opts...)
+}
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+// AllTeamMembersProfiles возвращает итератор по всем профилям участников команды, автоматически переходящий по курсорам страниц
+//
+// Входные параметры:
+//   - ctx: контекст, отмена которого завершает перебор
+//   - options: фильтры и размер страницы (опционально). Cursor задает начальную страницу
+//   - opts: параметры запросов страниц (опционально)
+//
+// Возвращаемое значение: итератор пар (профиль участника, ошибка)
+func (c *Client) AllTeamMembersProfiles(ctx context.Context, options *ListTeamMembersProfilesOptions, opts ...base_http_client.RequestOption) iter.Seq2[TeamMemberProfileResponse, error] {
+	return pagination.Seq(ctx, func(ctx context.Context, cursor string) ([]TeamMemberProfileResponse, string, error) {
+		page := ListTeamMembersProfilesOptions{}
+		if options != nil {
+			page = *options
+		}
+		if cursor != "" {
+			page.Cursor = cursor
+		}
+		response, err := c.ListTeamMembersProfilesCtx(ctx, &page, opts...)
+		if err != nil {
+			return nil, "", err
+		}
+		return response.Items, response.Pagination.NextCursor, nil
+	})
 }
 
 // SendInviteEmail отправляет приглашение в команду по email [https://docs.stack-auth.com/next/rest-api/server/teams/send-an-email-to-invite-a-user-to-a-team]
 //
 // Входные параметры:
 //   - request: данные для отправки приглашения
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект SuccessResponse и ошибка
-func (c *Client) SendInviteEmail(request *SendInviteEmailRequest) (*SuccessResponse, error) {
-	response := &SuccessResponse{}
-
-	body, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-	}
-
-	rawResponse, err := c.HTTPClient.SendRequest("POST", "/team-invitations/send-code", nil, body)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) SendInviteEmail(request *SendInviteEmailRequest, opts ...base_http_client.RequestOption) (*SuccessResponse, error) {
+	return c.SendInviteEmailCtx(context.Background(), request, opts...)
+}
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+// SendInviteEmailCtx выполняет SendInviteEmail с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) SendInviteEmailCtx(ctx context.Context, request *SendInviteEmailRequest, opts ...base_http_client.RequestOption) (*SuccessResponse, error) {
+	return transport.Do[SendInviteEmailRequest, SuccessResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "teams.SendInviteEmail",
+		Method:    "POST",
+		Path:      "/team-invitations/send-code",
+	}, request, opts...)
 }
 
 // AcceptInvite принимает приглашение в команду [https://docs.stack-auth.com/next/rest-api/server/teams/invite-a-user-to-a-team]
 //
 // Входные параметры:
 //   - request: данные приглашения
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: ошибка выполнения операции
-func (c *Client) AcceptInvite(request *AcceptInviteRequest) error {
-	body, err := json.Marshal(request)
-	if err != nil {
-		return fmt.Errorf("ошибка кодирования запроса: %w", err)
-	}
+func (c *Client) AcceptInvite(request *AcceptInviteRequest, opts ...base_http_client.RequestOption) error {
+	return c.AcceptInviteCtx(context.Background(), request, opts...)
+}
 
-	_, err = c.HTTPClient.SendRequest("POST", "/team-invitations/accept", nil, body)
-	return err
+// AcceptInviteCtx выполняет AcceptInvite с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) AcceptInviteCtx(ctx context.Context, request *AcceptInviteRequest, opts ...base_http_client.RequestOption) error {
+	return transport.Send[AcceptInviteRequest](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "teams.AcceptInvite",
+		Method:    "POST",
+		Path:      "/team-invitations/accept",
+	}, request, opts...)
 }
 
 // AddTeamMember добавляет пользователя в команду [https://docs.stack-auth.com/next/rest-api/server/teams/add-a-user-to-a-team]
@@ -205,21 +247,23 @@ func (c *Client) AcceptInvite(request *AcceptInviteRequest) error {
 // Входные параметры:
 //   - teamID: идентификатор команды
 //   - userID: идентификатор пользователя
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект TeamMembershipResponse и ошибка
-func (c *Client) AddTeamMember(teamID, userID string) (*TeamMembershipResponse, error) {
-	response := &TeamMembershipResponse{}
-	path := fmt.Sprintf("/team-memberships/%s/%s", teamID, userID)
+func (c *Client) AddTeamMember(teamID, userID string, opts ...base_http_client.RequestOption) (*TeamMembershipResponse, error) {
+	return c.AddTeamMemberCtx(context.Background(), teamID, userID, opts...)
+}
 
-	rawResponse, err := c.HTTPClient.SendRequest("POST", path, nil, nil)
-	if err != nil {
-		return nil, err
-	}
+// AddTeamMemberCtx выполняет AddTeamMember с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) AddTeamMemberCtx(ctx context.Context, teamID, userID string, opts ...base_http_client.RequestOption) (*TeamMembershipResponse, error) {
+	path := fmt.Sprintf("/team-memberships/%s/%s", teamID, userID)
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+	return transport.Do[transport.NoBody, TeamMembershipResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation:    "teams.AddTeamMember",
+		Method:       "POST",
+		PathTemplate: "/team-memberships/{team_id}/{user_id}",
+		Path:         path,
+	}, nil, opts...)
 }
 
 // RemoveTeamMember удаляет пользователя из команды [https://docs.stack-auth.com/next/rest-api/server/teams/remove-a-user-from-a-team]
@@ -227,21 +271,23 @@ func (c *Client) AddTeamMember(teamID, userID string) (*TeamMembershipResponse,
 // Входные параметры:
 //   - teamID: идентификатор команды
 //   - userID: идентификатор пользователя
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект SuccessResponse и ошибка
-func (c *Client) RemoveTeamMember(teamID, userID string) (*SuccessResponse, error) {
-	response := &SuccessResponse{}
-	path := fmt.Sprintf("/team-memberships/%s/%s", teamID, userID)
+func (c *Client) RemoveTeamMember(teamID, userID string, opts ...base_http_client.RequestOption) (*SuccessResponse, error) {
+	return c.RemoveTeamMemberCtx(context.Background(), teamID, userID, opts...)
+}
 
-	rawResponse, err := c.HTTPClient.SendRequest("DELETE", path, nil, nil)
-	if err != nil {
-		return nil, err
-	}
+// RemoveTeamMemberCtx выполняет RemoveTeamMember с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) RemoveTeamMemberCtx(ctx context.Context, teamID, userID string, opts ...base_http_client.RequestOption) (*SuccessResponse, error) {
+	path := fmt.Sprintf("/team-memberships/%s/%s", teamID, userID)
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+	return transport.Do[transport.NoBody, SuccessResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation:    "teams.RemoveTeamMember",
+		Method:       "DELETE",
+		PathTemplate: "/team-memberships/{team_id}/{user_id}",
+		Path:         path,
+	}, nil, opts...)
 }
 
 // GetTeamMemberProfile возвращает профиль участника команды [https://docs.stack-auth.com/next/rest-api/server/teams/get-a-team-member-profile]
@@ -249,21 +295,23 @@ func (c *Client) RemoveTeamMember(teamID, userID string) (*SuccessResponse, erro
 // Входные параметры:
 //   - teamID: идентификатор команды
 //   - userID: идентификатор пользователя
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект TeamMemberProfileResponse и ошибка
-func (c *Client) GetTeamMemberProfile(teamID, userID string) (*TeamMemberProfileResponse, error) {
-	response := &TeamMemberProfileResponse{}
-	path := fmt.Sprintf("/team-member-profiles/%s/%s", teamID, userID)
+func (c *Client) GetTeamMemberProfile(teamID, userID string, opts ...base_http_client.RequestOption) (*TeamMemberProfileResponse, error) {
+	return c.GetTeamMemberProfileCtx(context.Background(), teamID, userID, opts...)
+}
 
-	rawResponse, err := c.HTTPClient.SendRequest("GET", path, nil, nil)
-	if err != nil {
-		return nil, err
-	}
+// GetTeamMemberProfileCtx выполняет GetTeamMemberProfile с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) GetTeamMemberProfileCtx(ctx context.Context, teamID, userID string, opts ...base_http_client.RequestOption) (*TeamMemberProfileResponse, error) {
+	path := fmt.Sprintf("/team-member-profiles/%s/%s", teamID, userID)
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+	return transport.Do[transport.NoBody, TeamMemberProfileResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation:    "teams.GetTeamMemberProfile",
+		Method:       "GET",
+		PathTemplate: "/team-member-profiles/{team_id}/{user_id}",
+		Path:         path,
+	}, nil, opts...)
 }
 
 // UpdateTeamMemberProfile обновляет профиль участника команды [https://docs.stack-auth.com/next/rest-api/server/teams/update-a-team-member-profile]
@@ -272,76 +320,65 @@ func (c *Client) GetTeamMemberProfile(teamID, userID string) (*TeamMemberProfile
 //   - teamID: идентификатор команды
 //   - userID: идентификатор пользователя
 //   - request: данные для обновления
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект TeamMemberProfileResponse и ошибка
-func (c *Client) UpdateTeamMemberProfile(teamID, userID string, request *UpdateTeamMemberProfileRequest) (*TeamMemberProfileResponse, error) {
-	response := &TeamMemberProfileResponse{}
-	path := fmt.Sprintf("/team-member-profiles/%s/%s", teamID, userID)
-
-	body, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-	}
+func (c *Client) UpdateTeamMemberProfile(teamID, userID string, request *UpdateTeamMemberProfileRequest, opts ...base_http_client.RequestOption) (*TeamMemberProfileResponse, error) {
+	return c.UpdateTeamMemberProfileCtx(context.Background(), teamID, userID, request, opts...)
+}
 
-	rawResponse, err := c.HTTPClient.SendRequest("PATCH", path, nil, body)
-	if err != nil {
-		return nil, err
-	}
+// UpdateTeamMemberProfileCtx выполняет UpdateTeamMemberProfile с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) UpdateTeamMemberProfileCtx(ctx context.Context, teamID, userID string, request *UpdateTeamMemberProfileRequest, opts ...base_http_client.RequestOption) (*TeamMemberProfileResponse, error) {
+	path := fmt.Sprintf("/team-member-profiles/%s/%s", teamID, userID)
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+	return transport.Do[UpdateTeamMemberProfileRequest, TeamMemberProfileResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation:    "teams.UpdateTeamMemberProfile",
+		Method:       "PATCH",
+		PathTemplate: "/team-member-profiles/{team_id}/{user_id}",
+		Path:         path,
+	}, request, opts...)
 }
 
 // GetInvitationDetails возвращает информацию о приглашении [https://docs.stack-auth.com/next/rest-api/server/teams/get-team-invitation-details]
 //
 // Входные параметры:
 //   - code: код приглашения
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект InvitationDetailsResponse и ошибка
-func (c *Client) GetInvitationDetails(code string) (*InvitationDetailsResponse, error) {
-	response := &InvitationDetailsResponse{}
-	request := AcceptInviteRequest{Code: code}
-
-	body, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-	}
+func (c *Client) GetInvitationDetails(code string, opts ...base_http_client.RequestOption) (*InvitationDetailsResponse, error) {
+	return c.GetInvitationDetailsCtx(context.Background(), code, opts...)
+}
 
-	rawResponse, err := c.HTTPClient.SendRequest("POST", "/team-invitations/accept/details", nil, body)
-	if err != nil {
-		return nil, err
-	}
+// GetInvitationDetailsCtx выполняет GetInvitationDetails с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) GetInvitationDetailsCtx(ctx context.Context, code string, opts ...base_http_client.RequestOption) (*InvitationDetailsResponse, error) {
+	request := &AcceptInviteRequest{Code: code}
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+	return transport.Do[AcceptInviteRequest, InvitationDetailsResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "teams.GetInvitationDetails",
+		Method:    "POST",
+		Path:      "/team-invitations/accept/details",
+	}, request, opts...)
 }
 
 // CheckInviteCode проверяет валидность кода приглашения [https://docs.stack-auth.com/next/rest-api/server/teams/check-if-a-team-invitation-code-is-valid]
 //
 // Входные параметры:
 //   - code: код приглашения
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект CheckCodeResponse и ошибка
-func (c *Client) CheckInviteCode(code string) (*CheckCodeResponse, error) {
-	response := &CheckCodeResponse{}
-	request := AcceptInviteRequest{Code: code}
-
-	body, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-	}
-
-	rawResponse, err := c.HTTPClient.SendRequest("POST", "/team-invitations/accept/check-code", nil, body)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+func (c *Client) CheckInviteCode(code string, opts ...base_http_client.RequestOption) (*CheckCodeResponse, error) {
+	return c.CheckInviteCodeCtx(context.Background(), code, opts...)
+}
+
+// CheckInviteCodeCtx выполняет CheckInviteCode с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) CheckInviteCodeCtx(ctx context.Context, code string, opts ...base_http_client.RequestOption) (*CheckCodeResponse, error) {
+	request := &AcceptInviteRequest{Code: code}
+
+	return transport.Do[AcceptInviteRequest, CheckCodeResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "teams.CheckInviteCode",
+		Method:    "POST",
+		Path:      "/team-invitations/accept/check-code",
+	}, request, opts...)
 }
@@ -1,10 +1,13 @@
 package teams
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
 	"github.com/stretchr/testify/assert"
@@ -43,11 +46,39 @@ func TestListTeams(t *testing.T) {
 	defer server.Close()
 
 	client := setupTestClient(server.URL)
-	response, err := client.ListTeams("123")
+	response, err := client.ListTeams(&ListTeamsOptions{UserID: "123"})
 	assert.NoError(t, err)
 	assert.Equal(t, expectedResponse.Items[0].ID, response.Items[0].ID)
 }
 
+func TestAllTeams(t *testing.T) {
+	pages := map[string]ListTeamsResponse{
+		"":       {Items: []TeamResponse{{ID: "team-1"}, {ID: "team-2"}}, Pagination: Pagination{NextCursor: "team-3"}},
+		"team-3": {Items: []TeamResponse{{ID: "team-3"}}},
+	}
+
+	var cursors []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/teams", r.URL.Path)
+		assert.Equal(t, "123", r.URL.Query().Get("user_id"))
+		cursor := r.URL.Query().Get("cursor")
+		cursors = append(cursors, cursor)
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(pages[cursor])
+	}))
+	defer server.Close()
+
+	client := setupTestClient(server.URL)
+	var ids []string
+	for team, err := range client.AllTeams(context.Background(), &ListTeamsOptions{UserID: "123"}) {
+		assert.NoError(t, err)
+		ids = append(ids, team.ID)
+	}
+	assert.Equal(t, []string{"team-1", "team-2", "team-3"}, ids)
+	assert.Equal(t, []string{"", "team-3"}, cursors)
+}
+
 func TestCreateTeam(t *testing.T) {
 	expectedResponse := &TeamResponse{
 		ID:              "ad962777-8244-496a-b6a2-e0c6a449c79e",
@@ -165,7 +196,7 @@ func TestListTeamMembersProfiles(t *testing.T) {
 	defer server.Close()
 
 	client := setupTestClient(server.URL)
-	response, err := client.ListTeamMembersProfiles("team123", "user456")
+	response, err := client.ListTeamMembersProfiles(&ListTeamMembersProfilesOptions{TeamID: "team123", UserID: "user456"})
 	assert.NoError(t, err)
 	assert.Equal(t, expectedResponse.Items[0].UserID, response.Items[0].UserID)
 }
@@ -338,3 +369,39 @@ func TestCheckInviteCode(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, response.IsCodeValid)
 }
+
+func TestGetTeamCtx_DeadlineExceeded(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	client := setupTestClient(server.URL)
+	response, err := client.GetTeamCtx(ctx, "test-team")
+	assert.Nil(t, response)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+func TestGetTeam_ResponseMeta(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Request-Id", "request-42")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(&TeamResponse{ID: "team-id"})
+	}))
+	defer server.Close()
+
+	var meta base_http_client.ResponseMeta
+	client := setupTestClient(server.URL)
+	response, err := client.GetTeam("team-id", base_http_client.WithResponseMeta(&meta))
+	assert.NoError(t, err)
+	assert.Equal(t, "team-id", response.ID)
+	assert.Equal(t, http.StatusOK, meta.StatusCode)
+	assert.Equal(t, "request-42", meta.RequestID)
+}
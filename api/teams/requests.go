@@ -1,5 +1,11 @@
 package teams
 
+import (
+	"net/url"
+
+	"github.com/BlaisePopov/stack-auth/base-http-client/utils"
+)
+
 // CreateTeamRequest содержит данные для создания команды
 type CreateTeamRequest struct {
 	DisplayName            string                 `json:"display_name"`
@@ -36,3 +42,54 @@ type SendInviteEmailRequest struct {
 type AcceptInviteRequest struct {
 	Code string `json:"code"`
 }
+
+// ListTeamsOptions содержит параметры запроса списка команд.
+// Нулевое значение поля означает, что параметр не передается.
+type ListTeamsOptions struct {
+	// UserID оставляет только команды пользователя. Для типа доступа "client" используется "me"
+	UserID string
+	// Cursor - курсор страницы из Pagination.NextCursor предыдущего ответа
+	Cursor string
+	// Limit ограничивает количество команд на странице
+	Limit int
+}
+
+// values возвращает параметры строки запроса
+func (o *ListTeamsOptions) values() url.Values {
+	queryParams := url.Values{}
+	if o == nil {
+		return queryParams
+	}
+
+	utils.AddOptionalStringParam(queryParams, "user_id", o.UserID)
+	utils.AddOptionalStringParam(queryParams, "cursor", o.Cursor)
+	utils.AddOptionalIntParam(queryParams, "limit", o.Limit)
+	return queryParams
+}
+
+// ListTeamMembersProfilesOptions содержит параметры запроса профилей участников команды.
+// Нулевое значение поля означает, что параметр не передается.
+type ListTeamMembersProfilesOptions struct {
+	// TeamID оставляет только профили участников команды
+	TeamID string
+	// UserID оставляет только профили пользователя
+	UserID string
+	// Cursor - курсор страницы из Pagination.NextCursor предыдущего ответа
+	Cursor string
+	// Limit ограничивает количество профилей на странице
+	Limit int
+}
+
+// values возвращает параметры строки запроса
+func (o *ListTeamMembersProfilesOptions) values() url.Values {
+	queryParams := url.Values{}
+	if o == nil {
+		return queryParams
+	}
+
+	utils.AddOptionalStringParam(queryParams, "team_id", o.TeamID)
+	utils.AddOptionalStringParam(queryParams, "user_id", o.UserID)
+	utils.AddOptionalStringParam(queryParams, "cursor", o.Cursor)
+	utils.AddOptionalIntParam(queryParams, "limit", o.Limit)
+	return queryParams
+}
@@ -1,12 +1,12 @@
 package users
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"github.com/BlaisePopov/stack-auth/api/pagination"
 	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client/interface"
-	"github.com/BlaisePopov/stack-auth/base-http-client/utils"
-	"net/url"
-	"strconv"
+	"github.com/BlaisePopov/stack-auth/internal/transport"
+	"iter"
 )
 
 // Client представляет клиент для работы с пользователями
@@ -22,142 +22,171 @@ func NewClient(httpClient base_http_client.BaseHTTPClient) *Client {
 // ListUsers возвращает список пользователей проекта. [https://docs.stack-auth.com/next/rest-api/server/users/list-users]
 //
 // Входные параметры:
-//   - teamID: идентификатор команды (опционально)
-//   - cursor: курсор для пагинации (опционально)
-//   - orderBy: поле для сортировки (опционально)
-//   - query: поисковый запрос (опционально)
-//   - desc: обратный порядок сортировки (опционально)
-//   - limit: ограничение количества результатов (опционально)
+//   - options: фильтры, сортировка и пагинация (опционально, nil - параметры по умолчанию)
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект ListUsersResponse и ошибка, если она возникла
-func (c *Client) ListUsers(teamID, cursor, orderBy, query string, desc bool, limit int) (*ListUsersResponse, error) {
-	response := &ListUsersResponse{}
-	queryParams := url.Values{}
-
-	utils.AddOptionalStringParam(queryParams, "team_id", teamID)
-	utils.AddOptionalIntParam(queryParams, "limit", limit)
-	utils.AddOptionalStringParam(queryParams, "cursor", cursor)
-	utils.AddOptionalStringParam(queryParams, "order_by", orderBy)
-	utils.AddOptionalStringParam(queryParams, "query", query)
-	queryParams.Add("desc", strconv.FormatBool(desc))
+func (c *Client) ListUsers(options *ListUsersOptions, opts ...base_http_client.RequestOption) (*ListUsersResponse, error) {
+	return c.ListUsersCtx(context.Background(), options, opts...)
+}
 
-	rawResponse, err := c.HTTPClient.SendRequest("GET", "/users", queryParams, nil)
-	if err != nil {
-		return nil, err
-	}
+// ListUsersCtx выполняет ListUsers с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) ListUsersCtx(ctx context.Context, options *ListUsersOptions, opts ...base_http_client.RequestOption) (*ListUsersResponse, error) {
+	return transport.Do[transport.NoBody, ListUsersResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "users.ListUsers",
+		Method:    "GET",
+		Path:      "/users",
+		Query:     options.values(),
+	}, nil, opts...)
+}
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+// AllUsers возвращает итератор по всем пользователям проекта, автоматически переходящий по курсорам страниц.
+//
+// Входные параметры:
+//   - ctx: контекст, отмена которого завершает перебор
+//   - options: фильтры, сортировка и размер страницы (опционально). Cursor задает начальную страницу
+//   - opts: параметры запросов страниц (опционально)
+//
+// Возвращаемое значение: итератор пар (пользователь, ошибка)
+func (c *Client) AllUsers(ctx context.Context, options *ListUsersOptions, opts ...base_http_client.RequestOption) iter.Seq2[User, error] {
+	return pagination.Seq(ctx, func(ctx context.Context, cursor string) ([]User, string, error) {
+		page := ListUsersOptions{}
+		if options != nil {
+			page = *options
+		}
+		if cursor != "" {
+			page.Cursor = cursor
+		}
+		response, err := c.ListUsersCtx(ctx, &page, opts...)
+		if err != nil {
+			return nil, "", err
+		}
+		return response.Items, response.Pagination.NextCursor, nil
+	})
 }
 
 // CreateUser создает нового пользователя. [https://docs.stack-auth.com/next/rest-api/server/users/create-user]
 //
 // Входные параметры:
 //   - request: данные для создания пользователя
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект UserResponse и ошибка, если она возникла
-func (c *Client) CreateUser(request *CreateUserRequest) (*UserResponse, error) {
-	response := &UserResponse{}
-	body, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-	}
-
-	rawResponse, err := c.HTTPClient.SendRequest("POST", "/users", nil, body)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) CreateUser(request *CreateUserRequest, opts ...base_http_client.RequestOption) (*UserResponse, error) {
+	return c.CreateUserCtx(context.Background(), request, opts...)
+}
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+// CreateUserCtx выполняет CreateUser с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) CreateUserCtx(ctx context.Context, request *CreateUserRequest, opts ...base_http_client.RequestOption) (*UserResponse, error) {
+	return transport.Do[CreateUserRequest, UserResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "users.CreateUser",
+		Method:    "POST",
+		Path:      "/users",
+	}, request, opts...)
 }
 
 // GetCurrentUser возвращает данные текущего аутентифицированного пользователя. [https://docs.stack-auth.com/next/rest-api/server/users/get-current-user]
 //
+// Входные параметры:
+//   - opts: параметры отдельного запроса (опционально)
+//
 // Возвращаемое значение: объект UserResponse и ошибка, если она возникла
-func (c *Client) GetCurrentUser() (*UserResponse, error) {
-	response := &UserResponse{}
-	rawResponse, err := c.HTTPClient.SendRequest("GET", "/users/me", nil, nil)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) GetCurrentUser(opts ...base_http_client.RequestOption) (*UserResponse, error) {
+	return c.GetCurrentUserCtx(context.Background(), opts...)
+}
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+// GetCurrentUserCtx выполняет GetCurrentUser с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) GetCurrentUserCtx(ctx context.Context, opts ...base_http_client.RequestOption) (*UserResponse, error) {
+	return transport.Do[transport.NoBody, UserResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "users.GetCurrentUser",
+		Method:    "GET",
+		Path:      "/users/me",
+	}, nil, opts...)
 }
 
 // DeleteCurrentUser удаляет текущего аутентифицированного пользователя. [https://docs.stack-auth.com/next/rest-api/server/users/delete-current-user]
 //
+// Входные параметры:
+//   - opts: параметры отдельного запроса (опционально)
+//
 // Возвращаемое значение: объект SuccessResponse и ошибка, если она возникла
-func (c *Client) DeleteCurrentUser() (*SuccessResponse, error) {
-	response := &SuccessResponse{}
-	rawResponse, err := c.HTTPClient.SendRequest("DELETE", "/users/me", nil, nil)
-	if err != nil {
-		return nil, err
-	}
+func (c *Client) DeleteCurrentUser(opts ...base_http_client.RequestOption) (*SuccessResponse, error) {
+	return c.DeleteCurrentUserCtx(context.Background(), opts...)
+}
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+// DeleteCurrentUserCtx выполняет DeleteCurrentUser с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) DeleteCurrentUserCtx(ctx context.Context, opts ...base_http_client.RequestOption) (*SuccessResponse, error) {
+	return transport.Do[transport.NoBody, SuccessResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "users.DeleteCurrentUser",
+		Method:    "DELETE",
+		Path:      "/users/me",
+	}, nil, opts...)
 }
 
 // UpdateCurrentUser обновляет данные текущего пользователя. [https://docs.stack-auth.com/next/rest-api/server/users/update-current-user]
 //
 // Входные параметры:
 //   - request: данные для обновления
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект UserResponse и ошибка, если она возникла
-func (c *Client) UpdateCurrentUser(request *UpdateUserRequest) (*UserResponse, error) {
-	return c.updateUser("me", request)
+func (c *Client) UpdateCurrentUser(request *UpdateUserRequest, opts ...base_http_client.RequestOption) (*UserResponse, error) {
+	return c.UpdateCurrentUserCtx(context.Background(), request, opts...)
+}
+
+// UpdateCurrentUserCtx выполняет UpdateCurrentUser с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) UpdateCurrentUserCtx(ctx context.Context, request *UpdateUserRequest, opts ...base_http_client.RequestOption) (*UserResponse, error) {
+	return transport.Do[UpdateUserRequest, UserResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation: "users.UpdateCurrentUser",
+		Method:    "PATCH",
+		Path:      "/users/me",
+	}, request, opts...)
 }
 
 // GetUser возвращает пользователя по ID. [https://docs.stack-auth.com/next/rest-api/server/users/get-user]
 //
 // Входные параметры:
 //   - userID: идентификатор пользователя
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект UserResponse и ошибка, если она возникла
-func (c *Client) GetUser(userID string) (*UserResponse, error) {
-	response := &UserResponse{}
-	path := fmt.Sprintf("/users/%s", userID)
+func (c *Client) GetUser(userID string, opts ...base_http_client.RequestOption) (*UserResponse, error) {
+	return c.GetUserCtx(context.Background(), userID, opts...)
+}
 
-	rawResponse, err := c.HTTPClient.SendRequest("GET", path, nil, nil)
-	if err != nil {
-		return nil, err
-	}
+// GetUserCtx выполняет GetUser с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) GetUserCtx(ctx context.Context, userID string, opts ...base_http_client.RequestOption) (*UserResponse, error) {
+	path := fmt.Sprintf("/users/%s", userID)
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+	return transport.Do[transport.NoBody, UserResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation:    "users.GetUser",
+		Method:       "GET",
+		PathTemplate: "/users/{user_id}",
+		Path:         path,
+	}, nil, opts...)
 }
 
 // DeleteUser удаляет пользователя по ID. [https://docs.stack-auth.com/next/rest-api/server/users/delete-user]
 //
 // Входные параметры:
 //   - userID: идентификатор пользователя
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект SuccessResponse и ошибка, если она возникла
-func (c *Client) DeleteUser(userID string) (*SuccessResponse, error) {
-	response := &SuccessResponse{}
-	path := fmt.Sprintf("/users/%s", userID)
+func (c *Client) DeleteUser(userID string, opts ...base_http_client.RequestOption) (*SuccessResponse, error) {
+	return c.DeleteUserCtx(context.Background(), userID, opts...)
+}
 
-	rawResponse, err := c.HTTPClient.SendRequest("DELETE", path, nil, nil)
-	if err != nil {
-		return nil, err
-	}
+// DeleteUserCtx выполняет DeleteUser с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) DeleteUserCtx(ctx context.Context, userID string, opts ...base_http_client.RequestOption) (*SuccessResponse, error) {
+	path := fmt.Sprintf("/users/%s", userID)
 
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+	return transport.Do[transport.NoBody, SuccessResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation:    "users.DeleteUser",
+		Method:       "DELETE",
+		PathTemplate: "/users/{user_id}",
+		Path:         path,
+	}, nil, opts...)
 }
 
 // UpdateUser обновляет данные пользователя по ID. [https://docs.stack-auth.com/next/rest-api/server/users/update-user]
@@ -165,27 +194,20 @@ func (c *Client) DeleteUser(userID string) (*SuccessResponse, error) {
 // Входные параметры:
 //   - userID: идентификатор пользователя
 //   - request: данные для обновления
+//   - opts: параметры отдельного запроса (опционально)
 //
 // Возвращаемое значение: объект UserResponse и ошибка, если она возникла
-func (c *Client) UpdateUser(userID string, request *UpdateUserRequest) (*UserResponse, error) {
-	return c.updateUser(userID, request)
+func (c *Client) UpdateUser(userID string, request *UpdateUserRequest, opts ...base_http_client.RequestOption) (*UserResponse, error) {
+	return c.UpdateUserCtx(context.Background(), userID, request, opts...)
 }
 
-func (c *Client) updateUser(userID string, request *UpdateUserRequest) (*UserResponse, error) {
+// UpdateUserCtx выполняет UpdateUser с контекстом, позволяющим отменить запрос или ограничить время его выполнения.
+func (c *Client) UpdateUserCtx(ctx context.Context, userID string, request *UpdateUserRequest, opts ...base_http_client.RequestOption) (*UserResponse, error) {
 	path := fmt.Sprintf("/users/%s", userID)
-	response := &UserResponse{}
-	body, err := json.Marshal(request)
-	if err != nil {
-		return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
-	}
-
-	rawResponse, err := c.HTTPClient.SendRequest("PATCH", path, nil, body)
-	if err != nil {
-		return nil, err
-	}
-
-	if err := json.Unmarshal(rawResponse, response); err != nil {
-		return nil, fmt.Errorf("ошибка декодирования ответа: %w", err)
-	}
-	return response, nil
+	return transport.Do[UpdateUserRequest, UserResponse](ctx, c.HTTPClient, &base_http_client.Request{
+		Operation:    "users.UpdateUser",
+		Method:       "PATCH",
+		PathTemplate: "/users/{user_id}",
+		Path:         path,
+	}, request, opts...)
 }
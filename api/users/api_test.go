@@ -1,12 +1,16 @@
 package users
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"github.com/BlaisePopov/stack-auth/api/pagination"
 	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
 	"github.com/stretchr/testify/assert"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func setupTestClient(baseURL string) *Client {
@@ -39,12 +43,89 @@ func TestListUsers(t *testing.T) {
 	defer server.Close()
 
 	client := setupTestClient(server.URL)
-	response, err := client.ListUsers("", "", "", "", false, 0)
+	response, err := client.ListUsers(nil)
 	assert.NoError(t, err)
 	assert.Equal(t, expectedResponse.Items[0].ID, response.Items[0].ID)
 	assert.Equal(t, expectedResponse.Pagination.NextCursor, response.Pagination.NextCursor)
 }
 
+func TestListUsers_Options(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		assert.Equal(t, "team-1", query.Get("team_id"))
+		assert.Equal(t, "cursor-1", query.Get("cursor"))
+		assert.Equal(t, "10", query.Get("limit"))
+		assert.Equal(t, "signed_up_at", query.Get("order_by"))
+		assert.Equal(t, "true", query.Get("desc"))
+		assert.Equal(t, "john", query.Get("query"))
+		assert.Equal(t, "true", query.Get("include_anonymous"))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ListUsersResponse{})
+	}))
+	defer server.Close()
+
+	client := setupTestClient(server.URL)
+	_, err := client.ListUsers(&ListUsersOptions{
+		TeamID:           "team-1",
+		Cursor:           "cursor-1",
+		Limit:            10,
+		OrderBy:          OrderBySignedUpAt,
+		Desc:             true,
+		Query:            "john",
+		IncludeAnonymous: true,
+	})
+	assert.NoError(t, err)
+}
+
+func TestAllUsers(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "2", r.URL.Query().Get("limit"))
+
+		response := ListUsersResponse{Items: []User{{ID: "user-1"}, {ID: "user-2"}}, Pagination: Pagination{NextCursor: "user-3"}}
+		if r.URL.Query().Get("cursor") == "user-3" {
+			response = ListUsersResponse{Items: []User{{ID: "user-3"}}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := setupTestClient(server.URL)
+	users, err := pagination.CollectAll(client.AllUsers(context.Background(), &ListUsersOptions{Limit: 2}), 0)
+	assert.NoError(t, err)
+	assert.Len(t, users, 3)
+	assert.Equal(t, "user-3", users[2].ID)
+	assert.Equal(t, 2, requests)
+}
+
+func TestAllUsers_ContextCanceled(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(ListUsersResponse{Items: []User{{ID: "user"}}, Pagination: Pagination{NextCursor: "next-" + r.URL.Query().Get("cursor")}})
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := setupTestClient(server.URL)
+	var lastErr error
+	for _, err := range client.AllUsers(ctx, nil) {
+		if err != nil {
+			lastErr = err
+			break
+		}
+		cancel()
+	}
+	assert.ErrorIs(t, lastErr, context.Canceled)
+	assert.Equal(t, 1, requests)
+}
+
 func TestCreateUser(t *testing.T) {
 	expectedResponse := &UserResponse{User: User{
 		ID:           "3241a285-8329-4d69-8f3d-316e08cf140c",
@@ -210,3 +291,114 @@ func TestUpdateUser(t *testing.T) {
 	assert.Equal(t, expectedResponse.ID, response.ID)
 	assert.Equal(t, expectedResponse.DisplayName, response.DisplayName)
 }
+
+func TestGetUserCtx_Canceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("запрос не должен быть отправлен после отмены контекста")
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	client := setupTestClient(server.URL)
+	response, err := client.GetUserCtx(ctx, "test-user-id")
+	assert.Nil(t, response)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestGetUser_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":"USER_NOT_FOUND","error":"User not found."}`))
+	}))
+	defer server.Close()
+
+	client := setupTestClient(server.URL)
+	response, err := client.GetUser("unknown-user-id")
+	assert.Nil(t, response)
+	assert.True(t, errors.Is(err, base_http_client.ErrUserNotFound))
+
+	var apiError *base_http_client.APIError
+	assert.True(t, errors.As(err, &apiError))
+	assert.Equal(t, http.StatusNotFound, apiError.StatusCode)
+}
+
+func TestGetUser_MiddlewareReceivesOperation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(&UserResponse{User: User{ID: "test-user-id"}})
+	}))
+	defer server.Close()
+
+	var observed base_http_client.Operation
+	baseClient := base_http_client.NewClient(base_http_client.Config{
+		BaseURL: server.URL,
+		Middlewares: []base_http_client.Middleware{
+			base_http_client.ResponseObserver(func(op base_http_client.Operation, req *http.Request, resp *http.Response, err error) {
+				observed = op
+			}),
+		},
+	})
+
+	_, err := NewClient(baseClient).GetUser("test-user-id")
+	assert.NoError(t, err)
+	assert.Equal(t, "users.GetUser", observed.Name)
+	assert.Equal(t, "/users/{user_id}", observed.PathTemplate)
+}
+
+func TestGetCurrentUser_WithAccessToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "user-access-token", r.Header.Get("X-Stack-Access-Token"))
+		assert.Equal(t, "request-42", r.Header.Get("X-Request-Id"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(&UserResponse{User: User{ID: "test-user-id"}})
+	}))
+	defer server.Close()
+
+	client := setupTestClient(server.URL)
+	response, err := client.GetCurrentUser(
+		base_http_client.WithAccessToken("user-access-token"),
+		base_http_client.WithHeader("X-Request-Id", "request-42"),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "test-user-id", response.ID)
+}
+
+func TestGetUser_CacheInvalidatedByUpdateUser(t *testing.T) {
+	var gets int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			gets++
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(&UserResponse{User: User{ID: "test-user-id"}})
+	}))
+	defer server.Close()
+
+	baseClient := base_http_client.NewClient(base_http_client.Config{
+		BaseURL: server.URL,
+		Cache: &base_http_client.CacheConfig{
+			TTL: map[string]time.Duration{"users.GetUser": time.Minute},
+		},
+	})
+	client := NewClient(baseClient)
+
+	for i := 0; i < 2; i++ {
+		_, err := client.GetUser("test-user-id")
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1, gets)
+
+	_, err := client.UpdateUser("test-user-id", &UpdateUserRequest{DisplayName: "Updated"})
+	assert.NoError(t, err)
+	_, err = client.GetUser("test-user-id")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, gets)
+	assert.Equal(t, base_http_client.CacheStats{Hits: 1, Misses: 2, Invalidations: 1}, baseClient.CacheStats())
+}
@@ -1,5 +1,11 @@
 package users
 
+import (
+	"net/url"
+
+	"github.com/BlaisePopov/stack-auth/base-http-client/utils"
+)
+
 // CreateUserRequest содержит данные для создания пользователя
 type CreateUserRequest struct {
 	DisplayName             string                 `json:"display_name,omitempty"`
@@ -30,3 +36,47 @@ type UpdateUserRequest struct {
 	TOTPSecretBase64        string                 `json:"totp_secret_base64,omitempty"`
 	SelectedTeamID          string                 `json:"selected_team_id,omitempty"`
 }
+
+// OrderBy - поле сортировки списка пользователей
+type OrderBy string
+
+const (
+	// OrderBySignedUpAt сортирует пользователей по дате регистрации
+	OrderBySignedUpAt OrderBy = "signed_up_at"
+)
+
+// ListUsersOptions содержит параметры запроса списка пользователей.
+// Нулевое значение поля означает, что параметр не передается.
+type ListUsersOptions struct {
+	// TeamID оставляет только участников команды
+	TeamID string
+	// Cursor - курсор страницы из Pagination.NextCursor предыдущего ответа
+	Cursor string
+	// Limit ограничивает количество пользователей на странице
+	Limit int
+	// OrderBy - поле сортировки
+	OrderBy OrderBy
+	// Desc включает обратный порядок сортировки
+	Desc bool
+	// Query - строка поиска по идентификатору, имени и email
+	Query string
+	// IncludeAnonymous добавляет в список анонимных пользователей
+	IncludeAnonymous bool
+}
+
+// values возвращает параметры строки запроса
+func (o *ListUsersOptions) values() url.Values {
+	queryParams := url.Values{}
+	if o == nil {
+		return queryParams
+	}
+
+	utils.AddOptionalStringParam(queryParams, "team_id", o.TeamID)
+	utils.AddOptionalIntParam(queryParams, "limit", o.Limit)
+	utils.AddOptionalStringParam(queryParams, "cursor", o.Cursor)
+	utils.AddOptionalStringParam(queryParams, "order_by", string(o.OrderBy))
+	utils.AddOptionalStringParam(queryParams, "query", o.Query)
+	utils.AddOptionalBoolParam(queryParams, "desc", o.Desc)
+	utils.AddOptionalBoolParam(queryParams, "include_anonymous", o.IncludeAnonymous)
+	return queryParams
+}
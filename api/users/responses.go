@@ -31,6 +31,7 @@ type User struct {
     SignedUpAtMillis        int64                  `json:"signed_up_at_millis"`
     SelectedTeamID          string                 `json:"selected_team_id"`
     SelectedTeam            *SelectedTeam          `json:"selected_team,omitempty"`
+    IsAnonymous             bool                   `json:"is_anonymous"`
 }
 
 // SelectedTeam содержит информацию о выбранной команде
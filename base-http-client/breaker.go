@@ -0,0 +1,302 @@
+package base_http_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen возвращается, когда автоматический выключатель группы операций разомкнут
+var ErrCircuitOpen = errors.New("автоматический выключатель разомкнут: запросы к Stack Auth временно отклоняются")
+
+// CircuitOpenError описывает отклоненный запрос. errors.Is(err, ErrCircuitOpen) возвращает true
+type CircuitOpenError struct {
+	// Group - группа операций, выключатель которой разомкнут
+	Group string
+	// RetryAfter - время до перехода выключателя в полуоткрытое состояние
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s (группа %q, повтор через %s)", ErrCircuitOpen, e.Group, e.RetryAfter)
+}
+
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// CircuitState - состояние автоматического выключателя
+type CircuitState int
+
+const (
+	// CircuitClosed - запросы выполняются, ошибки подсчитываются
+	CircuitClosed CircuitState = iota
+	// CircuitOpen - запросы отклоняются с ErrCircuitOpen до истечения Cooldown
+	CircuitOpen
+	// CircuitHalfOpen - выполняется ограниченное число пробных запросов
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// Группы операций по умолчанию
+const (
+	CircuitGroupAuth  = "auth"
+	CircuitGroupRead  = "read"
+	CircuitGroupWrite = "write"
+)
+
+// authPackages - пакеты api/*, операции которых относятся к группе CircuitGroupAuth
+var authPackages = []string{"password.", "otp.", "sessions.", "oauth."}
+
+// DefaultCircuitGroup относит операции входа и сессий к группе "auth",
+// остальные GET-запросы к группе "read", а прочие запросы к группе "write"
+func DefaultCircuitGroup(op Operation, method string) string {
+	for _, prefix := range authPackages {
+		if strings.HasPrefix(op.Name, prefix) {
+			return CircuitGroupAuth
+		}
+	}
+	if method == http.MethodGet {
+		return CircuitGroupRead
+	}
+	return CircuitGroupWrite
+}
+
+// CircuitBreakerSettings - параметры выключателя одной группы операций
+type CircuitBreakerSettings struct {
+	// FailureThreshold - число ошибок подряд, после которого выключатель размыкается. По умолчанию 5
+	FailureThreshold int
+	// Cooldown - время в разомкнутом состоянии до пробных запросов. По умолчанию 30 секунд
+	Cooldown time.Duration
+	// HalfOpenMaxRequests - число одновременных пробных запросов в полуоткрытом состоянии. По умолчанию 1
+	HalfOpenMaxRequests int
+}
+
+// CircuitBreakerConfig описывает автоматический выключатель.
+//
+// Ошибкой считаются сбои соединения, истечение времени ожидания и ответы 5xx.
+// Ответы 4xx и отмена контекста вызывающей стороной выключатель не размыкают.
+// Отмена контекста и ErrRateLimited не говорят о состоянии Stack Auth: пробный запрос с таким результатом
+// не замыкает выключатель, и он остается полуоткрытым.
+type CircuitBreakerConfig struct {
+	// Default - параметры для групп, не указанных в Groups
+	Default CircuitBreakerSettings
+	// Groups - параметры отдельных групп операций
+	Groups map[string]CircuitBreakerSettings
+	// GroupOf определяет группу операции. По умолчанию DefaultCircuitGroup
+	GroupOf func(op Operation, method string) string
+	// OnStateChange вызывается при смене состояния выключателя группы
+	OnStateChange func(group string, from, to CircuitState)
+}
+
+// withDefaults возвращает параметры с заполненными значениями по умолчанию
+func (s CircuitBreakerSettings) withDefaults() CircuitBreakerSettings {
+	if s.FailureThreshold < 1 {
+		s.FailureThreshold = 5
+	}
+	if s.Cooldown <= 0 {
+		s.Cooldown = 30 * time.Second
+	}
+	if s.HalfOpenMaxRequests < 1 {
+		s.HalfOpenMaxRequests = 1
+	}
+	return s
+}
+
+// circuitOutcome - результат операции с точки зрения выключателя
+type circuitOutcome int
+
+const (
+	// circuitSuccess - Stack Auth ответил без ошибки сервера
+	circuitSuccess circuitOutcome = iota
+	// circuitFailure - сбой соединения, истечение времени ожидания или ответ 5xx
+	circuitFailure
+	// circuitNeutral - запрос прерван до получения ответа по причинам, не связанным со Stack Auth
+	circuitNeutral
+)
+
+// circuit - выключатель одной группы операций
+type circuit struct {
+	mu       sync.Mutex
+	settings CircuitBreakerSettings
+	state    CircuitState
+	failures int
+	openedAt time.Time
+	trials   int
+	// generation увеличивается при каждой смене состояния. Результаты запросов,
+	// допущенных в другом поколении, не учитываются
+	generation uint64
+}
+
+// setState переводит выключатель в состояние state и начинает новое поколение
+func (c *circuit) setState(state CircuitState) {
+	c.state = state
+	c.generation++
+	switch state {
+	case CircuitOpen:
+		c.openedAt = time.Now()
+	case CircuitHalfOpen:
+		c.trials = 0
+	case CircuitClosed:
+		c.failures = 0
+	}
+}
+
+// circuitBreaker управляет выключателями групп операций
+type circuitBreaker struct {
+	config   CircuitBreakerConfig
+	mu       sync.Mutex
+	circuits map[string]*circuit
+}
+
+func newCircuitBreaker(cfg *CircuitBreakerConfig) *circuitBreaker {
+	breaker := &circuitBreaker{
+		config:   *cfg,
+		circuits: map[string]*circuit{},
+	}
+	if breaker.config.GroupOf == nil {
+		breaker.config.GroupOf = DefaultCircuitGroup
+	}
+	return breaker
+}
+
+// circuit возвращает выключатель группы, создавая его при первом обращении
+func (b *circuitBreaker) circuit(group string) *circuit {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.circuits[group]
+	if !ok {
+		settings, ok := b.config.Groups[group]
+		if !ok {
+			settings = b.config.Default
+		}
+		c = &circuit{settings: settings.withDefaults()}
+		b.circuits[group] = c
+	}
+	return c
+}
+
+// state возвращает текущее состояние выключателя группы
+func (b *circuitBreaker) state(group string) CircuitState {
+	if b == nil {
+		return CircuitClosed
+	}
+	c := b.circuit(group)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state == CircuitOpen && time.Since(c.openedAt) >= c.settings.Cooldown {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+// allow проверяет, можно ли выполнить операцию. Возвращенная функция должна быть вызвана
+// с результатом операции.
+func (b *circuitBreaker) allow(op Operation, method string) (func(resp *http.Response, err error), error) {
+	if b == nil {
+		return func(*http.Response, error) {}, nil
+	}
+
+	group := b.config.GroupOf(op, method)
+	c := b.circuit(group)
+
+	c.mu.Lock()
+	from := c.state
+	if c.state == CircuitOpen {
+		if elapsed := time.Since(c.openedAt); elapsed < c.settings.Cooldown {
+			c.mu.Unlock()
+			return nil, &CircuitOpenError{Group: group, RetryAfter: c.settings.Cooldown - elapsed}
+		}
+		c.setState(CircuitHalfOpen)
+	}
+	if c.state == CircuitHalfOpen {
+		if c.trials >= c.settings.HalfOpenMaxRequests {
+			c.mu.Unlock()
+			return nil, &CircuitOpenError{Group: group}
+		}
+		c.trials++
+	}
+	to, generation := c.state, c.generation
+	c.mu.Unlock()
+	b.notify(group, from, to)
+
+	return func(resp *http.Response, err error) {
+		b.record(group, c, generation, circuitOutcomeOf(resp, err))
+	}, nil
+}
+
+// record учитывает результат операции, допущенной в поколении generation, и переключает состояние выключателя.
+// Результат операции из прежнего поколения, например медленного запроса, начатого до размыкания, не учитывается
+func (b *circuitBreaker) record(group string, c *circuit, generation uint64, outcome circuitOutcome) {
+	c.mu.Lock()
+	if generation != c.generation {
+		c.mu.Unlock()
+		return
+	}
+	from := c.state
+	switch c.state {
+	case CircuitClosed:
+		if outcome == circuitNeutral {
+			break
+		}
+		if outcome == circuitSuccess {
+			c.failures = 0
+			break
+		}
+		c.failures++
+		if c.failures >= c.settings.FailureThreshold {
+			c.setState(CircuitOpen)
+		}
+	case CircuitHalfOpen:
+		c.trials--
+		switch outcome {
+		case circuitFailure:
+			c.setState(CircuitOpen)
+		case circuitSuccess:
+			c.setState(CircuitClosed)
+		}
+	}
+	to := c.state
+	c.mu.Unlock()
+	b.notify(group, from, to)
+}
+
+// notify вызывает OnStateChange при смене состояния
+func (b *circuitBreaker) notify(group string, from, to CircuitState) {
+	if from != to && b.config.OnStateChange != nil {
+		b.config.OnStateChange(group, from, to)
+	}
+}
+
+// circuitOutcomeOf определяет, свидетельствует ли результат о неработоспособности Stack Auth
+func circuitOutcomeOf(resp *http.Response, err error) circuitOutcome {
+	if resp != nil {
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return circuitFailure
+		}
+		return circuitSuccess
+	}
+	if err == nil {
+		return circuitSuccess
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, ErrRateLimited) {
+		return circuitNeutral
+	}
+	return circuitFailure
+}
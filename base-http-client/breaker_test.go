@@ -0,0 +1,182 @@
+package base_http_client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stateChange struct {
+	group    string
+	from, to CircuitState
+}
+
+func TestCircuitBreaker_OpensAndRecovers(t *testing.T) {
+	var healthy atomic.Bool
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if healthy.Load() {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	var mu sync.Mutex
+	var changes []stateChange
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		CircuitBreaker: &CircuitBreakerConfig{
+			Default: CircuitBreakerSettings{FailureThreshold: 2, Cooldown: 20 * time.Millisecond},
+			OnStateChange: func(group string, from, to CircuitState) {
+				mu.Lock()
+				defer mu.Unlock()
+				changes = append(changes, stateChange{group, from, to})
+			},
+		},
+	})
+	getUser := &Request{Operation: "users.GetUser", Method: "GET", Path: "/users/user-1"}
+
+	for i := 0; i < 2; i++ {
+		_, err := client.Do(context.Background(), getUser)
+		var apiError *APIError
+		assert.True(t, errors.As(err, &apiError))
+	}
+	assert.Equal(t, CircuitOpen, client.CircuitState(CircuitGroupRead))
+
+	_, err := client.Do(context.Background(), getUser)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+	var openError *CircuitOpenError
+	assert.True(t, errors.As(err, &openError))
+	assert.Equal(t, CircuitGroupRead, openError.Group)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+
+	_, err = client.Do(context.Background(), &Request{Operation: "users.UpdateUser", Method: "PATCH", Path: "/users/user-1"})
+	assert.False(t, errors.Is(err, ErrCircuitOpen))
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, CircuitHalfOpen, client.CircuitState(CircuitGroupRead))
+	healthy.Store(true)
+	_, err = client.Do(context.Background(), getUser)
+	assert.NoError(t, err)
+	assert.Equal(t, CircuitClosed, client.CircuitState(CircuitGroupRead))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []stateChange{
+		{CircuitGroupRead, CircuitClosed, CircuitOpen},
+		{CircuitGroupRead, CircuitOpen, CircuitHalfOpen},
+		{CircuitGroupRead, CircuitHalfOpen, CircuitClosed},
+	}, changes)
+}
+
+func TestCircuitBreaker_ClientErrorsDoNotOpen(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL:        server.URL,
+		CircuitBreaker: &CircuitBreakerConfig{Default: CircuitBreakerSettings{FailureThreshold: 1}},
+	})
+	for i := 0; i < 3; i++ {
+		_, err := client.SendRequest("GET", "/users/unknown", nil, nil)
+		assert.False(t, errors.Is(err, ErrCircuitOpen))
+	}
+	assert.Equal(t, CircuitClosed, client.CircuitState(CircuitGroupRead))
+}
+
+func TestCircuitBreaker_HalfOpenFailureReopens(t *testing.T) {
+	breaker := newCircuitBreaker(&CircuitBreakerConfig{
+		Groups: map[string]CircuitBreakerSettings{
+			CircuitGroupAuth: {FailureThreshold: 1, Cooldown: time.Millisecond},
+		},
+	})
+	signIn := Operation{Name: "password.SignInWithEmail"}
+
+	done, err := breaker.allow(signIn, "POST")
+	assert.NoError(t, err)
+	done(nil, errors.New("connection refused"))
+	assert.Equal(t, CircuitOpen, breaker.state(CircuitGroupAuth))
+
+	time.Sleep(2 * time.Millisecond)
+	done, err = breaker.allow(signIn, "POST")
+	assert.NoError(t, err)
+	_, err = breaker.allow(signIn, "POST")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	done(nil, context.DeadlineExceeded)
+	assert.Equal(t, CircuitOpen, breaker.state(CircuitGroupAuth))
+}
+
+func TestCircuitBreaker_HalfOpenNeutralOutcomeKeepsHalfOpen(t *testing.T) {
+	breaker := newCircuitBreaker(&CircuitBreakerConfig{
+		Groups: map[string]CircuitBreakerSettings{
+			CircuitGroupAuth: {FailureThreshold: 1, Cooldown: time.Millisecond},
+		},
+	})
+	signIn := Operation{Name: "password.SignInWithEmail"}
+
+	done, err := breaker.allow(signIn, "POST")
+	assert.NoError(t, err)
+	done(nil, errors.New("connection refused"))
+	time.Sleep(2 * time.Millisecond)
+
+	for _, neutral := range []error{context.Canceled, ErrRateLimited} {
+		done, err = breaker.allow(signIn, "POST")
+		assert.NoError(t, err)
+		done(nil, neutral)
+		assert.Equal(t, CircuitHalfOpen, breaker.state(CircuitGroupAuth))
+	}
+
+	done, err = breaker.allow(signIn, "POST")
+	assert.NoError(t, err)
+	done(&http.Response{StatusCode: http.StatusOK}, nil)
+	assert.Equal(t, CircuitClosed, breaker.state(CircuitGroupAuth))
+}
+
+func TestCircuitBreaker_StaleOutcomeIgnoredInHalfOpen(t *testing.T) {
+	breaker := newCircuitBreaker(&CircuitBreakerConfig{
+		Groups: map[string]CircuitBreakerSettings{
+			CircuitGroupAuth: {FailureThreshold: 1, Cooldown: time.Millisecond},
+		},
+	})
+	signIn := Operation{Name: "password.SignInWithEmail"}
+
+	slow, err := breaker.allow(signIn, "POST")
+	assert.NoError(t, err)
+	failed, err := breaker.allow(signIn, "POST")
+	assert.NoError(t, err)
+	failed(nil, errors.New("connection refused"))
+	assert.Equal(t, CircuitOpen, breaker.state(CircuitGroupAuth))
+
+	time.Sleep(2 * time.Millisecond)
+	trial, err := breaker.allow(signIn, "POST")
+	assert.NoError(t, err)
+
+	// Медленный запрос, начатый до размыкания, не замыкает выключатель и не освобождает место пробного запроса
+	slow(&http.Response{StatusCode: http.StatusOK}, nil)
+	assert.Equal(t, CircuitHalfOpen, breaker.state(CircuitGroupAuth))
+	_, err = breaker.allow(signIn, "POST")
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	trial(&http.Response{StatusCode: http.StatusOK}, nil)
+	assert.Equal(t, CircuitClosed, breaker.state(CircuitGroupAuth))
+}
+
+func TestDefaultCircuitGroup(t *testing.T) {
+	assert.Equal(t, CircuitGroupAuth, DefaultCircuitGroup(Operation{Name: "otp.SignInWithCode"}, "POST"))
+	assert.Equal(t, CircuitGroupAuth, DefaultCircuitGroup(Operation{Name: "sessions.RefreshAccessToken"}, "POST"))
+	assert.Equal(t, CircuitGroupRead, DefaultCircuitGroup(Operation{Name: "users.ListUsers"}, "GET"))
+	assert.Equal(t, CircuitGroupWrite, DefaultCircuitGroup(Operation{Name: "teams.DeleteTeam"}, "DELETE"))
+}
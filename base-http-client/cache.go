@@ -0,0 +1,267 @@
+package base_http_client
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCacheSize - емкость LRU-кэша по умолчанию
+const DefaultCacheSize = 1024
+
+// Cache хранит сериализованные успешные ответы GET-запросов: статус, заголовки и тело
+type Cache interface {
+	// Get возвращает сохраненное значение, если оно есть и не устарело
+	Get(key string) ([]byte, bool)
+	// Set сохраняет значение на время ttl
+	Set(key string, value []byte, ttl time.Duration)
+	// Delete удаляет значение
+	Delete(key string)
+}
+
+// DefaultCacheInvalidations - операции, после которых сбрасывается кэш связанных операций чтения
+var DefaultCacheInvalidations = map[string][]string{
+	"users.UpdateUser":        {"users.GetUser", "users.GetCurrentUser", "users.ListUsers"},
+	"users.DeleteUser":        {"users.GetUser", "users.GetCurrentUser", "users.ListUsers"},
+	"users.UpdateCurrentUser": {"users.GetUser", "users.GetCurrentUser", "users.ListUsers"},
+	"users.DeleteCurrentUser": {"users.GetUser", "users.GetCurrentUser", "users.ListUsers"},
+	"teams.UpdateTeam":        {"teams.GetTeam", "teams.ListTeams"},
+	"teams.DeleteTeam":        {"teams.GetTeam", "teams.ListTeams"},
+}
+
+// CacheConfig описывает кэширование ответов GET-запросов.
+//
+// Кэшируются только операции, для которых задан TTL. Ключ кэша учитывает URL запроса и
+// учетные данные, поэтому ответы для разных пользователей не смешиваются.
+type CacheConfig struct {
+	// Cache - хранилище ответов. По умолчанию используется NewLRUCache(DefaultCacheSize)
+	Cache Cache
+	// TTL - время жизни ответов по имени операции, например {"users.GetUser": time.Minute}
+	TTL map[string]time.Duration
+	// Invalidations - операции, после выполнения которых сбрасывается кэш указанных операций.
+	// При nil используется DefaultCacheInvalidations
+	Invalidations map[string][]string
+}
+
+// cachedHeaders - заголовки ответа, которые сохраняются в кэше вместе с телом.
+// Заголовки с учетными данными, например Set-Cookie, не сохраняются
+var cachedHeaders = append([]string{"Content-Type", "Date", "ETag", "Last-Modified"}, requestIDHeaders...)
+
+// cachedResponse - запись кэша: статус, выбранные заголовки и тело ответа
+type cachedResponse struct {
+	StatusCode int         `json:"status"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body"`
+}
+
+// CacheStats - статистика обращений к кэшу ответов
+type CacheStats struct {
+	Hits          uint64
+	Misses        uint64
+	Invalidations uint64
+}
+
+// responseCache кэширует ответы операций и сбрасывает их по правилам инвалидации.
+// Сброс выполняется увеличением поколения операции: старые ключи становятся недостижимыми.
+type responseCache struct {
+	config        CacheConfig
+	mu            sync.Mutex
+	generations   map[string]uint64
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	invalidations atomic.Uint64
+}
+
+func newResponseCache(cfg *CacheConfig) *responseCache {
+	cache := &responseCache{
+		config:      *cfg,
+		generations: map[string]uint64{},
+	}
+	if cache.config.Cache == nil {
+		cache.config.Cache = NewLRUCache(DefaultCacheSize)
+	}
+	if cache.config.Invalidations == nil {
+		cache.config.Invalidations = DefaultCacheInvalidations
+	}
+	return cache
+}
+
+// key возвращает ключ кэша для запроса или пустую строку, если запрос не кэшируется
+func (rc *responseCache) key(op Operation, method, url, credentials string) string {
+	if rc == nil || method != http.MethodGet || rc.config.TTL[op.Name] <= 0 {
+		return ""
+	}
+
+	rc.mu.Lock()
+	generation := rc.generations[op.Name]
+	rc.mu.Unlock()
+
+	return fmt.Sprintf("%s#%d %s %s", op.Name, generation, url, credentials)
+}
+
+// get возвращает сохраненный ответ и учитывает попадание или промах.
+// Запись, которую не удалось разобрать, считается промахом
+func (rc *responseCache) get(key string) (cachedResponse, bool) {
+	var entry cachedResponse
+	value, ok := rc.config.Cache.Get(key)
+	if !ok || json.Unmarshal(value, &entry) != nil {
+		rc.misses.Add(1)
+		return cachedResponse{}, false
+	}
+	rc.hits.Add(1)
+	return entry, true
+}
+
+// set сохраняет статус, выбранные заголовки и тело ответа операции
+func (rc *responseCache) set(key string, op Operation, resp *http.Response, body []byte) {
+	entry := cachedResponse{StatusCode: resp.StatusCode, Body: body}
+	for _, name := range cachedHeaders {
+		if values := resp.Header.Values(name); len(values) > 0 {
+			if entry.Header == nil {
+				entry.Header = http.Header{}
+			}
+			entry.Header[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+		}
+	}
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	rc.config.Cache.Set(key, value, rc.config.TTL[op.Name])
+}
+
+// invalidate сбрасывает кэш операций, связанных с выполненной операцией
+func (rc *responseCache) invalidate(op Operation) {
+	if rc == nil {
+		return
+	}
+	targets, ok := rc.config.Invalidations[op.Name]
+	if !ok {
+		return
+	}
+
+	rc.mu.Lock()
+	for _, target := range targets {
+		rc.generations[target]++
+	}
+	rc.mu.Unlock()
+	rc.invalidations.Add(1)
+}
+
+// stats возвращает статистику обращений к кэшу
+func (rc *responseCache) stats() CacheStats {
+	if rc == nil {
+		return CacheStats{}
+	}
+	return CacheStats{
+		Hits:          rc.hits.Load(),
+		Misses:        rc.misses.Load(),
+		Invalidations: rc.invalidations.Load(),
+	}
+}
+
+// credentialsFingerprint возвращает отпечаток учетных данных запроса для ключа кэша
+func credentialsFingerprint(cfg *Config, options *RequestOptions) string {
+	accessToken := cfg.AccessToken
+	if options.AccessToken != "" || options.UserTokens {
+		accessToken = options.AccessToken
+	}
+	accessType := cfg.AccessType
+	if options.AccessType != "" {
+		accessType = options.AccessType
+	}
+	sum := sha256.Sum256([]byte(cfg.ProjectID + "\x00" + accessType + "\x00" + accessToken))
+	return hex.EncodeToString(sum[:16])
+}
+
+// LRUCache - потокобезопасный кэш в памяти с вытеснением давно неиспользуемых записей
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewLRUCache создает LRU-кэш указанной емкости. Емкость меньше 1 считается равной DefaultCacheSize
+func NewLRUCache(capacity int) *LRUCache {
+	if capacity < 1 {
+		capacity = DefaultCacheSize
+	}
+	return &LRUCache{
+		capacity: capacity,
+		items:    map[string]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+// Get возвращает значение, если оно есть и не устарело
+func (c *LRUCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*lruEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.remove(element)
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// Set сохраняет значение на время ttl, вытесняя давно неиспользуемые записи при переполнении
+func (c *LRUCache) Set(key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+	if element, ok := c.items[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.capacity {
+		c.remove(c.order.Back())
+	}
+}
+
+// Delete удаляет значение
+func (c *LRUCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if element, ok := c.items[key]; ok {
+		c.remove(element)
+	}
+}
+
+// Len возвращает число записей в кэше, включая устаревшие
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRUCache) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.items, element.Value.(*lruEntry).key)
+}
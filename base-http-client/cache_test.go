@@ -0,0 +1,150 @@
+package base_http_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache_HitsAndInvalidation(t *testing.T) {
+	var gets int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			atomic.AddInt32(&gets, 1)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id":"user-1"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		Cache:   &CacheConfig{TTL: map[string]time.Duration{"users.GetUser": time.Minute}},
+	})
+
+	getUser := func(meta *ResponseMeta) {
+		response, err := client.Do(context.Background(), &Request{
+			Operation: "users.GetUser", Method: "GET", PathTemplate: "/users/{user_id}", Path: "/users/user-1",
+		}, WithResponseMeta(meta))
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"id":"user-1"}`, string(response))
+	}
+
+	var meta ResponseMeta
+	getUser(&meta)
+	assert.False(t, meta.Cached)
+	getUser(&meta)
+	assert.True(t, meta.Cached)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&gets))
+
+	_, err := client.Do(context.Background(), &Request{
+		Operation: "users.UpdateUser", Method: "PATCH", PathTemplate: "/users/{user_id}", Path: "/users/user-1", Body: []byte(`{}`),
+	})
+	assert.NoError(t, err)
+
+	getUser(&meta)
+	assert.False(t, meta.Cached)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&gets))
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 2, Invalidations: 1}, client.CacheStats())
+}
+
+func TestCache_KeyIncludesCredentials(t *testing.T) {
+	var gets int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&gets, 1)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		Cache:   &CacheConfig{TTL: map[string]time.Duration{"users.GetCurrentUser": time.Minute}},
+	})
+	request := func() *Request {
+		return &Request{Operation: "users.GetCurrentUser", Method: "GET", Path: "/users/me"}
+	}
+
+	client.Do(context.Background(), request(), WithAccessToken("token-a"))
+	client.Do(context.Background(), request(), WithAccessToken("token-b"))
+	client.Do(context.Background(), request(), WithAccessToken("token-a"))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&gets))
+}
+
+func TestCache_ErrorsAreNotCached(t *testing.T) {
+	var gets int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&gets, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		Cache:   &CacheConfig{TTL: map[string]time.Duration{"teams.GetTeam": time.Minute}},
+	})
+	for i := 0; i < 2; i++ {
+		_, err := client.Do(context.Background(), &Request{Operation: "teams.GetTeam", Method: "GET", Path: "/teams/unknown"})
+		assert.Error(t, err)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&gets))
+}
+
+func TestLRUCache_EvictionAndExpiry(t *testing.T) {
+	cache := NewLRUCache(2)
+	cache.Set("a", []byte("1"), time.Minute)
+	cache.Set("b", []byte("2"), time.Minute)
+	cache.Get("a")
+	cache.Set("c", []byte("3"), time.Minute)
+
+	_, ok := cache.Get("b")
+	assert.False(t, ok)
+	value, ok := cache.Get("a")
+	assert.True(t, ok)
+	assert.Equal(t, []byte("1"), value)
+
+	cache.Set("expired", []byte("4"), -time.Second)
+	_, ok = cache.Get("expired")
+	assert.False(t, ok)
+
+	cache.Delete("a")
+	assert.Equal(t, 0, cache.Len())
+}
+
+func TestCache_HitRestoresStatusAndHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Stack-Request-Id", "req-1")
+		w.Header().Set("Set-Cookie", "stack-access=secret")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id":"user-1"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		Cache:   &CacheConfig{TTL: map[string]time.Duration{"users.GetUser": time.Minute}},
+	})
+	request := func() *Request {
+		return &Request{Operation: "users.GetUser", Method: "GET", PathTemplate: "/users/{user_id}", Path: "/users/user-1"}
+	}
+
+	_, err := client.Do(context.Background(), request())
+	assert.NoError(t, err)
+
+	var meta ResponseMeta
+	response, err := client.Do(context.Background(), request(), WithResponseMeta(&meta))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":"user-1"}`, string(response))
+	assert.True(t, meta.Cached)
+	assert.Equal(t, http.StatusOK, meta.StatusCode)
+	assert.Equal(t, "req-1", meta.RequestID)
+	assert.Equal(t, "application/json", meta.Header.Get("Content-Type"))
+	assert.Empty(t, meta.Header.Get("Set-Cookie"))
+}
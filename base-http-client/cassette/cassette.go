@@ -0,0 +1,372 @@
+// Package cassette содержит транспорт для записи и воспроизведения HTTP-взаимодействий со Stack Auth.
+//
+// В режиме записи запросы выполняются через настоящий транспорт, а пары запрос-ответ сохраняются в файл
+// с замаскированными учетными данными. В режиме воспроизведения ответы берутся из файла, поэтому тесты
+// выполняются без сети. Запросы сопоставляются по методу, пути со строкой запроса и нормализованному телу.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+// ErrInteractionNotFound возвращается в режиме воспроизведения, если для запроса нет записи
+var ErrInteractionNotFound = errors.New("запись для запроса не найдена в кассете")
+
+// Mode - режим работы кассеты
+type Mode int
+
+const (
+	// ModeReplay воспроизводит записанные ответы и не обращается к сети
+	ModeReplay Mode = iota
+	// ModeRecord выполняет запросы и перезаписывает кассету
+	ModeRecord
+	// ModeRecordOnce записывает кассету, если файла нет, иначе воспроизводит ее
+	ModeRecordOnce
+)
+
+// DefaultScrubFields - поля JSON-тел и параметры строки запроса, значения которых маскируются при записи
+var DefaultScrubFields = []string{
+	"password", "old_password", "new_password", "password_hash",
+	"access_token", "refresh_token", "totp", "totp_secret_base64",
+	"client_secret", "token", "code",
+}
+
+// responseKeepFields - поля, которые не маскируются в ответах: "code" в ответе содержит код ошибки Stack Auth
+var responseKeepFields = map[string]bool{"code": true}
+
+// Cassette - содержимое файла кассеты
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction - записанная пара запрос-ответ
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request - записанный запрос
+type Request struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   Body        `json:"body,omitempty"`
+}
+
+// Response - записанный ответ
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       Body        `json:"body,omitempty"`
+}
+
+// Body - тело запроса или ответа. JSON сохраняется как документ, остальное - как строка
+type Body struct {
+	JSON json.RawMessage `json:"json,omitempty"`
+	Text string          `json:"text,omitempty"`
+}
+
+// Bytes возвращает содержимое тела
+func (b Body) Bytes() []byte {
+	if len(b.JSON) > 0 {
+		return b.JSON
+	}
+	if b.Text != "" {
+		return []byte(b.Text)
+	}
+	return nil
+}
+
+// Option настраивает Recorder
+type Option func(*Recorder)
+
+// WithMode задает режим работы кассеты. По умолчанию ModeRecordOnce
+func WithMode(mode Mode) Option {
+	return func(r *Recorder) {
+		r.mode = mode
+	}
+}
+
+// WithTransport задает транспорт для выполнения запросов в режиме записи. По умолчанию http.DefaultTransport
+func WithTransport(transport http.RoundTripper) Option {
+	return func(r *Recorder) {
+		r.transport = transport
+	}
+}
+
+// WithScrubFields добавляет поля JSON-тел и параметры строки запроса, значения которых маскируются при записи
+func WithScrubFields(fields ...string) Option {
+	return func(r *Recorder) {
+		for _, field := range fields {
+			r.scrubFields[strings.ToLower(field)] = true
+		}
+	}
+}
+
+// Recorder - http.RoundTripper, записывающий и воспроизводящий взаимодействия
+type Recorder struct {
+	path        string
+	mode        Mode
+	transport   http.RoundTripper
+	scrubFields map[string]bool
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+// New создает Recorder для файла кассеты path
+func New(path string, opts ...Option) (*Recorder, error) {
+	r := &Recorder{
+		path:        path,
+		mode:        ModeRecordOnce,
+		transport:   http.DefaultTransport,
+		scrubFields: map[string]bool{},
+	}
+	for _, field := range DefaultScrubFields {
+		r.scrubFields[field] = true
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	if r.mode == ModeRecordOnce {
+		r.mode = ModeReplay
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			r.mode = ModeRecord
+		}
+	}
+	if r.mode == ModeReplay {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения кассеты: %w", err)
+		}
+		if err := json.Unmarshal(data, &r.cassette); err != nil {
+			return nil, fmt.Errorf("ошибка разбора кассеты %s: %w", path, err)
+		}
+		r.used = make([]bool, len(r.cassette.Interactions))
+	}
+	return r, nil
+}
+
+// Mode возвращает фактический режим работы кассеты
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Client возвращает HTTP-клиент, использующий кассету, для base_http_client.Config.HTTPClient
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip выполняет или воспроизводит запрос
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, body)
+	}
+	return r.record(req, body)
+}
+
+// replay возвращает первый неиспользованный ответ, подходящий к запросу.
+// Если все подходящие записи использованы, повторяется последняя из них.
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	url := r.scrubURL(requestURL(req))
+	key := r.matchKey(req.Method, url, r.scrub(body, nil))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	found := -1
+	for i, interaction := range r.cassette.Interactions {
+		if r.matchKey(interaction.Request.Method, interaction.Request.URL, interaction.Request.Body.Bytes()) != key {
+			continue
+		}
+		found = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if found < 0 {
+		return nil, fmt.Errorf("%w: %s %s", ErrInteractionNotFound, req.Method, url)
+	}
+	r.used[found] = true
+
+	recorded := r.cassette.Interactions[found].Response
+	return &http.Response{
+		StatusCode:    recorded.StatusCode,
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(recorded.Body.Bytes())),
+		ContentLength: int64(len(recorded.Body.Bytes())),
+		Request:       req,
+	}, nil
+}
+
+// record выполняет запрос и сохраняет взаимодействие с замаскированными секретами
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	responseBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(responseBody))
+
+	interaction := Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    r.scrubURL(requestURL(req)),
+			Header: base_http_client.RedactHeaders(req.Header),
+			Body:   newBody(r.scrub(body, nil)),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     base_http_client.RedactHeaders(resp.Header),
+			Body:       newBody(r.scrub(responseBody, responseKeepFields)),
+		},
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	if err := r.save(); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// save записывает кассету в файл
+func (r *Recorder) save() error {
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return fmt.Errorf("ошибка записи кассеты: %w", err)
+	}
+	if err := os.WriteFile(r.path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("ошибка записи кассеты: %w", err)
+	}
+	return nil
+}
+
+// matchKey возвращает ключ сопоставления запроса: метод, URL с замаскированными параметрами и нормализованное тело
+func (r *Recorder) matchKey(method, url string, body []byte) string {
+	return method + " " + r.scrubURL(url) + "\n" + string(normalizeJSON(body))
+}
+
+// scrubURL маскирует значения секретных параметров строки запроса
+func (r *Recorder) scrubURL(rawURL string) string {
+	path, rawQuery, found := strings.Cut(rawURL, "?")
+	if !found {
+		return rawURL
+	}
+	query, err := neturl.ParseQuery(rawQuery)
+	if err != nil {
+		return rawURL
+	}
+	for key, values := range query {
+		if r.scrubFields[strings.ToLower(key)] {
+			for i := range values {
+				values[i] = base_http_client.RedactedValue
+			}
+		}
+	}
+	return path + "?" + query.Encode()
+}
+
+// scrub маскирует секретные поля JSON-тела, кроме полей keep
+func (r *Recorder) scrub(body []byte, keep map[string]bool) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return body
+	}
+	scrubbed, err := json.Marshal(r.scrubValue(document, keep))
+	if err != nil {
+		return body
+	}
+	return scrubbed
+}
+
+func (r *Recorder) scrubValue(value interface{}, keep map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			name := strings.ToLower(key)
+			if r.scrubFields[name] && !keep[name] {
+				v[key] = base_http_client.RedactedValue
+				continue
+			}
+			v[key] = r.scrubValue(item, keep)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.scrubValue(item, keep)
+		}
+	}
+	return value
+}
+
+// requestURL возвращает путь запроса с отсортированными параметрами строки запроса
+func requestURL(req *http.Request) string {
+	if req.URL.RawQuery == "" {
+		return req.URL.Path
+	}
+	return req.URL.Path + "?" + req.URL.Query().Encode()
+}
+
+// normalizeJSON приводит JSON-документ к каноническому виду с отсортированными ключами
+func normalizeJSON(body []byte) []byte {
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return body
+	}
+	normalized, err := json.Marshal(document)
+	if err != nil {
+		return body
+	}
+	return normalized
+}
+
+// newBody создает Body, сохраняя JSON как документ
+func newBody(body []byte) Body {
+	if len(body) == 0 {
+		return Body{}
+	}
+	if json.Valid(body) {
+		return Body{JSON: normalizeJSON(body)}
+	}
+	return Body{Text: string(body)}
+}
@@ -0,0 +1,134 @@
+package cassette
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStackServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/auth/password/sign-in":
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"access_token":"real-access","refresh_token":"real-refresh","user_id":"user-1"}`))
+		case "/api/v1/users/unknown":
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":"USER_NOT_FOUND","error":"User not found."}`))
+		default:
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"items":[],"pagination":{}}`))
+		}
+	}))
+}
+
+func newClient(recorder *Recorder, baseURL string) *base_http_client.Client {
+	return base_http_client.NewClient(base_http_client.Config{
+		BaseURL:         baseURL + "/api/v1",
+		ProjectID:       "project-id",
+		SecretServerKey: "real-secret",
+		HTTPClient:      recorder.Client(),
+	})
+}
+
+func TestRecorder_RecordThenReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures", "sign-in.json")
+	server := newStackServer(t)
+
+	recorder, err := New(path)
+	assert.NoError(t, err)
+	assert.Equal(t, ModeRecord, recorder.Mode())
+
+	client := newClient(recorder, server.URL)
+	response, err := client.SendRequest("POST", "/auth/password/sign-in", nil, []byte(`{"email":"a@example.com","password":"hunter2"}`))
+	assert.NoError(t, err)
+	assert.Contains(t, string(response), "real-access")
+	_, err = client.SendRequest("GET", "/users/unknown", nil, nil)
+	assert.ErrorIs(t, err, base_http_client.ErrUserNotFound)
+	server.Close()
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	for _, secret := range []string{"real-secret", "real-access", "real-refresh", "hunter2"} {
+		assert.NotContains(t, string(data), secret)
+	}
+	assert.Contains(t, string(data), "USER_NOT_FOUND")
+
+	replayer, err := New(path)
+	assert.NoError(t, err)
+	assert.Equal(t, ModeReplay, replayer.Mode())
+
+	client = newClient(replayer, "http://stack-auth.invalid")
+	response, err = client.SendRequest("POST", "/auth/password/sign-in", nil, []byte(`{"password":"other","email":"a@example.com"}`))
+	assert.NoError(t, err)
+	var signIn map[string]string
+	assert.NoError(t, json.Unmarshal(response, &signIn))
+	assert.Equal(t, "user-1", signIn["user_id"])
+	assert.Equal(t, base_http_client.RedactedValue, signIn["access_token"])
+
+	_, err = client.SendRequest("GET", "/users/unknown", nil, nil)
+	assert.ErrorIs(t, err, base_http_client.ErrUserNotFound)
+
+	_, err = client.SendRequest("GET", "/teams", nil, nil)
+	assert.True(t, errors.Is(err, ErrInteractionNotFound))
+}
+
+func TestRecorder_ScrubsQueryParameters(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "authorize.json")
+	server := newStackServer(t)
+
+	recorder, err := New(path)
+	assert.NoError(t, err)
+	client := newClient(recorder, server.URL)
+	query := url.Values{"client_id": {"web"}, "client_secret": {"real-client-secret"}, "token": {"real-token"}}
+	_, err = client.SendRequest("GET", "/auth/oauth/authorize/github", query, nil)
+	assert.NoError(t, err)
+	server.Close()
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "real-client-secret")
+	assert.NotContains(t, string(data), "real-token")
+	assert.Contains(t, string(data), "client_id=web")
+
+	replayer, err := New(path)
+	assert.NoError(t, err)
+	client = newClient(replayer, "http://stack-auth.invalid")
+	query = url.Values{"client_id": {"web"}, "client_secret": {"other-secret"}, "token": {"other-token"}}
+	_, err = client.SendRequest("GET", "/auth/oauth/authorize/github", query, nil)
+	assert.NoError(t, err)
+}
+
+func TestRecorder_ReplaySequence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sequence.json")
+	cassette := Cassette{Interactions: []Interaction{
+		{Request: Request{Method: "GET", URL: "/users/me"}, Response: Response{StatusCode: 200, Body: Body{JSON: json.RawMessage(`{"display_name":"Before"}`)}}},
+		{Request: Request{Method: "GET", URL: "/users/me"}, Response: Response{StatusCode: 200, Body: Body{JSON: json.RawMessage(`{"display_name":"After"}`)}}},
+	}}
+	data, _ := json.Marshal(cassette)
+	assert.NoError(t, os.WriteFile(path, data, 0o644))
+
+	recorder, err := New(path, WithMode(ModeReplay))
+	assert.NoError(t, err)
+	client := base_http_client.NewClient(base_http_client.Config{BaseURL: "http://stack-auth.invalid", HTTPClient: recorder.Client()})
+
+	for _, expected := range []string{"Before", "After", "After"} {
+		response, err := client.SendRequest("GET", "/users/me", nil, nil)
+		assert.NoError(t, err)
+		assert.Contains(t, string(response), expected)
+	}
+}
+
+func TestNew_ReplayWithoutFile(t *testing.T) {
+	_, err := New(filepath.Join(t.TempDir(), "missing.json"), WithMode(ModeReplay))
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
@@ -2,14 +2,20 @@ package base_http_client
 
 import (
 	"bytes"
-	"encoding/json"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
 	"time"
+
+	_interface "github.com/BlaisePopov/stack-auth/base-http-client/interface"
+	"github.com/BlaisePopov/stack-auth/base-http-client/tracing"
 )
 
+// Request описывает запрос к API Stack Auth
+type Request = _interface.Request
+
 type Config struct {
 	ProjectID            string
 	SecretServerKey      string
@@ -20,17 +26,40 @@ type Config struct {
 	PublishableClientKey string
 	BaseURL              string
 	HTTPClient           *http.Client
+	// RetryPolicy задает политику повторных попыток. При nil каждый запрос выполняется один раз
+	RetryPolicy *RetryPolicy
+	// Middlewares - цепочка обработчиков, выполняемых вокруг каждой попытки запроса
+	Middlewares []Middleware
+	// Logging включает логирование запросов через log/slog. При nil логирование отключено
+	Logging *LoggingConfig
+	// Tracer создает спаны для каждой операции. По умолчанию используется tracing.Noop()
+	Tracer tracing.Tracer
+	// Metrics получает метрики каждой операции. При nil метрики не собираются
+	Metrics MetricsRecorder
+	// RateLimit включает клиентское ограничение частоты запросов. При nil ограничение отключено
+	RateLimit *RateLimiterConfig
+	// Cache включает кэширование ответов GET-запросов. При nil кэширование отключено
+	Cache *CacheConfig
+	// CircuitBreaker включает автоматический выключатель для групп операций. При nil выключатель отключен
+	CircuitBreaker *CircuitBreakerConfig
+	// TokenStore хранит токены пользователя. Токены из хранилища передаются с каждым запросом,
+	// а токены, выданные при входе, обновлении и выходе, сохраняются автоматически. При nil хранилище не используется
+	TokenStore TokenStore
 }
 
 const (
 	DefaultBaseURL        = "https://api.stack-auth.com/api/v1"
 	DefaultRequestTimeout = 30 * time.Second
-	DefaultAccessType     = "server"
+	DefaultAccessType     = AccessTypeServer
 )
 
 type Client struct {
 	httpClient *http.Client
 	config     Config
+	handler    Handler
+	tracer     tracing.Tracer
+	cache      *responseCache
+	breaker    *circuitBreaker
 }
 
 func NewClient(cfg Config) *Client {
@@ -54,18 +83,176 @@ func NewClient(cfg Config) *Client {
 		}
 	}
 
+	client.tracer = client.config.Tracer
+	if client.tracer == nil {
+		client.tracer = tracing.Noop()
+	}
+
+	if client.config.Cache != nil {
+		client.cache = newResponseCache(client.config.Cache)
+	}
+
+	if client.config.CircuitBreaker != nil {
+		client.breaker = newCircuitBreaker(client.config.CircuitBreaker)
+	}
+
+	var middlewares []Middleware
+	if client.config.RateLimit != nil {
+		middlewares = append(middlewares, newRateLimiter(client.config.RateLimit).middleware())
+	}
+	middlewares = append(middlewares, client.config.Middlewares...)
+	if client.config.Logging != nil && client.config.Logging.Logger != nil {
+		middlewares = append(middlewares, loggingMiddleware(client.config.Logging))
+	}
+	client.handler = chainMiddlewares(middlewares, client.roundTrip)
+
 	return client
 }
 
+// CacheStats возвращает статистику кэша ответов. Без Config.Cache возвращает нулевые значения
+func (c *Client) CacheStats() CacheStats {
+	return c.cache.stats()
+}
+
+// CircuitState возвращает состояние автоматического выключателя группы операций.
+// Без Config.CircuitBreaker всегда возвращает CircuitClosed
+func (c *Client) CircuitState(group string) CircuitState {
+	return c.breaker.state(group)
+}
+
 // SendRequest отправляет HTTP-запрос к API.
-func (c *Client) SendRequest(method, path string, queryParams url.Values, body []byte) ([]byte, error) {
-	fullURL := c.config.BaseURL + path
+func (c *Client) SendRequest(method, path string, queryParams url.Values, body []byte, opts ...RequestOption) ([]byte, error) {
+	return c.SendRequestContext(context.Background(), method, path, queryParams, body, opts...)
+}
+
+// SendRequestContext отправляет HTTP-запрос к API с учетом контекста.
+// Отмена контекста или истечение его дедлайна прерывает выполняющийся запрос.
+func (c *Client) SendRequestContext(ctx context.Context, method, path string, queryParams url.Values, body []byte, opts ...RequestOption) ([]byte, error) {
+	return c.Do(ctx, &Request{
+		Method: method,
+		Path:   path,
+		Query:  queryParams,
+		Body:   body,
+	}, opts...)
+}
+
+// Do выполняет запрос к API в рамках операции Stack Auth.
+// Имя операции и шаблон пути передаются в цепочку Middleware, трассировку и метрики.
+// Опции запроса переопределяют настройки клиента только для этого вызова.
+func (c *Client) Do(ctx context.Context, request *Request, opts ...RequestOption) ([]byte, error) {
+	op := Operation{Name: request.Operation, PathTemplate: request.PathTemplate}
+	if op.PathTemplate == "" {
+		// Путь операции без шаблона не содержит идентификаторов. Путь запроса через SendRequest
+		// может их содержать, поэтому в метки он не попадает
+		op.PathTemplate = UnknownPathTemplate
+		if request.Operation != "" {
+			op.PathTemplate = request.Path
+		}
+	}
+
+	options := _interface.NewRequestOptions(opts...)
+	if options.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, options.Timeout)
+		defer cancel()
+	}
+	if options.IdempotencyKey != "" {
+		ctx = ContextWithIdempotencyKey(ctx, options.IdempotencyKey)
+	}
+
+	store := c.config.TokenStore
+	if options.UserTokens {
+		// Токены пользователя заданы явно, а хранилище клиента относится к его собственному пользователю
+		store = nil
+	}
+	if options.TokenStore != nil {
+		store = options.TokenStore
+	}
+	var stored Tokens
+	if store != nil {
+		var err error
+		if stored, err = store.Load(ctx); err != nil {
+			return nil, fmt.Errorf("ошибка загрузки токенов из хранилища: %w", err)
+		}
+		if options.AccessToken == "" && !options.UserTokens {
+			options.AccessToken = stored.AccessToken
+		}
+		if options.RefreshToken == "" && !options.UserTokens {
+			options.RefreshToken = stored.RefreshToken
+		}
+	}
 
-	u, err := url.Parse(fullURL)
+	baseURL := c.config.BaseURL
+	if options.BaseURL != "" {
+		baseURL = options.BaseURL
+	}
+	u, err := buildURL(baseURL, request.Path, request.Query)
 	if err != nil {
 		return nil, err
 	}
 
+	cacheKey := c.cache.key(op, request.Method, u, credentialsFingerprint(&c.config, &options))
+	if cacheKey != "" {
+		if entry, ok := c.cache.get(cacheKey); ok {
+			if options.ResponseMeta != nil {
+				*options.ResponseMeta = ResponseMeta{
+					StatusCode: entry.StatusCode,
+					Header:     entry.Header,
+					RequestID:  requestIDOf(entry.Header),
+					Cached:     true,
+				}
+			}
+			return entry.Body, nil
+		}
+	}
+
+	labels := MetricLabels{Operation: op.Name, Method: request.Method, PathTemplate: op.PathTemplate}
+	if c.config.Metrics != nil {
+		c.config.Metrics.RequestStarted(labels)
+	}
+	start := time.Now()
+
+	resp, responseBody, err := c.perform(ctx, op, request.Method, u, request.Body, &options)
+	duration := time.Since(start)
+
+	if err == nil && cacheKey != "" && resp != nil {
+		c.cache.set(cacheKey, op, resp, responseBody)
+	}
+	c.cache.invalidate(op)
+
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	if c.config.Metrics != nil {
+		c.config.Metrics.RequestFinished(labels, status, metricsErrorCode(err), duration)
+	}
+	if options.ResponseMeta != nil {
+		*options.ResponseMeta = ResponseMeta{StatusCode: status, Duration: duration}
+		if resp != nil {
+			options.ResponseMeta.Header = resp.Header
+			options.ResponseMeta.RequestID = requestIDOf(resp.Header)
+		}
+	}
+	if err == nil && store != nil {
+		refreshToken := options.RefreshToken
+		if refreshToken == "" && !options.UserTokens {
+			refreshToken = c.config.RefreshToken
+		}
+		if err := updateTokenStore(ctx, store, op, stored, refreshToken, responseBody); err != nil {
+			return responseBody, fmt.Errorf("%w: %w", ErrTokenStoreSave, err)
+		}
+	}
+	return responseBody, err
+}
+
+// buildURL формирует полный URL запроса
+func buildURL(baseURL, path string, queryParams url.Values) (string, error) {
+	u, err := url.Parse(baseURL + path)
+	if err != nil {
+		return "", err
+	}
+
 	if queryParams != nil {
 		params := u.Query()
 		for key, values := range queryParams {
@@ -73,50 +260,127 @@ func (c *Client) SendRequest(method, path string, queryParams url.Values, body [
 		}
 		u.RawQuery = params.Encode()
 	}
+	return u.String(), nil
+}
+
+// perform выполняет операцию в рамках спана трассировки.
+// Отказ открытого автомата отключения тоже записывается в спан
+func (c *Client) perform(ctx context.Context, op Operation, method, url string, body []byte, options *RequestOptions) (*http.Response, []byte, error) {
+	ctx, span := c.tracer.Start(ctx, spanName(op),
+		tracing.String("stackauth.operation", op.Name),
+		tracing.String("http.request.method", method),
+		tracing.String("url.template", op.PathTemplate),
+	)
+	defer span.End()
+
+	done, err := c.breaker.allow(op, method)
+	if err != nil {
+		recordSpanResult(span, nil, err)
+		return nil, nil, err
+	}
+	resp, responseBody, err := c.execute(ctx, op, method, url, body, options, span.SpanContext())
+	err = redactError(err)
+	if err == nil {
+		responseBody, err = handleResponse(resp, responseBody)
+	}
+	done(resp, err)
+	recordSpanResult(span, resp, err)
+	return resp, responseBody, err
+}
+
+// execute выполняет запрос с учетом политики повторов и возвращает результат последней попытки
+func (c *Client) execute(ctx context.Context, op Operation, method, url string, body []byte, options *RequestOptions, sc tracing.SpanContext) (*http.Response, []byte, error) {
+	idempotencyKey := IdempotencyKeyFromContext(ctx)
+	maxAttempts := c.config.RetryPolicy.maxAttempts(method, idempotencyKey != "")
+
+	for attempt := 1; ; attempt++ {
+		req, err := c.newRequest(ctx, method, url, body, options)
+		if err != nil {
+			return nil, nil, err
+		}
+		if idempotencyKey != "" {
+			req.Header.Set(IdempotencyKeyHeader, idempotencyKey)
+		}
+		if sc.IsValid() {
+			req.Header.Set(tracing.TraceParentHeader, sc.TraceParent())
+		}
+
+		resp, err := c.handler(op, req)
+		var responseBody []byte
+		if err == nil {
+			responseBody, err = io.ReadAll(resp.Body)
+			resp.Body.Close()
+		}
+
+		if attempt < maxAttempts && c.config.RetryPolicy.shouldRetry(ctx, resp, err) {
+			if err := sleepContext(ctx, c.config.RetryPolicy.backoff(attempt, resp)); err != nil {
+				return nil, nil, err
+			}
+			continue
+		}
+
+		if err != nil {
+			return nil, nil, err
+		}
+		return resp, responseBody, nil
+	}
+}
+
+// roundTrip отправляет запрос через HTTP-клиент и замыкает цепочку Middleware
+func (c *Client) roundTrip(_ Operation, req *http.Request) (*http.Response, error) {
+	return c.httpClient.Do(req)
+}
 
+// newRequest создает HTTP-запрос с заголовками аутентификации Stack Auth и заголовками из опций запроса
+func (c *Client) newRequest(ctx context.Context, method, url string, body []byte, options *RequestOptions) (*http.Request, error) {
 	var req *http.Request
-	if body != nil && len(body) > 0 {
-		req, err = http.NewRequest(method, u.String(), bytes.NewBuffer(body))
+	var err error
+	if len(body) > 0 {
+		req, err = http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
 		if err != nil {
 			return nil, err
 		}
 		req.Header.Set("Content-Type", "application/json")
 	} else {
 		// Создаем запрос без тела
-		req, err = http.NewRequest(method, u.String(), nil)
+		req, err = http.NewRequestWithContext(ctx, method, url, nil)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("X-Stack-Access-Type", c.config.AccessType)
-	req.Header.Set("X-Stack-Project-Id", c.config.ProjectID)
-	req.Header.Set("X-Stack-Secret-Server-Key", c.config.SecretServerKey)
-	req.Header.Set("X-Stack-Access-Token", c.config.AccessToken)
-	req.Header.Set("X-Stack-Refresh-Token", c.config.RefreshToken)
-	req.Header.Set("X-Stack-Publishable-Client-Key", c.config.PublishableClientKey)
-	req.Header.Set("X-Stack-Super-Secret-Admin-Key", c.config.SuperSecretAdminKey)
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, err
+	cfg := c.config
+	if options.AccessType != "" {
+		if cfg, err = c.config.withAccessType(options.AccessType); err != nil {
+			return nil, err
+		}
 	}
-	defer resp.Body.Close()
-
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+	if options.UserTokens {
+		cfg.AccessToken, cfg.RefreshToken = "", ""
 	}
+	req.Header.Set("Accept", "application/json")
+	setCredentialHeaders(req.Header, &cfg)
 
-	if resp.StatusCode >= 400 {
-		apiError := &APIError{}
-		err := json.Unmarshal(responseBody, &apiError)
-		if err == nil && apiError.Message != "" {
-			return nil, apiError
+	if options.AccessToken != "" {
+		req.Header.Set("X-Stack-Access-Token", options.AccessToken)
+	}
+	if options.RefreshToken != "" {
+		req.Header.Set("X-Stack-Refresh-Token", options.RefreshToken)
+	}
+	for key, values := range options.Header {
+		req.Header.Del(key)
+		for _, value := range values {
+			req.Header.Add(key, value)
 		}
+	}
 
-		return nil, fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(responseBody))
+	return req, nil
+}
+
+// handleResponse проверяет статус ответа и преобразует ошибки API
+func handleResponse(resp *http.Response, responseBody []byte) ([]byte, error) {
+	if resp.StatusCode >= 400 {
+		return nil, newAPIError(resp, responseBody)
 	}
 
 	return responseBody, nil
@@ -0,0 +1,70 @@
+package base_http_client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendRequestContext_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/users", r.URL.Path)
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "project-id", r.Header.Get("X-Stack-Project-Id"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"items":[]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, ProjectID: "project-id"})
+	response, err := client.SendRequestContext(context.Background(), "GET", "/users", nil, nil)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"items":[]}`, string(response))
+}
+
+func TestSendRequestContext_Canceled(t *testing.T) {
+	started := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	response, err := client.SendRequestContext(ctx, "GET", "/users", nil, nil)
+	assert.Nil(t, response)
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestSendRequestContext_DeadlineExceeded(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	response, err := client.SendRequestContext(ctx, "GET", "/users", nil, nil)
+	assert.Nil(t, response)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
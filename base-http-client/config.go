@@ -0,0 +1,155 @@
+package base_http_client
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Типы доступа к API Stack Auth
+const (
+	AccessTypeClient = "client"
+	AccessTypeServer = "server"
+	AccessTypeAdmin  = "admin"
+)
+
+// ConfigError описывает ошибку в конфигурации клиента
+type ConfigError struct {
+	// Field - имя поля Config, например "SecretServerKey"
+	Field string
+	// Source - источник значения, например имя переменной окружения. Может быть пустым
+	Source string
+	// Message - описание ошибки
+	Message string
+}
+
+func (e *ConfigError) Error() string {
+	if e.Source != "" {
+		return fmt.Sprintf("некорректная конфигурация Stack Auth: %s (%s): %s", e.Field, e.Source, e.Message)
+	}
+	return fmt.Sprintf("некорректная конфигурация Stack Auth: %s: %s", e.Field, e.Message)
+}
+
+// credentialField описывает учетные данные, передаваемые в заголовке
+type credentialField struct {
+	name   string
+	header string
+	value  func(cfg *Config) string
+}
+
+var (
+	projectIDField      = credentialField{"ProjectID", "X-Stack-Project-Id", func(cfg *Config) string { return cfg.ProjectID }}
+	publishableKeyField = credentialField{"PublishableClientKey", "X-Stack-Publishable-Client-Key", func(cfg *Config) string { return cfg.PublishableClientKey }}
+	secretKeyField      = credentialField{"SecretServerKey", "X-Stack-Secret-Server-Key", func(cfg *Config) string { return cfg.SecretServerKey }}
+	adminKeyField       = credentialField{"SuperSecretAdminKey", "X-Stack-Super-Secret-Admin-Key", func(cfg *Config) string { return cfg.SuperSecretAdminKey }}
+	accessTokenField    = credentialField{"AccessToken", "X-Stack-Access-Token", func(cfg *Config) string { return cfg.AccessToken }}
+	refreshTokenField   = credentialField{"RefreshToken", "X-Stack-Refresh-Token", func(cfg *Config) string { return cfg.RefreshToken }}
+)
+
+// accessTypeRules - обязательные, допустимые и запрещенные учетные данные для каждого типа доступа
+var accessTypeRules = map[string]struct {
+	required  []credentialField
+	allowed   []credentialField
+	forbidden []credentialField
+}{
+	AccessTypeClient: {
+		required:  []credentialField{projectIDField, publishableKeyField},
+		allowed:   []credentialField{accessTokenField, refreshTokenField},
+		forbidden: []credentialField{secretKeyField, adminKeyField},
+	},
+	AccessTypeServer: {
+		required:  []credentialField{projectIDField, secretKeyField},
+		allowed:   []credentialField{accessTokenField, refreshTokenField},
+		forbidden: []credentialField{adminKeyField},
+	},
+	AccessTypeAdmin: {
+		required: []credentialField{projectIDField, adminKeyField},
+		allowed:  []credentialField{accessTokenField, refreshTokenField},
+	},
+}
+
+// Validate проверяет, что для типа доступа заданы нужные ключи и нет ключей с более широкими правами.
+// Пустой AccessType считается равным DefaultAccessType.
+func (cfg Config) Validate() error {
+	accessType := cfg.AccessType
+	if accessType == "" {
+		accessType = DefaultAccessType
+	}
+
+	rules, ok := accessTypeRules[accessType]
+	if !ok {
+		return &ConfigError{Field: "AccessType", Message: fmt.Sprintf("неизвестный тип доступа %q", accessType)}
+	}
+	for _, field := range rules.required {
+		if field.value(&cfg) == "" {
+			return &ConfigError{Field: field.name, Message: fmt.Sprintf("обязателен для типа доступа %q", accessType)}
+		}
+	}
+	for _, field := range rules.forbidden {
+		if field.value(&cfg) != "" {
+			return &ConfigError{Field: field.name, Message: fmt.Sprintf("не должен использоваться с типом доступа %q", accessType)}
+		}
+	}
+	return nil
+}
+
+// NewServerClient создает клиент с типом доступа "server".
+// Требует ProjectID и SecretServerKey и отклоняет конфигурацию с SuperSecretAdminKey.
+func NewServerClient(cfg Config) (*Client, error) {
+	return newClientWithAccessType(cfg, AccessTypeServer)
+}
+
+// NewClientAccessClient создает клиент с типом доступа "client".
+// Требует ProjectID и PublishableClientKey и отклоняет конфигурацию с серверными или административными ключами.
+func NewClientAccessClient(cfg Config) (*Client, error) {
+	return newClientWithAccessType(cfg, AccessTypeClient)
+}
+
+// NewAdminClient создает клиент с типом доступа "admin".
+// Требует ProjectID и SuperSecretAdminKey.
+func NewAdminClient(cfg Config) (*Client, error) {
+	return newClientWithAccessType(cfg, AccessTypeAdmin)
+}
+
+// newClientWithAccessType проверяет конфигурацию для типа доступа и создает клиент
+func newClientWithAccessType(cfg Config, accessType string) (*Client, error) {
+	if cfg.AccessType != "" && cfg.AccessType != accessType {
+		return nil, &ConfigError{Field: "AccessType", Message: fmt.Sprintf("ожидается %q, получено %q", accessType, cfg.AccessType)}
+	}
+	cfg.AccessType = accessType
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return NewClient(cfg), nil
+}
+
+// setCredentialHeaders устанавливает заголовки с учетными данными, относящимися к типу доступа.
+// Пустые значения не передаются. Для неизвестного типа доступа передаются все заданные учетные данные.
+func setCredentialHeaders(header http.Header, cfg *Config) {
+	header.Set("X-Stack-Access-Type", cfg.AccessType)
+
+	fields := []credentialField{projectIDField, publishableKeyField, secretKeyField, adminKeyField, accessTokenField, refreshTokenField}
+	if rules, ok := accessTypeRules[cfg.AccessType]; ok {
+		fields = append(append([]credentialField{}, rules.required...), rules.allowed...)
+	}
+	for _, field := range fields {
+		if value := field.value(cfg); value != "" {
+			header.Set(field.header, value)
+		}
+	}
+}
+
+// withAccessType возвращает копию конфигурации с другим типом доступа.
+// Проверяет только наличие обязательных ключей: ключи, не относящиеся к типу доступа, не передаются в запрос
+func (cfg Config) withAccessType(accessType string) (Config, error) {
+	rules, ok := accessTypeRules[accessType]
+	if !ok {
+		return cfg, &ConfigError{Field: "AccessType", Message: fmt.Sprintf("неизвестный тип доступа %q", accessType)}
+	}
+	for _, field := range rules.required {
+		if field.value(&cfg) == "" {
+			return cfg, &ConfigError{Field: field.name, Message: fmt.Sprintf("обязателен для типа доступа %q", accessType)}
+		}
+	}
+	cfg.AccessType = accessType
+	return cfg, nil
+}
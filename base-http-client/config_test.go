@@ -0,0 +1,90 @@
+package base_http_client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleConstructors_Validation(t *testing.T) {
+	tests := []struct {
+		name        string
+		constructor func(Config) (*Client, error)
+		config      Config
+		field       string
+	}{
+		{"server ok", NewServerClient, Config{ProjectID: "p", SecretServerKey: "s"}, ""},
+		{"server without secret", NewServerClient, Config{ProjectID: "p"}, "SecretServerKey"},
+		{"server with admin key", NewServerClient, Config{ProjectID: "p", SecretServerKey: "s", SuperSecretAdminKey: "a"}, "SuperSecretAdminKey"},
+		{"client ok", NewClientAccessClient, Config{ProjectID: "p", PublishableClientKey: "pk"}, ""},
+		{"client without project", NewClientAccessClient, Config{PublishableClientKey: "pk"}, "ProjectID"},
+		{"client with server secret", NewClientAccessClient, Config{ProjectID: "p", PublishableClientKey: "pk", SecretServerKey: "s"}, "SecretServerKey"},
+		{"admin ok", NewAdminClient, Config{ProjectID: "p", SuperSecretAdminKey: "a"}, ""},
+		{"admin without key", NewAdminClient, Config{ProjectID: "p", SecretServerKey: "s"}, "SuperSecretAdminKey"},
+		{"conflicting access type", NewAdminClient, Config{ProjectID: "p", SuperSecretAdminKey: "a", AccessType: AccessTypeClient}, "AccessType"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := tt.constructor(tt.config)
+			if tt.field == "" {
+				assert.NoError(t, err)
+				assert.NotNil(t, client)
+				return
+			}
+
+			assert.Nil(t, client)
+			var configError *ConfigError
+			assert.True(t, errors.As(err, &configError))
+			assert.Equal(t, tt.field, configError.Field)
+		})
+	}
+}
+
+func TestNewClientAccessClient_SendsOnlyClientHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "client", r.Header.Get("X-Stack-Access-Type"))
+		assert.Equal(t, "project-id", r.Header.Get("X-Stack-Project-Id"))
+		assert.Equal(t, "publishable-key", r.Header.Get("X-Stack-Publishable-Client-Key"))
+		assert.Equal(t, "access-token", r.Header.Get("X-Stack-Access-Token"))
+		assert.NotContains(t, r.Header, "X-Stack-Secret-Server-Key")
+		assert.NotContains(t, r.Header, "X-Stack-Super-Secret-Admin-Key")
+		assert.NotContains(t, r.Header, "X-Stack-Refresh-Token")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewClientAccessClient(Config{
+		BaseURL:              server.URL,
+		ProjectID:            "project-id",
+		PublishableClientKey: "publishable-key",
+		AccessToken:          "access-token",
+	})
+	assert.NoError(t, err)
+
+	_, err = client.SendRequest("GET", "/users/me", nil, nil)
+	assert.NoError(t, err)
+}
+
+func TestNewServerClient_OmitsClientKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secret-key", r.Header.Get("X-Stack-Secret-Server-Key"))
+		assert.NotContains(t, r.Header, "X-Stack-Publishable-Client-Key")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client, err := NewServerClient(Config{
+		BaseURL:              server.URL,
+		ProjectID:            "project-id",
+		SecretServerKey:      "secret-key",
+		PublishableClientKey: "publishable-key",
+	})
+	assert.NoError(t, err)
+
+	_, err = client.SendRequest("GET", "/users", nil, nil)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,99 @@
+package base_http_client
+
+import (
+	"os"
+	"strings"
+)
+
+// Переменные окружения, из которых ConfigFromEnv читает конфигурацию
+const (
+	EnvProjectID            = "STACK_PROJECT_ID"
+	EnvPublishableClientKey = "STACK_PUBLISHABLE_CLIENT_KEY"
+	EnvSecretServerKey      = "STACK_SECRET_SERVER_KEY"
+	EnvSuperSecretAdminKey  = "STACK_SUPER_SECRET_ADMIN_KEY"
+	EnvAPIURL               = "STACK_API_URL"
+	EnvAccessType           = "STACK_ACCESS_TYPE"
+)
+
+// NextPublicPrefix - префикс публичных переменных окружения Next.js
+const NextPublicPrefix = "NEXT_PUBLIC_"
+
+// apiVersionPath - путь версии API, который добавляется к базовому URL из окружения или файла
+const apiVersionPath = "/api/v1"
+
+// envField связывает поле Config с переменными окружения, из которых оно читается по порядку
+type envField struct {
+	name  string
+	vars  []string
+	apply func(cfg *Config, value string)
+}
+
+var envFields = []envField{
+	{"ProjectID", []string{EnvProjectID, NextPublicPrefix + EnvProjectID}, func(cfg *Config, v string) { cfg.ProjectID = v }},
+	{"PublishableClientKey", []string{EnvPublishableClientKey, NextPublicPrefix + EnvPublishableClientKey}, func(cfg *Config, v string) { cfg.PublishableClientKey = v }},
+	{"SecretServerKey", []string{EnvSecretServerKey}, func(cfg *Config, v string) { cfg.SecretServerKey = v }},
+	{"SuperSecretAdminKey", []string{EnvSuperSecretAdminKey}, func(cfg *Config, v string) { cfg.SuperSecretAdminKey = v }},
+	{"BaseURL", []string{EnvAPIURL, NextPublicPrefix + EnvAPIURL, "STACK_URL", NextPublicPrefix + "STACK_URL"}, func(cfg *Config, v string) { cfg.BaseURL = normalizeBaseURL(v) }},
+	{"AccessType", []string{EnvAccessType}, func(cfg *Config, v string) { cfg.AccessType = v }},
+}
+
+// ConfigFromEnv читает конфигурацию клиента из переменных окружения и проверяет ее.
+//
+// Поддерживаются переменные STACK_PROJECT_ID, STACK_PUBLISHABLE_CLIENT_KEY, STACK_SECRET_SERVER_KEY,
+// STACK_SUPER_SECRET_ADMIN_KEY, STACK_API_URL (или STACK_URL) и STACK_ACCESS_TYPE. Публичные значения
+// также читаются из переменных с префиксом NEXT_PUBLIC_. Если STACK_ACCESS_TYPE не задан, тип доступа
+// определяется по набору ключей. Ошибка проверки содержит имя переменной окружения в ConfigError.Source.
+func ConfigFromEnv() (Config, error) {
+	var cfg Config
+	for _, field := range envFields {
+		for _, name := range field.vars {
+			if value, ok := os.LookupEnv(name); ok && value != "" {
+				field.apply(&cfg, value)
+				break
+			}
+		}
+	}
+	if cfg.AccessType == "" {
+		cfg.AccessType = inferAccessType(cfg)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		if configError, ok := err.(*ConfigError); ok {
+			configError.Source = envVarOf(configError.Field)
+		}
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// inferAccessType определяет тип доступа по заданным ключам
+func inferAccessType(cfg Config) string {
+	switch {
+	case cfg.SuperSecretAdminKey != "":
+		return AccessTypeAdmin
+	case cfg.SecretServerKey != "":
+		return AccessTypeServer
+	case cfg.PublishableClientKey != "":
+		return AccessTypeClient
+	}
+	return DefaultAccessType
+}
+
+// envVarOf возвращает основную переменную окружения для поля Config
+func envVarOf(field string) string {
+	for _, f := range envFields {
+		if f.name == field {
+			return f.vars[0]
+		}
+	}
+	return ""
+}
+
+// normalizeBaseURL добавляет путь версии API к адресу Stack Auth, если он не указан
+func normalizeBaseURL(value string) string {
+	value = strings.TrimRight(value, "/")
+	if value == "" || strings.HasSuffix(value, apiVersionPath) {
+		return value
+	}
+	return value + apiVersionPath
+}
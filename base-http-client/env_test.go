@@ -0,0 +1,46 @@
+package base_http_client
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigFromEnv_Server(t *testing.T) {
+	t.Setenv(EnvProjectID, "project-id")
+	t.Setenv(EnvSecretServerKey, "secret-key")
+	t.Setenv(EnvAPIURL, "https://auth.example.com/")
+
+	cfg, err := ConfigFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, "project-id", cfg.ProjectID)
+	assert.Equal(t, "secret-key", cfg.SecretServerKey)
+	assert.Equal(t, AccessTypeServer, cfg.AccessType)
+	assert.Equal(t, "https://auth.example.com/api/v1", cfg.BaseURL)
+}
+
+func TestConfigFromEnv_NextPublic(t *testing.T) {
+	t.Setenv("NEXT_PUBLIC_STACK_PROJECT_ID", "project-id")
+	t.Setenv("NEXT_PUBLIC_STACK_PUBLISHABLE_CLIENT_KEY", "publishable-key")
+	t.Setenv("NEXT_PUBLIC_STACK_API_URL", "https://auth.example.com/api/v1")
+
+	cfg, err := ConfigFromEnv()
+	assert.NoError(t, err)
+	assert.Equal(t, "project-id", cfg.ProjectID)
+	assert.Equal(t, "publishable-key", cfg.PublishableClientKey)
+	assert.Equal(t, AccessTypeClient, cfg.AccessType)
+	assert.Equal(t, "https://auth.example.com/api/v1", cfg.BaseURL)
+}
+
+func TestConfigFromEnv_MissingKey(t *testing.T) {
+	t.Setenv(EnvProjectID, "project-id")
+	t.Setenv(EnvAccessType, AccessTypeServer)
+
+	_, err := ConfigFromEnv()
+	var configError *ConfigError
+	assert.True(t, errors.As(err, &configError))
+	assert.Equal(t, "SecretServerKey", configError.Field)
+	assert.Equal(t, EnvSecretServerKey, configError.Source)
+	assert.Contains(t, err.Error(), EnvSecretServerKey)
+}
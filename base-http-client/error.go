@@ -1,12 +1,123 @@
 package base_http_client
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
 
+// KnownErrorHeader - заголовок, в котором Stack Auth передает код известной ошибки
+const KnownErrorHeader = "X-Stack-Known-Error"
+
+// requestIDHeaders - заголовки, в которых может передаваться идентификатор запроса
+var requestIDHeaders = []string{"X-Stack-Request-Id", "X-Request-Id"}
+
+// APIError представляет ошибку, возвращенную API Stack Auth.
+//
+// Ошибка поддерживает errors.Is с сентинелами ErrorCode:
+//
+//	if errors.Is(err, base_http_client.ErrUserNotFound) { ... }
 type APIError struct {
-	Code    string `json:"code"`
+	// Code - код известной ошибки Stack Auth (например, USER_NOT_FOUND)
+	Code string `json:"code"`
+	// Message - текстовое описание ошибки
 	Message string `json:"error"`
+	// Details - дополнительные сведения об ошибке, если они переданы сервером
+	Details json.RawMessage `json:"details,omitempty"`
+	// StatusCode - HTTP-статус ответа
+	StatusCode int `json:"-"`
+	// Header - заголовки ответа
+	Header http.Header `json:"-"`
+	// RequestID - идентификатор запроса, если сервер его вернул
+	RequestID string `json:"-"`
+	// Body - необработанное тело ответа
+	Body []byte `json:"-"`
 }
 
 func (e *APIError) Error() string {
-	return fmt.Sprintf("[%s] %s", e.Code, e.Message)
+	if e.Code != "" || e.Message != "" {
+		return fmt.Sprintf("[%s] %s", e.Code, e.Message)
+	}
+	return fmt.Sprintf("request failed with status %d: %s", e.StatusCode, string(e.Body))
+}
+
+// Is позволяет сравнивать ошибку с сентинелами ErrorCode через errors.Is
+func (e *APIError) Is(target error) bool {
+	code, ok := target.(ErrorCode)
+	return ok && e.Code != "" && string(code) == e.Code
+}
+
+// newAPIError формирует APIError по ответу сервера.
+// Тело ответа, не являющееся JSON, сохраняется в поле Body.
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiError := &APIError{}
+	if err := json.Unmarshal(body, apiError); err != nil {
+		apiError = &APIError{}
+	}
+
+	apiError.StatusCode = resp.StatusCode
+	apiError.Header = resp.Header
+	apiError.Body = body
+	if apiError.Code == "" {
+		apiError.Code = resp.Header.Get(KnownErrorHeader)
+	}
+	apiError.RequestID = requestIDOf(resp.Header)
+	return apiError
 }
+
+// requestIDOf возвращает идентификатор запроса из заголовков ответа
+func requestIDOf(header http.Header) string {
+	for _, name := range requestIDHeaders {
+		if requestID := header.Get(name); requestID != "" {
+			return requestID
+		}
+	}
+	return ""
+}
+
+// ErrorCode - код известной ошибки Stack Auth, используемый как сентинел для errors.Is
+type ErrorCode string
+
+func (c ErrorCode) Error() string {
+	return string(c)
+}
+
+// Известные коды ошибок Stack Auth
+var (
+	ErrSchemaError                   = ErrorCode("SCHEMA_ERROR")
+	ErrInvalidProjectAuthentication  = ErrorCode("INVALID_PROJECT_AUTHENTICATION")
+	ErrInvalidPublishableClientKey   = ErrorCode("INVALID_PUBLISHABLE_CLIENT_KEY")
+	ErrInvalidSecretServerKey        = ErrorCode("INVALID_SECRET_SERVER_KEY")
+	ErrInvalidSuperSecretAdminKey    = ErrorCode("INVALID_SUPER_SECRET_ADMIN_KEY")
+	ErrInsufficientAccessType        = ErrorCode("INSUFFICIENT_ACCESS_TYPE")
+	ErrProjectNotFound               = ErrorCode("PROJECT_NOT_FOUND")
+	ErrUserAuthenticationRequired    = ErrorCode("USER_AUTHENTICATION_REQUIRED")
+	ErrUnparsableAccessToken         = ErrorCode("UNPARSABLE_ACCESS_TOKEN")
+	ErrAccessTokenExpired            = ErrorCode("ACCESS_TOKEN_EXPIRED")
+	ErrRefreshTokenNotFoundOrExpired = ErrorCode("REFRESH_TOKEN_NOT_FOUND_OR_EXPIRED")
+	ErrCannotDeleteCurrentSession    = ErrorCode("CANNOT_DELETE_CURRENT_SESSION")
+	ErrUserNotFound                  = ErrorCode("USER_NOT_FOUND")
+	ErrUserIDDoesNotExist            = ErrorCode("USER_ID_DOES_NOT_EXIST")
+	ErrUserEmailAlreadyExists        = ErrorCode("USER_EMAIL_ALREADY_EXISTS")
+	ErrEmailPasswordMismatch         = ErrorCode("EMAIL_PASSWORD_MISMATCH")
+	ErrPasswordRequirementsNotMet    = ErrorCode("PASSWORD_REQUIREMENTS_NOT_MET")
+	ErrPasswordTooShort              = ErrorCode("PASSWORD_TOO_SHORT")
+	ErrPasswordTooLong               = ErrorCode("PASSWORD_TOO_LONG")
+	ErrPasswordConfirmationMismatch  = ErrorCode("PASSWORD_CONFIRMATION_MISMATCH")
+	ErrUserDoesNotHavePassword       = ErrorCode("USER_DOES_NOT_HAVE_PASSWORD")
+	ErrSignUpNotEnabled              = ErrorCode("SIGN_UP_NOT_ENABLED")
+	ErrVerificationCodeNotFound      = ErrorCode("VERIFICATION_CODE_NOT_FOUND")
+	ErrVerificationCodeExpired       = ErrorCode("VERIFICATION_CODE_EXPIRED")
+	ErrVerificationCodeAlreadyUsed   = ErrorCode("VERIFICATION_CODE_ALREADY_USED")
+	ErrMultiFactorAuthRequired       = ErrorCode("MULTI_FACTOR_AUTHENTICATION_REQUIRED")
+	ErrInvalidTOTPCode               = ErrorCode("INVALID_TOTP_CODE")
+	ErrEmailAlreadyVerified          = ErrorCode("EMAIL_ALREADY_VERIFIED")
+	ErrContactChannelNotFound        = ErrorCode("CONTACT_CHANNEL_NOT_FOUND")
+	ErrTeamNotFound                  = ErrorCode("TEAM_NOT_FOUND")
+	ErrTeamMembershipNotFound        = ErrorCode("TEAM_MEMBERSHIP_NOT_FOUND")
+	ErrTeamMembershipAlreadyExists   = ErrorCode("TEAM_MEMBERSHIP_ALREADY_EXISTS")
+	ErrTeamPermissionRequired        = ErrorCode("TEAM_PERMISSION_REQUIRED")
+	ErrTeamPermissionNotFound        = ErrorCode("TEAM_PERMISSION_NOT_FOUND")
+	ErrPermissionNotFound            = ErrorCode("PERMISSION_NOT_FOUND")
+	ErrInvalidAuthorizationCode      = ErrorCode("INVALID_AUTHORIZATION_CODE")
+)
@@ -0,0 +1,67 @@
+package base_http_client
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIError_KnownError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(KnownErrorHeader, "USER_NOT_FOUND")
+		w.Header().Set("X-Stack-Request-Id", "request-1")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":"USER_NOT_FOUND","error":"User not found."}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+	_, err := client.SendRequest("GET", "/users/unknown", nil, nil)
+
+	assert.True(t, errors.Is(err, ErrUserNotFound))
+	assert.False(t, errors.Is(err, ErrTeamNotFound))
+
+	var apiError *APIError
+	assert.True(t, errors.As(err, &apiError))
+	assert.Equal(t, http.StatusNotFound, apiError.StatusCode)
+	assert.Equal(t, "User not found.", apiError.Message)
+	assert.Equal(t, "request-1", apiError.RequestID)
+	assert.Equal(t, "USER_NOT_FOUND", apiError.Header.Get(KnownErrorHeader))
+	assert.Equal(t, "[USER_NOT_FOUND] User not found.", apiError.Error())
+}
+
+func TestAPIError_CodeFromHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(KnownErrorHeader, "TEAM_MEMBERSHIP_ALREADY_EXISTS")
+		w.WriteHeader(http.StatusConflict)
+		w.Write([]byte(`Team membership already exists`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+	_, err := client.SendRequest("POST", "/team-memberships/team/user", nil, nil)
+
+	assert.True(t, errors.Is(err, ErrTeamMembershipAlreadyExists))
+}
+
+func TestAPIError_NonJSONBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(`<html>Bad Gateway</html>`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+	_, err := client.SendRequest("GET", "/users", nil, nil)
+
+	var apiError *APIError
+	assert.True(t, errors.As(err, &apiError))
+	assert.Equal(t, http.StatusBadGateway, apiError.StatusCode)
+	assert.Empty(t, apiError.Code)
+	assert.Equal(t, `<html>Bad Gateway</html>`, string(apiError.Body))
+	assert.Equal(t, "request failed with status 502: <html>Bad Gateway</html>", apiError.Error())
+}
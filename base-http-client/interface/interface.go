@@ -1,7 +1,30 @@
 package _interface
 
-import "net/url"
+import (
+	"context"
+	"net/url"
+)
+
+// Request описывает запрос к API Stack Auth
+type Request struct {
+	// Operation - имя операции в формате "<пакет>.<метод>", например "users.GetUser"
+	Operation string
+	// Method - HTTP-метод запроса
+	Method string
+	// PathTemplate - шаблон пути без идентификаторов, например "/users/{user_id}".
+	// Если шаблон не задан, для запроса с Operation используется Path, а без Operation -
+	// значение "unknown" (UnknownPathTemplate), чтобы идентификаторы не попадали в метки
+	PathTemplate string
+	// Path - путь запроса относительно базового URL
+	Path string
+	// Query - параметры строки запроса
+	Query url.Values
+	// Body - тело запроса в формате JSON
+	Body []byte
+}
 
 type BaseHTTPClient interface {
-	SendRequest(method, path string, queryParams url.Values, body []byte) ([]byte, error)
+	SendRequest(method, path string, queryParams url.Values, body []byte, opts ...RequestOption) ([]byte, error)
+	SendRequestContext(ctx context.Context, method, path string, queryParams url.Values, body []byte, opts ...RequestOption) ([]byte, error)
+	Do(ctx context.Context, request *Request, opts ...RequestOption) ([]byte, error)
 }
@@ -0,0 +1,134 @@
+package _interface
+
+import (
+	"net/http"
+	"time"
+)
+
+// RequestOptions - параметры отдельного запроса, переопределяющие настройки клиента
+type RequestOptions struct {
+	// Timeout ограничивает время выполнения запроса, включая повторные попытки
+	Timeout time.Duration
+	// Header - дополнительные заголовки запроса
+	Header http.Header
+	// AccessToken заменяет токен доступа из конфигурации клиента
+	AccessToken string
+	// RefreshToken заменяет токен обновления из конфигурации клиента
+	RefreshToken string
+	// UserTokens сообщает, что AccessToken и RefreshToken заданы явно: токены из конфигурации клиента
+	// и хранилища токенов не используются, даже если AccessToken или RefreshToken пусты
+	UserTokens bool
+	// AccessType заменяет тип доступа из конфигурации клиента
+	AccessType string
+	// TokenStore заменяет хранилище токенов из конфигурации клиента
+	TokenStore TokenStore
+	// IdempotencyKey - ключ идемпотентности, разрешающий повтор неидемпотентного запроса
+	IdempotencyKey string
+	// BaseURL заменяет базовый URL API из конфигурации клиента
+	BaseURL string
+	// ResponseMeta заполняется метаданными ответа после выполнения запроса
+	ResponseMeta *ResponseMeta
+}
+
+// ResponseMeta - метаданные ответа API Stack Auth
+type ResponseMeta struct {
+	// StatusCode - HTTP-статус последней попытки. Равен 0, если ответ не получен
+	StatusCode int
+	// Header - заголовки ответа
+	Header http.Header
+	// RequestID - идентификатор запроса из заголовков ответа
+	RequestID string
+	// Duration - длительность операции, включая повторные попытки
+	Duration time.Duration
+	// Cached сообщает, что ответ получен из кэша клиента
+	Cached bool
+}
+
+// RequestOption изменяет параметры отдельного запроса
+type RequestOption func(*RequestOptions)
+
+// NewRequestOptions применяет опции и возвращает итоговые параметры запроса
+func NewRequestOptions(opts ...RequestOption) RequestOptions {
+	var options RequestOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+	return options
+}
+
+// WithTimeout ограничивает время выполнения запроса
+func WithTimeout(timeout time.Duration) RequestOption {
+	return func(o *RequestOptions) {
+		o.Timeout = timeout
+	}
+}
+
+// WithHeader добавляет заголовок к запросу
+func WithHeader(key, value string) RequestOption {
+	return func(o *RequestOptions) {
+		if o.Header == nil {
+			o.Header = http.Header{}
+		}
+		o.Header.Add(key, value)
+	}
+}
+
+// WithAccessToken выполняет запрос с указанным токеном доступа
+func WithAccessToken(accessToken string) RequestOption {
+	return func(o *RequestOptions) {
+		o.AccessToken = accessToken
+	}
+}
+
+// WithRefreshToken выполняет запрос с указанным токеном обновления
+func WithRefreshToken(refreshToken string) RequestOption {
+	return func(o *RequestOptions) {
+		o.RefreshToken = refreshToken
+	}
+}
+
+// WithUserTokens выполняет запрос только с указанными токенами пользователя
+func WithUserTokens(accessToken, refreshToken string) RequestOption {
+	return func(o *RequestOptions) {
+		o.AccessToken = accessToken
+		o.RefreshToken = refreshToken
+		o.UserTokens = true
+	}
+}
+
+// WithAccessType выполняет запрос с указанным типом доступа
+func WithAccessType(accessType string) RequestOption {
+	return func(o *RequestOptions) {
+		o.AccessType = accessType
+	}
+}
+
+// WithTokenStore выполняет запрос с токенами из указанного хранилища
+func WithTokenStore(store TokenStore) RequestOption {
+	return func(o *RequestOptions) {
+		o.TokenStore = store
+	}
+}
+
+// WithIdempotencyKey задает ключ идемпотентности запроса
+func WithIdempotencyKey(key string) RequestOption {
+	return func(o *RequestOptions) {
+		o.IdempotencyKey = key
+	}
+}
+
+// WithBaseURL выполняет запрос к указанному базовому URL
+func WithBaseURL(baseURL string) RequestOption {
+	return func(o *RequestOptions) {
+		o.BaseURL = baseURL
+	}
+}
+
+// WithResponseMeta заполняет meta метаданными ответа, в том числе при ошибке API
+func WithResponseMeta(meta *ResponseMeta) RequestOption {
+	return func(o *RequestOptions) {
+		o.ResponseMeta = meta
+	}
+}
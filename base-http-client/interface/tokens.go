@@ -0,0 +1,26 @@
+package _interface
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrTokenStoreSave возвращается вместе с телом ответа, если запрос выполнен,
+// но выданные токены не удалось сохранить в хранилище или удалить из него
+var ErrTokenStoreSave = errors.New("ошибка сохранения токенов в хранилище")
+
+// Tokens - токены пользователя
+type Tokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// TokenStore хранит токены пользователя между запросами
+type TokenStore interface {
+	// Load возвращает сохраненные токены. Если токенов нет, возвращает пустые Tokens без ошибки
+	Load(ctx context.Context) (Tokens, error)
+	// Save сохраняет токены, заменяя прежние
+	Save(ctx context.Context, tokens Tokens) error
+	// Clear удаляет сохраненные токены
+	Clear(ctx context.Context) error
+}
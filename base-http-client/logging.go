@@ -0,0 +1,275 @@
+package base_http_client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// RedactedValue - значение, которым заменяются секреты в логах
+const RedactedValue = "[REDACTED]"
+
+// sensitiveHeaders - заголовки, содержащие учетные данные
+var sensitiveHeaders = []string{
+	"Authorization",
+	"Cookie",
+	"Set-Cookie",
+	"X-Stack-Secret-Server-Key",
+	"X-Stack-Super-Secret-Admin-Key",
+	"X-Stack-Publishable-Client-Key",
+	"X-Stack-Access-Token",
+	"X-Stack-Refresh-Token",
+	"X-Stack-Auth",
+}
+
+// sensitiveJSONFields - поля тел запросов и ответов и параметры строки запроса, содержащие секреты.
+// Поле "code" в ответах не маскируется: оно содержит код ошибки Stack Auth (см. responseKeepFields)
+var sensitiveJSONFields = map[string]bool{
+	"password":           true,
+	"old_password":       true,
+	"new_password":       true,
+	"password_hash":      true,
+	"access_token":       true,
+	"refresh_token":      true,
+	"code":               true,
+	"totp":               true,
+	"totp_secret_base64": true,
+	"client_secret":      true,
+	"token":              true,
+}
+
+// responseKeepFields - поля, которые не маскируются в телах ответов: "code" в ответе содержит код ошибки Stack Auth,
+// а в запросе - одноразовый код OTP или OAuth
+var responseKeepFields = map[string]bool{"code": true}
+
+// LoggingConfig задает параметры логирования запросов через log/slog
+type LoggingConfig struct {
+	// Logger - логгер для записи сведений о запросах
+	Logger *slog.Logger
+	// Level - уровень записей об успешных запросах. По умолчанию slog.LevelDebug
+	Level slog.Leveler
+	// ErrorLevel - уровень записей о неуспешных запросах. По умолчанию slog.LevelWarn
+	ErrorLevel slog.Leveler
+	// LogHeaders включает запись заголовков запроса и ответа с маскировкой учетных данных
+	LogHeaders bool
+	// LogBodies включает запись тел запроса и ответа с маскировкой секретных полей
+	LogBodies bool
+}
+
+// RedactHeaders возвращает копию заголовков, в которой учетные данные заменены на RedactedValue
+func RedactHeaders(header http.Header) http.Header {
+	redacted := header.Clone()
+	for _, name := range sensitiveHeaders {
+		if redacted.Get(name) != "" {
+			redacted.Set(name, RedactedValue)
+		}
+	}
+	return redacted
+}
+
+// RedactJSON возвращает копию JSON-документа, в которой значения секретных полей заменены на RedactedValue.
+// Тело, не являющееся JSON, заменяется целиком.
+func RedactJSON(body []byte) []byte {
+	return redactJSON(body, nil)
+}
+
+// redactJSON маскирует секретные поля JSON-документа, кроме полей keep
+func redactJSON(body []byte, keep map[string]bool) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	var document interface{}
+	if err := json.Unmarshal(body, &document); err != nil {
+		return []byte(RedactedValue)
+	}
+	redacted, err := json.Marshal(redactValue(document, keep))
+	if err != nil {
+		return []byte(RedactedValue)
+	}
+	return redacted
+}
+
+// RedactURL возвращает копию URL, в которой значения секретных параметров строки запроса заменены на RedactedValue.
+// Строка, не являющаяся URL, заменяется целиком.
+func RedactURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return RedactedValue
+	}
+	if u.RawQuery == "" {
+		return rawURL
+	}
+	query := u.Query()
+	redacted := false
+	for key := range query {
+		if sensitiveJSONFields[strings.ToLower(key)] {
+			query[key] = []string{RedactedValue}
+			redacted = true
+		}
+	}
+	if !redacted {
+		return rawURL
+	}
+	u.RawQuery = strings.ReplaceAll(query.Encode(), url.QueryEscape(RedactedValue), RedactedValue)
+	return u.String()
+}
+
+// redactedError - ошибка, из текста которой удалены секреты. Unwrap возвращает исходную ошибку
+type redactedError struct {
+	message string
+	err     error
+}
+
+func (e *redactedError) Error() string {
+	return e.message
+}
+
+func (e *redactedError) Unwrap() error {
+	return e.err
+}
+
+// redactError маскирует секретные параметры URL запроса в ошибке транспорта (*url.Error).
+// Ошибка *url.Error заменяется копией с замаскированным URL, в обернутой ошибке маскируется текст.
+// Остальные ошибки возвращаются без изменений.
+func redactError(err error) error {
+	var urlError *url.Error
+	if !errors.As(err, &urlError) {
+		return err
+	}
+	redacted := RedactURL(urlError.URL)
+	if redacted == urlError.URL {
+		return err
+	}
+	if err == error(urlError) {
+		return &url.Error{Op: urlError.Op, URL: redacted, Err: urlError.Err}
+	}
+	return &redactedError{message: strings.ReplaceAll(err.Error(), urlError.URL, redacted), err: err}
+}
+
+// redactValue рекурсивно маскирует секретные поля JSON-значения, кроме полей keep
+func redactValue(value interface{}, keep map[string]bool) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			name := strings.ToLower(key)
+			if sensitiveJSONFields[name] && !keep[name] {
+				v[key] = RedactedValue
+				continue
+			}
+			v[key] = redactValue(item, keep)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = redactValue(item, keep)
+		}
+	}
+	return value
+}
+
+// loggingMiddleware создает Middleware, записывающий сведения о каждой попытке запроса
+func loggingMiddleware(cfg *LoggingConfig) Middleware {
+	level := slog.Leveler(slog.LevelDebug)
+	if cfg.Level != nil {
+		level = cfg.Level
+	}
+	errorLevel := slog.Leveler(slog.LevelWarn)
+	if cfg.ErrorLevel != nil {
+		errorLevel = cfg.ErrorLevel
+	}
+
+	return func(next Handler) Handler {
+		return func(op Operation, req *http.Request) (*http.Response, error) {
+			var requestBody []byte
+			if cfg.LogBodies && req.GetBody != nil {
+				if body, err := req.GetBody(); err == nil {
+					requestBody, _ = io.ReadAll(body)
+					body.Close()
+				}
+			}
+
+			start := time.Now()
+			resp, err := next(op, req)
+			duration := time.Since(start)
+
+			attrs := []slog.Attr{
+				slog.String("operation", op.Name),
+				slog.String("method", req.Method),
+				slog.String("path_template", op.PathTemplate),
+				slog.Duration("duration", duration),
+			}
+			if cfg.LogHeaders {
+				attrs = append(attrs, slog.Any("request_headers", RedactHeaders(req.Header)))
+			}
+			if cfg.LogBodies && len(requestBody) > 0 {
+				attrs = append(attrs, slog.String("request_body", string(RedactJSON(requestBody))))
+			}
+
+			recordLevel := level.Level()
+			if err != nil {
+				recordLevel = errorLevel.Level()
+				attrs = append(attrs, slog.String("error", redactError(err).Error()))
+				cfg.Logger.LogAttrs(req.Context(), recordLevel, "stack auth request failed", attrs...)
+				return resp, err
+			}
+
+			var responseBody []byte
+			if cfg.LogBodies || resp.StatusCode >= 400 {
+				responseBody, resp.Body = peekBody(resp.Body)
+			}
+
+			attrs = append(attrs, slog.Int("status", resp.StatusCode))
+			if resp.StatusCode >= 400 {
+				recordLevel = errorLevel.Level()
+				if code := errorCodeOf(resp, responseBody); code != "" {
+					attrs = append(attrs, slog.String("error_code", code))
+				}
+			}
+			if cfg.LogHeaders {
+				attrs = append(attrs, slog.Any("response_headers", RedactHeaders(resp.Header)))
+			}
+			if cfg.LogBodies && len(responseBody) > 0 {
+				attrs = append(attrs, slog.String("response_body", string(redactJSON(responseBody, responseKeepFields))))
+			}
+
+			cfg.Logger.LogAttrs(req.Context(), recordLevel, "stack auth request", attrs...)
+			return resp, err
+		}
+	}
+}
+
+// peekBody читает тело ответа и возвращает его копию вместе с новым Reader для дальнейшей обработки
+func peekBody(body io.ReadCloser) ([]byte, io.ReadCloser) {
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		return data, io.NopCloser(io.MultiReader(bytes.NewReader(data), errReader{err}))
+	}
+	return data, io.NopCloser(bytes.NewReader(data))
+}
+
+// errReader возвращает ошибку чтения, возникшую при предварительном чтении тела
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+// errorCodeOf извлекает код ошибки Stack Auth из заголовков или тела ответа
+func errorCodeOf(resp *http.Response, body []byte) string {
+	if code := resp.Header.Get(KnownErrorHeader); code != "" {
+		return code
+	}
+	var apiError APIError
+	if err := json.Unmarshal(body, &apiError); err == nil {
+		return apiError.Code
+	}
+	return ""
+}
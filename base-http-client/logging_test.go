@@ -0,0 +1,211 @@
+package base_http_client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/BlaisePopov/stack-auth/base-http-client/tracing"
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeLogRecords(t *testing.T, buffer *bytes.Buffer) []map[string]interface{} {
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buffer.String()), "\n") {
+		record := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLogging_RedactsCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"access_token":"access-secret","refresh_token":"refresh-secret","user_id":"user-1"}`))
+	}))
+	defer server.Close()
+
+	var buffer bytes.Buffer
+	client := NewClient(Config{
+		BaseURL:         server.URL,
+		ProjectID:       "project-id",
+		SecretServerKey: "server-secret",
+		AccessToken:     "header-access-secret",
+		Logging: &LoggingConfig{
+			Logger:     slog.New(slog.NewJSONHandler(&buffer, &slog.HandlerOptions{Level: slog.LevelDebug})),
+			LogHeaders: true,
+			LogBodies:  true,
+		},
+	})
+
+	response, err := client.Do(context.Background(), &Request{
+		Operation: "password.SignInWithEmail",
+		Method:    "POST",
+		Path:      "/auth/password/sign-in",
+		Body:      []byte(`{"email":"johndoe@example.com","password":"p@ssw0rd"}`),
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, string(response), "access-secret")
+
+	output := buffer.String()
+	for _, secret := range []string{"server-secret", "header-access-secret", "p@ssw0rd", "access-secret", "refresh-secret"} {
+		assert.NotContains(t, output, secret)
+	}
+
+	records := decodeLogRecords(t, &buffer)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "DEBUG", records[0]["level"])
+	assert.Equal(t, "password.SignInWithEmail", records[0]["operation"])
+	assert.Equal(t, "POST", records[0]["method"])
+	assert.Equal(t, "/auth/password/sign-in", records[0]["path_template"])
+	assert.Equal(t, float64(http.StatusOK), records[0]["status"])
+	assert.Contains(t, records[0]["request_body"], "johndoe@example.com")
+	assert.Contains(t, records[0]["response_body"], "user-1")
+}
+
+func TestLogging_ErrorCodeAndLevel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":"EMAIL_PASSWORD_MISMATCH","error":"Wrong e-mail or password."}`))
+	}))
+	defer server.Close()
+
+	var buffer bytes.Buffer
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		Logging: &LoggingConfig{
+			Logger:     slog.New(slog.NewJSONHandler(&buffer, nil)),
+			ErrorLevel: slog.LevelError,
+		},
+	})
+
+	_, err := client.Do(context.Background(), &Request{
+		Operation: "password.SignInWithEmail",
+		Method:    "POST",
+		Path:      "/auth/password/sign-in",
+		Body:      []byte(`{"email":"johndoe@example.com","password":"wrong"}`),
+	})
+	assert.ErrorIs(t, err, ErrEmailPasswordMismatch)
+
+	records := decodeLogRecords(t, &buffer)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "ERROR", records[0]["level"])
+	assert.Equal(t, "EMAIL_PASSWORD_MISMATCH", records[0]["error_code"])
+	assert.Equal(t, float64(http.StatusBadRequest), records[0]["status"])
+	assert.NotContains(t, buffer.String(), "wrong")
+}
+
+func TestLogging_SuccessBelowLoggerLevelIsSkipped(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var buffer bytes.Buffer
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		Logging: &LoggingConfig{Logger: slog.New(slog.NewJSONHandler(&buffer, nil))},
+	})
+
+	_, err := client.SendRequest("GET", "/users", nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, buffer.String())
+}
+
+func TestLogging_RedactsTransportErrorURL(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	var buffer bytes.Buffer
+	exporter := tracing.NewInMemoryExporter()
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		Logging: &LoggingConfig{Logger: slog.New(slog.NewJSONHandler(&buffer, nil))},
+		Tracer:  tracing.NewTracer(exporter),
+	})
+
+	_, err := client.Do(context.Background(), &Request{
+		Operation: "oauth.Authorize",
+		Method:    "GET",
+		Path:      "/auth/oauth/authorize/github",
+		Query:     url.Values{"client_id": {"client-1"}, "client_secret": {"TOPSECRET"}, "token": {"USERTOKEN"}},
+	})
+	var urlError *url.Error
+	assert.ErrorAs(t, err, &urlError)
+	assert.NotContains(t, err.Error(), "TOPSECRET")
+	assert.NotContains(t, err.Error(), "USERTOKEN")
+	assert.Contains(t, urlError.URL, "client_id=client-1")
+
+	output := buffer.String()
+	assert.NotContains(t, output, "TOPSECRET")
+	assert.NotContains(t, output, "USERTOKEN")
+	assert.Contains(t, output, "client-1")
+
+	spans := exporter.Spans()
+	if assert.Len(t, spans, 1) {
+		assert.NotContains(t, spans[0].Err.Error(), "TOPSECRET")
+		assert.ErrorAs(t, spans[0].Err, &urlError)
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	assert.Equal(t, "https://example.com/a?client_secret=[REDACTED]&scope=openid", RedactURL("https://example.com/a?client_secret=s&scope=openid"))
+	assert.Equal(t, "https://example.com/a?scope=openid", RedactURL("https://example.com/a?scope=openid"))
+}
+
+func TestRedactJSON_Nested(t *testing.T) {
+	redacted := RedactJSON([]byte(`{"items":[{"code":"123456","email":"a@b.c"}],"old_password":"x"}`))
+	assert.JSONEq(t, `{"items":[{"code":"[REDACTED]","email":"a@b.c"}],"old_password":"[REDACTED]"}`, string(redacted))
+	assert.Equal(t, RedactedValue, string(RedactJSON([]byte("not json"))))
+}
+
+func TestRedactHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Stack-Super-Secret-Admin-Key", "admin-secret")
+	header.Set("X-Stack-Project-Id", "project-id")
+	header.Set("X-Stack-Access-Token", "")
+
+	redacted := RedactHeaders(header)
+	assert.Equal(t, RedactedValue, redacted.Get("X-Stack-Super-Secret-Admin-Key"))
+	assert.Equal(t, "project-id", redacted.Get("X-Stack-Project-Id"))
+	assert.Equal(t, "", redacted.Get("X-Stack-Access-Token"))
+	assert.Equal(t, "admin-secret", header.Get("X-Stack-Super-Secret-Admin-Key"))
+}
+
+func TestLogging_KeepsErrorCodeInResponseBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":"VERIFICATION_CODE_NOT_FOUND","error":"Verification code not found."}`))
+	}))
+	defer server.Close()
+
+	var buffer bytes.Buffer
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		Logging: &LoggingConfig{Logger: slog.New(slog.NewJSONHandler(&buffer, nil)), LogBodies: true},
+	})
+
+	_, err := client.Do(context.Background(), &Request{
+		Operation: "otp.SignInWithCode",
+		Method:    "POST",
+		Path:      "/auth/otp/sign-in",
+		Body:      []byte(`{"code":"123456"}`),
+	})
+	assert.Error(t, err)
+
+	records := decodeLogRecords(t, &buffer)
+	if assert.Len(t, records, 1) {
+		assert.NotContains(t, records[0]["request_body"], "123456")
+		assert.Contains(t, records[0]["response_body"], "VERIFICATION_CODE_NOT_FOUND")
+	}
+}
@@ -0,0 +1,60 @@
+package base_http_client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Коды ошибок в метриках для сбоев, не связанных с ответом Stack Auth
+const (
+	MetricsErrorCanceled    = "CANCELED"
+	MetricsErrorTransport   = "TRANSPORT_ERROR"
+	MetricsErrorRateLimited = "CLIENT_RATE_LIMITED"
+	MetricsErrorCircuitOpen = "CIRCUIT_OPEN"
+)
+
+// UnknownPathTemplate - шаблон пути в метках, логах и спанах запросов без операции, например через SendRequest
+const UnknownPathTemplate = "unknown"
+
+// MetricLabels - метки метрик операции. Содержат шаблон пути, а не фактический путь с идентификаторами
+type MetricLabels struct {
+	Operation    string
+	Method       string
+	PathTemplate string
+}
+
+// MetricsRecorder получает метрики запросов к Stack Auth
+type MetricsRecorder interface {
+	// RequestStarted вызывается перед выполнением операции
+	RequestStarted(labels MetricLabels)
+	// RequestFinished вызывается после завершения операции, включая все повторные попытки.
+	// status равен 0, если ответ не получен; errorCode пуст для успешных операций
+	RequestFinished(labels MetricLabels, status int, errorCode string, duration time.Duration)
+}
+
+// metricsErrorCode возвращает код ошибки для метрик
+func metricsErrorCode(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var apiError *APIError
+	if errors.As(err, &apiError) {
+		if apiError.Code != "" {
+			return apiError.Code
+		}
+		return fmt.Sprintf("HTTP_%d", apiError.StatusCode)
+	}
+	if errors.Is(err, ErrCircuitOpen) {
+		return MetricsErrorCircuitOpen
+	}
+	if errors.Is(err, ErrRateLimited) {
+		return MetricsErrorRateLimited
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return MetricsErrorCanceled
+	}
+	return MetricsErrorTransport
+}
@@ -0,0 +1,205 @@
+// Package metrics содержит адаптеры base_http_client.MetricsRecorder.
+//
+// PrometheusRecorder накапливает метрики в памяти и отдает их в текстовом формате
+// Prometheus, поэтому может быть подключен как обработчик эндпоинта /metrics.
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+// DefaultBuckets - границы корзин гистограммы длительности запросов в секундах
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// ContentType - тип содержимого текстового формата Prometheus
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+const namespace = "stackauth_client"
+
+type requestKey struct {
+	labels base_http_client.MetricLabels
+	status int
+}
+
+type errorKey struct {
+	labels    base_http_client.MetricLabels
+	errorCode string
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// PrometheusRecorder реализует base_http_client.MetricsRecorder и отдает метрики в формате Prometheus
+type PrometheusRecorder struct {
+	mu        sync.Mutex
+	buckets   []float64
+	requests  map[requestKey]uint64
+	errors    map[errorKey]uint64
+	durations map[base_http_client.MetricLabels]*histogram
+	inFlight  map[base_http_client.MetricLabels]int64
+}
+
+// NewPrometheusRecorder создает PrometheusRecorder с указанными границами корзин гистограммы.
+// При пустом списке используются DefaultBuckets
+func NewPrometheusRecorder(buckets ...float64) *PrometheusRecorder {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+
+	return &PrometheusRecorder{
+		buckets:   buckets,
+		requests:  map[requestKey]uint64{},
+		errors:    map[errorKey]uint64{},
+		durations: map[base_http_client.MetricLabels]*histogram{},
+		inFlight:  map[base_http_client.MetricLabels]int64{},
+	}
+}
+
+// RequestStarted увеличивает число выполняющихся запросов
+func (r *PrometheusRecorder) RequestStarted(labels base_http_client.MetricLabels) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.inFlight[labels]++
+}
+
+// RequestFinished учитывает завершенную операцию
+func (r *PrometheusRecorder) RequestFinished(labels base_http_client.MetricLabels, status int, errorCode string, duration time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.inFlight[labels]--
+	r.requests[requestKey{labels: labels, status: status}]++
+	if errorCode != "" {
+		r.errors[errorKey{labels: labels, errorCode: errorCode}]++
+	}
+
+	h, ok := r.durations[labels]
+	if !ok {
+		h = &histogram{counts: make([]uint64, len(r.buckets))}
+		r.durations[labels] = h
+	}
+	seconds := duration.Seconds()
+	for i, bound := range r.buckets {
+		if seconds <= bound {
+			h.counts[i]++
+		}
+	}
+	h.sum += seconds
+	h.count++
+}
+
+// WriteTo записывает метрики в текстовом формате Prometheus
+func (r *PrometheusRecorder) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	var buffer bytes.Buffer
+	r.writeRequests(&buffer)
+	r.writeErrors(&buffer)
+	r.writeInFlight(&buffer)
+	r.writeDurations(&buffer)
+	r.mu.Unlock()
+
+	return buffer.WriteTo(w)
+}
+
+// ServeHTTP отдает метрики в текстовом формате Prometheus
+func (r *PrometheusRecorder) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+	r.WriteTo(w)
+}
+
+func (r *PrometheusRecorder) writeRequests(buffer *bytes.Buffer) {
+	name := namespace + "_requests_total"
+	writeHeader(buffer, name, "counter", "Total number of Stack Auth operations.")
+
+	lines := make([]string, 0, len(r.requests))
+	for key, value := range r.requests {
+		lines = append(lines, fmt.Sprintf("%s{%s,status=\"%d\"} %d\n", name, formatLabels(key.labels), key.status, value))
+	}
+	writeSorted(buffer, lines)
+}
+
+func (r *PrometheusRecorder) writeErrors(buffer *bytes.Buffer) {
+	name := namespace + "_errors_total"
+	writeHeader(buffer, name, "counter", "Total number of failed Stack Auth operations by error code.")
+
+	lines := make([]string, 0, len(r.errors))
+	for key, value := range r.errors {
+		lines = append(lines, fmt.Sprintf("%s{%s,error_code=\"%s\"} %d\n", name, formatLabels(key.labels), escapeLabel(key.errorCode), value))
+	}
+	writeSorted(buffer, lines)
+}
+
+func (r *PrometheusRecorder) writeInFlight(buffer *bytes.Buffer) {
+	name := namespace + "_requests_in_flight"
+	writeHeader(buffer, name, "gauge", "Number of Stack Auth operations in progress.")
+
+	lines := make([]string, 0, len(r.inFlight))
+	for labels, value := range r.inFlight {
+		lines = append(lines, fmt.Sprintf("%s{%s} %d\n", name, formatLabels(labels), value))
+	}
+	writeSorted(buffer, lines)
+}
+
+func (r *PrometheusRecorder) writeDurations(buffer *bytes.Buffer) {
+	name := namespace + "_request_duration_seconds"
+	writeHeader(buffer, name, "histogram", "Duration of Stack Auth operations in seconds.")
+
+	keys := make([]base_http_client.MetricLabels, 0, len(r.durations))
+	for labels := range r.durations {
+		keys = append(keys, labels)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return formatLabels(keys[i]) < formatLabels(keys[j])
+	})
+
+	for _, labels := range keys {
+		h := r.durations[labels]
+		formatted := formatLabels(labels)
+		for i, bound := range r.buckets {
+			fmt.Fprintf(buffer, "%s_bucket{%s,le=\"%s\"} %d\n", name, formatted, strconv.FormatFloat(bound, 'g', -1, 64), h.counts[i])
+		}
+		fmt.Fprintf(buffer, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, formatted, h.count)
+		fmt.Fprintf(buffer, "%s_sum{%s} %s\n", name, formatted, strconv.FormatFloat(h.sum, 'g', -1, 64))
+		fmt.Fprintf(buffer, "%s_count{%s} %d\n", name, formatted, h.count)
+	}
+}
+
+func writeHeader(buffer *bytes.Buffer, name, metricType, help string) {
+	fmt.Fprintf(buffer, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(buffer, "# TYPE %s %s\n", name, metricType)
+}
+
+func writeSorted(buffer *bytes.Buffer, lines []string) {
+	sort.Strings(lines)
+	for _, line := range lines {
+		buffer.WriteString(line)
+	}
+}
+
+// formatLabels форматирует метки операции в синтаксисе Prometheus
+func formatLabels(labels base_http_client.MetricLabels) string {
+	return fmt.Sprintf("operation=\"%s\",method=\"%s\",path_template=\"%s\"",
+		escapeLabel(labels.Operation), escapeLabel(labels.Method), escapeLabel(labels.PathTemplate))
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// escapeLabel экранирует значение метки
+func escapeLabel(value string) string {
+	return labelEscaper.Replace(value)
+}
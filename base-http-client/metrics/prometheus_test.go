@@ -0,0 +1,62 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheusRecorder_WriteTo(t *testing.T) {
+	recorder := NewPrometheusRecorder(0.1, 1)
+	labels := base_http_client.MetricLabels{Operation: "users.GetUser", Method: "GET", PathTemplate: "/users/{user_id}"}
+
+	recorder.RequestStarted(labels)
+	recorder.RequestFinished(labels, http.StatusOK, "", 50*time.Millisecond)
+	recorder.RequestStarted(labels)
+	recorder.RequestFinished(labels, http.StatusNotFound, "USER_NOT_FOUND", 500*time.Millisecond)
+	recorder.RequestStarted(labels)
+
+	var output strings.Builder
+	_, err := recorder.WriteTo(&output)
+	assert.NoError(t, err)
+
+	expected := `# HELP stackauth_client_requests_total Total number of Stack Auth operations.
+# TYPE stackauth_client_requests_total counter
+stackauth_client_requests_total{operation="users.GetUser",method="GET",path_template="/users/{user_id}",status="200"} 1
+stackauth_client_requests_total{operation="users.GetUser",method="GET",path_template="/users/{user_id}",status="404"} 1
+# HELP stackauth_client_errors_total Total number of failed Stack Auth operations by error code.
+# TYPE stackauth_client_errors_total counter
+stackauth_client_errors_total{operation="users.GetUser",method="GET",path_template="/users/{user_id}",error_code="USER_NOT_FOUND"} 1
+# HELP stackauth_client_requests_in_flight Number of Stack Auth operations in progress.
+# TYPE stackauth_client_requests_in_flight gauge
+stackauth_client_requests_in_flight{operation="users.GetUser",method="GET",path_template="/users/{user_id}"} 1
+# HELP stackauth_client_request_duration_seconds Duration of Stack Auth operations in seconds.
+# TYPE stackauth_client_request_duration_seconds histogram
+stackauth_client_request_duration_seconds_bucket{operation="users.GetUser",method="GET",path_template="/users/{user_id}",le="0.1"} 1
+stackauth_client_request_duration_seconds_bucket{operation="users.GetUser",method="GET",path_template="/users/{user_id}",le="1"} 2
+stackauth_client_request_duration_seconds_bucket{operation="users.GetUser",method="GET",path_template="/users/{user_id}",le="+Inf"} 2
+stackauth_client_request_duration_seconds_sum{operation="users.GetUser",method="GET",path_template="/users/{user_id}"} 0.55
+stackauth_client_request_duration_seconds_count{operation="users.GetUser",method="GET",path_template="/users/{user_id}"} 2
+`
+	assert.Equal(t, expected, output.String())
+}
+
+func TestPrometheusRecorder_ServeHTTP(t *testing.T) {
+	recorder := NewPrometheusRecorder()
+	recorder.RequestStarted(base_http_client.MetricLabels{Operation: "teams.GetTeam", Method: "GET", PathTemplate: "/teams/{team_id}"})
+
+	response := httptest.NewRecorder()
+	recorder.ServeHTTP(response, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, ContentType, response.Header().Get("Content-Type"))
+	assert.Contains(t, response.Body.String(), `stackauth_client_requests_in_flight{operation="teams.GetTeam",method="GET",path_template="/teams/{team_id}"} 1`)
+}
+
+func TestEscapeLabel(t *testing.T) {
+	assert.Equal(t, `a\"b\\c\nd`, escapeLabel("a\"b\\c\nd"))
+}
@@ -0,0 +1,108 @@
+package base_http_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordedMetric struct {
+	labels    MetricLabels
+	status    int
+	errorCode string
+}
+
+type fakeMetricsRecorder struct {
+	mu       sync.Mutex
+	started  []MetricLabels
+	finished []recordedMetric
+}
+
+func (r *fakeMetricsRecorder) RequestStarted(labels MetricLabels) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.started = append(r.started, labels)
+}
+
+func (r *fakeMetricsRecorder) RequestFinished(labels MetricLabels, status int, errorCode string, _ time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.finished = append(r.finished, recordedMetric{labels: labels, status: status, errorCode: errorCode})
+}
+
+func TestMetrics_LabelsUsePathTemplate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/users/unknown" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":"USER_NOT_FOUND","error":"User not found."}`))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	recorder := &fakeMetricsRecorder{}
+	client := NewClient(Config{BaseURL: server.URL, Metrics: recorder})
+
+	for _, id := range []string{"known", "unknown"} {
+		client.Do(context.Background(), &Request{
+			Operation:    "users.GetUser",
+			Method:       "GET",
+			PathTemplate: "/users/{user_id}",
+			Path:         "/users/" + id,
+		})
+	}
+
+	labels := MetricLabels{Operation: "users.GetUser", Method: "GET", PathTemplate: "/users/{user_id}"}
+	assert.Equal(t, []MetricLabels{labels, labels}, recorder.started)
+	assert.Equal(t, []recordedMetric{
+		{labels: labels, status: http.StatusOK},
+		{labels: labels, status: http.StatusNotFound, errorCode: "USER_NOT_FOUND"},
+	}, recorder.finished)
+}
+
+func TestMetrics_SendRequestUsesUnknownPathTemplate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	recorder := &fakeMetricsRecorder{}
+	client := NewClient(Config{BaseURL: server.URL, Metrics: recorder})
+	_, err := client.SendRequest("GET", "/users/user-42", nil, nil)
+	assert.NoError(t, err)
+	_, err = client.Do(context.Background(), &Request{Operation: "projects.GetCurrentProject", Method: "GET", Path: "/projects/current"})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []MetricLabels{
+		{Method: "GET", PathTemplate: UnknownPathTemplate},
+		{Operation: "projects.GetCurrentProject", Method: "GET", PathTemplate: "/projects/current"},
+	}, recorder.started)
+}
+
+func TestMetrics_ErrorCodeWithoutResponse(t *testing.T) {
+	recorder := &fakeMetricsRecorder{}
+	client := NewClient(Config{BaseURL: "http://127.0.0.1:0", Metrics: recorder})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	client.SendRequestContext(ctx, "GET", "/users", nil, nil)
+	client.SendRequest("GET", "/users", nil, nil)
+
+	assert.Len(t, recorder.finished, 2)
+	assert.Equal(t, 0, recorder.finished[0].status)
+	assert.Equal(t, MetricsErrorCanceled, recorder.finished[0].errorCode)
+	assert.Equal(t, MetricsErrorTransport, recorder.finished[1].errorCode)
+}
+
+func TestMetricsErrorCode_StatusFallback(t *testing.T) {
+	assert.Equal(t, "", metricsErrorCode(nil))
+	assert.Equal(t, "HTTP_502", metricsErrorCode(&APIError{StatusCode: http.StatusBadGateway}))
+	assert.Equal(t, MetricsErrorRateLimited, metricsErrorCode(ErrRateLimited))
+}
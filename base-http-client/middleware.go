@@ -0,0 +1,51 @@
+package base_http_client
+
+import "net/http"
+
+// Operation описывает вызываемую операцию Stack Auth
+type Operation struct {
+	// Name - имя операции, например "users.GetUser". Пустое для запросов через SendRequest
+	Name string
+	// PathTemplate - шаблон пути без идентификаторов, например "/users/{user_id}"
+	PathTemplate string
+}
+
+// Handler выполняет HTTP-запрос в рамках операции Stack Auth
+type Handler func(op Operation, req *http.Request) (*http.Response, error)
+
+// Middleware оборачивает Handler, добавляя обработку до и после выполнения запроса.
+// Middleware вызывается для каждой попытки запроса, включая повторные.
+type Middleware func(next Handler) Handler
+
+// RequestMutator создает Middleware, изменяющий запрос перед отправкой.
+// Ошибка, возвращенная mutate, прерывает запрос.
+func RequestMutator(mutate func(op Operation, req *http.Request) error) Middleware {
+	return func(next Handler) Handler {
+		return func(op Operation, req *http.Request) (*http.Response, error) {
+			if err := mutate(op, req); err != nil {
+				return nil, err
+			}
+			return next(op, req)
+		}
+	}
+}
+
+// ResponseObserver создает Middleware, получающий результат каждой попытки запроса.
+// Наблюдатель не должен читать или закрывать тело ответа.
+func ResponseObserver(observe func(op Operation, req *http.Request, resp *http.Response, err error)) Middleware {
+	return func(next Handler) Handler {
+		return func(op Operation, req *http.Request) (*http.Response, error) {
+			resp, err := next(op, req)
+			observe(op, req, resp, err)
+			return resp, err
+		}
+	}
+}
+
+// chainMiddlewares собирает цепочку обработчиков. Первый Middleware в списке является внешним.
+func chainMiddlewares(middlewares []Middleware, handler Handler) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
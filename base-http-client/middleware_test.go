@@ -0,0 +1,136 @@
+package base_http_client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware_OrderAndOperation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "tenant-1", r.Header.Get("X-Tenant-Id"))
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	var calls []string
+	trace := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(op Operation, req *http.Request) (*http.Response, error) {
+				calls = append(calls, name+":before")
+				resp, err := next(op, req)
+				calls = append(calls, name+":after")
+				return resp, err
+			}
+		}
+	}
+
+	var observed Operation
+	var observedStatus int
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		Middlewares: []Middleware{
+			trace("outer"),
+			RequestMutator(func(op Operation, req *http.Request) error {
+				req.Header.Set("X-Tenant-Id", "tenant-1")
+				return nil
+			}),
+			ResponseObserver(func(op Operation, req *http.Request, resp *http.Response, err error) {
+				observed = op
+				observedStatus = resp.StatusCode
+			}),
+			trace("inner"),
+		},
+	})
+
+	_, err := client.Do(context.Background(), &Request{
+		Operation:    "users.GetUser",
+		Method:       "GET",
+		PathTemplate: "/users/{user_id}",
+		Path:         "/users/user-1",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"outer:before", "inner:before", "inner:after", "outer:after"}, calls)
+	assert.Equal(t, Operation{Name: "users.GetUser", PathTemplate: "/users/{user_id}"}, observed)
+	assert.Equal(t, http.StatusOK, observedStatus)
+}
+
+func TestMiddleware_PathTemplateDefaultsToPath(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var observed Operation
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		Middlewares: []Middleware{
+			ResponseObserver(func(op Operation, req *http.Request, resp *http.Response, err error) {
+				observed = op
+			}),
+		},
+	})
+
+	_, err := client.Do(context.Background(), &Request{Operation: "projects.GetCurrentProject", Method: "GET", Path: "/projects/current"})
+	assert.NoError(t, err)
+	assert.Equal(t, Operation{Name: "projects.GetCurrentProject", PathTemplate: "/projects/current"}, observed)
+
+	_, err = client.SendRequest("GET", "/users/user-42", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Operation{PathTemplate: UnknownPathTemplate}, observed)
+}
+
+func TestMiddleware_MutatorErrorAbortsRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("запрос не должен быть отправлен")
+	}))
+	defer server.Close()
+
+	injected := errors.New("injected fault")
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		Middlewares: []Middleware{
+			RequestMutator(func(op Operation, req *http.Request) error {
+				return injected
+			}),
+		},
+	})
+
+	_, err := client.SendRequest("GET", "/users", nil, nil)
+	assert.True(t, errors.Is(err, injected))
+}
+
+func TestMiddleware_FaultInjectionWithRetry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+
+	attempts := 0
+	client := NewClient(Config{
+		BaseURL:     server.URL,
+		RetryPolicy: testRetryPolicy(),
+		Middlewares: []Middleware{
+			func(next Handler) Handler {
+				return func(op Operation, req *http.Request) (*http.Response, error) {
+					attempts++
+					if attempts == 1 {
+						return nil, errors.New("connection reset")
+					}
+					return next(op, req)
+				}
+			},
+		},
+	})
+
+	response, err := client.SendRequest("GET", "/users", nil, nil)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"success":true}`, string(response))
+	assert.Equal(t, 2, attempts)
+}
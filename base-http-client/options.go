@@ -0,0 +1,75 @@
+package base_http_client
+
+import (
+	"time"
+
+	_interface "github.com/BlaisePopov/stack-auth/base-http-client/interface"
+)
+
+// RequestOption изменяет параметры отдельного запроса. Опции принимают все методы пакетов api/*
+type RequestOption = _interface.RequestOption
+
+// RequestOptions - параметры отдельного запроса, переопределяющие настройки клиента
+type RequestOptions = _interface.RequestOptions
+
+// ResponseMeta - метаданные ответа: статус, заголовки, идентификатор запроса и длительность
+type ResponseMeta = _interface.ResponseMeta
+
+// WithTimeout ограничивает время выполнения запроса, включая повторные попытки
+func WithTimeout(timeout time.Duration) RequestOption {
+	return _interface.WithTimeout(timeout)
+}
+
+// WithHeader добавляет заголовок к запросу. Заголовок применяется после заголовков клиента
+func WithHeader(key, value string) RequestOption {
+	return _interface.WithHeader(key, value)
+}
+
+// WithAccessToken выполняет запрос с указанным токеном доступа вместо Config.AccessToken
+func WithAccessToken(accessToken string) RequestOption {
+	return _interface.WithAccessToken(accessToken)
+}
+
+// WithRefreshToken выполняет запрос с указанным токеном обновления вместо Config.RefreshToken
+func WithRefreshToken(refreshToken string) RequestOption {
+	return _interface.WithRefreshToken(refreshToken)
+}
+
+// WithUserTokens выполняет запрос от имени пользователя только с указанными токенами.
+// В отличие от WithAccessToken и WithRefreshToken, пустой токен не заменяется токеном из Config
+// или хранилища токенов, поэтому токены другого пользователя в запрос не попадают.
+// Config.TokenStore в таком запросе не используется: выданные операцией токены в него не сохраняются
+func WithUserTokens(accessToken, refreshToken string) RequestOption {
+	return _interface.WithUserTokens(accessToken, refreshToken)
+}
+
+// WithAccessType выполняет запрос с указанным типом доступа вместо Config.AccessType.
+// Ключи, обязательные для этого типа доступа, должны быть заданы в Config. Ключи, не относящиеся
+// к типу доступа, в запрос не передаются, поэтому серверный клиент может выполнить запрос с типом "client"
+func WithAccessType(accessType string) RequestOption {
+	return _interface.WithAccessType(accessType)
+}
+
+// WithTokenStore выполняет запрос с токенами из store вместо Config.TokenStore и сохраняет в store
+// токены, выданные операцией. Подходит для хранилищ, привязанных к входящему запросу, например CookieTokenStore
+func WithTokenStore(store TokenStore) RequestOption {
+	return _interface.WithTokenStore(store)
+}
+
+// WithIdempotencyKey задает ключ идемпотентности запроса, аналогично ContextWithIdempotencyKey
+func WithIdempotencyKey(key string) RequestOption {
+	return _interface.WithIdempotencyKey(key)
+}
+
+// WithBaseURL выполняет запрос к указанному базовому URL вместо Config.BaseURL
+func WithBaseURL(baseURL string) RequestOption {
+	return _interface.WithBaseURL(baseURL)
+}
+
+// WithResponseMeta заполняет meta метаданными ответа, в том числе при ошибке API.
+//
+//	var meta base_http_client.ResponseMeta
+//	user, err := stackAuth.Users.GetUser(userID, base_http_client.WithResponseMeta(&meta))
+func WithResponseMeta(meta *ResponseMeta) RequestOption {
+	return _interface.WithResponseMeta(meta)
+}
@@ -0,0 +1,112 @@
+package base_http_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestOptions_OverrideCredentialsAndHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "user-access-token", r.Header.Get("X-Stack-Access-Token"))
+		assert.Equal(t, "user-refresh-token", r.Header.Get("X-Stack-Refresh-Token"))
+		assert.Equal(t, "tenant-42", r.Header.Get("X-Tenant-Id"))
+		assert.Equal(t, "server-secret", r.Header.Get("X-Stack-Secret-Server-Key"))
+		assert.Equal(t, "create-user-1", r.Header.Get(IdempotencyKeyHeader))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL:         server.URL,
+		SecretServerKey: "server-secret",
+		AccessToken:     "shared-access-token",
+	})
+
+	_, err := client.SendRequest("POST", "/users", nil, []byte(`{}`),
+		WithAccessToken("user-access-token"),
+		WithRefreshToken("user-refresh-token"),
+		WithHeader("X-Tenant-Id", "tenant-42"),
+		WithIdempotencyKey("create-user-1"),
+	)
+	assert.NoError(t, err)
+}
+
+func TestRequestOptions_AccessType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "client", r.Header.Get("X-Stack-Access-Type"))
+		assert.Equal(t, "publishable-key", r.Header.Get("X-Stack-Publishable-Client-Key"))
+		assert.NotContains(t, r.Header, "X-Stack-Secret-Server-Key")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL:              server.URL,
+		ProjectID:            "project-id",
+		SecretServerKey:      "server-secret",
+		PublishableClientKey: "publishable-key",
+	})
+	_, err := client.SendRequest("GET", "/users/me", nil, nil, WithAccessType(AccessTypeClient))
+	assert.NoError(t, err)
+
+	_, err = client.SendRequest("GET", "/users/me", nil, nil, WithAccessType(AccessTypeAdmin))
+	var configError *ConfigError
+	if assert.ErrorAs(t, err, &configError) {
+		assert.Equal(t, "SuperSecretAdminKey", configError.Field)
+	}
+}
+
+func TestRequestOptions_BaseURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/users", r.URL.Path)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: "http://127.0.0.1:0"})
+	_, err := client.SendRequest("GET", "/users", nil, nil, WithBaseURL(server.URL+"/api/v1"))
+	assert.NoError(t, err)
+}
+
+func TestRequestOptions_TimeoutCoversRetries(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	policy := testRetryPolicy()
+	policy.MaxAttempts = 100
+	policy.InitialBackoff = 10 * time.Millisecond
+	policy.MaxBackoff = 10 * time.Millisecond
+	client := NewClient(Config{BaseURL: server.URL, RetryPolicy: policy})
+
+	start := time.Now()
+	_, err := client.SendRequestContext(context.Background(), "GET", "/users", nil, nil, WithTimeout(30*time.Millisecond))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestRequestOptions_ResponseMeta(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Stack-Request-Id", "request-1")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":"USER_NOT_FOUND","error":"User not found."}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+
+	var meta ResponseMeta
+	_, err := client.SendRequest("GET", "/users/unknown", nil, nil, WithResponseMeta(&meta))
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	assert.Equal(t, http.StatusNotFound, meta.StatusCode)
+	assert.Equal(t, "request-1", meta.RequestID)
+	assert.Equal(t, "application/json", meta.Header.Get("Content-Type"))
+	assert.Greater(t, meta.Duration, time.Duration(0))
+}
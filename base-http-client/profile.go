@@ -0,0 +1,106 @@
+package base_http_client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profile - именованный набор настроек клиента в файле конфигурации.
+// Значения могут ссылаться на переменные окружения в виде ${NAME}.
+type Profile struct {
+	ProjectID            string `json:"project_id" yaml:"project_id"`
+	AccessType           string `json:"access_type" yaml:"access_type"`
+	PublishableClientKey string `json:"publishable_client_key" yaml:"publishable_client_key"`
+	SecretServerKey      string `json:"secret_server_key" yaml:"secret_server_key"`
+	SuperSecretAdminKey  string `json:"super_secret_admin_key" yaml:"super_secret_admin_key"`
+	BaseURL              string `json:"base_url" yaml:"base_url"`
+}
+
+// profileKeys - ключи профиля для полей Config, используемые в сообщениях об ошибках
+var profileKeys = map[string]string{
+	"ProjectID":            "project_id",
+	"AccessType":           "access_type",
+	"PublishableClientKey": "publishable_client_key",
+	"SecretServerKey":      "secret_server_key",
+	"SuperSecretAdminKey":  "super_secret_admin_key",
+	"BaseURL":              "base_url",
+}
+
+// LoadProfiles читает профили из файла YAML (.yaml, .yml) или JSON (.json).
+// Файл содержит объект, ключами которого являются имена профилей.
+func LoadProfiles(path string) (map[string]Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла конфигурации: %w", err)
+	}
+
+	profiles := map[string]Profile{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, &profiles)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &profiles)
+	default:
+		return nil, fmt.Errorf("неподдерживаемый формат файла конфигурации: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка разбора файла конфигурации %s: %w", path, err)
+	}
+	return profiles, nil
+}
+
+// ConfigFromProfile читает профиль name из файла конфигурации и проверяет его.
+// Ошибка проверки содержит путь к ключу профиля в ConfigError.Source, например "config.yaml: prod.secret_server_key".
+func ConfigFromProfile(path, name string) (Config, error) {
+	profiles, err := LoadProfiles(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	profile, ok := profiles[name]
+	if !ok {
+		return Config{}, fmt.Errorf("профиль %q не найден в файле конфигурации %s", name, path)
+	}
+
+	cfg := profile.Config()
+	if err := cfg.Validate(); err != nil {
+		if configError, ok := err.(*ConfigError); ok {
+			configError.Source = fmt.Sprintf("%s: %s.%s", path, name, profileKeys[configError.Field])
+		}
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// Config преобразует профиль в конфигурацию клиента, подставляя значения переменных окружения
+func (p Profile) Config() Config {
+	cfg := Config{
+		ProjectID:            expandEnv(p.ProjectID),
+		AccessType:           expandEnv(p.AccessType),
+		PublishableClientKey: expandEnv(p.PublishableClientKey),
+		SecretServerKey:      expandEnv(p.SecretServerKey),
+		SuperSecretAdminKey:  expandEnv(p.SuperSecretAdminKey),
+		BaseURL:              normalizeBaseURL(expandEnv(p.BaseURL)),
+	}
+	if cfg.AccessType == "" {
+		cfg.AccessType = inferAccessType(cfg)
+	}
+	return cfg
+}
+
+// envReference - ссылка на переменную окружения в виде ${NAME}
+var envReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandEnv подставляет значения переменных окружения вместо ссылок ${NAME}.
+// Остальные символы $, например в секретных ключах, остаются без изменений
+func expandEnv(value string) string {
+	return envReference.ReplaceAllStringFunc(value, func(reference string) string {
+		return os.Getenv(envReference.FindStringSubmatch(reference)[1])
+	})
+}
@@ -0,0 +1,77 @@
+package base_http_client
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeProfiles(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestConfigFromProfile_YAML(t *testing.T) {
+	t.Setenv("PROD_STACK_SECRET", "prod-secret")
+	path := writeProfiles(t, "stack.yaml", `
+dev:
+  project_id: dev-project
+  publishable_client_key: dev-publishable
+prod:
+  project_id: prod-project
+  secret_server_key: ${PROD_STACK_SECRET}
+  base_url: https://auth.example.com
+`)
+
+	cfg, err := ConfigFromProfile(path, "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, "prod-project", cfg.ProjectID)
+	assert.Equal(t, "prod-secret", cfg.SecretServerKey)
+	assert.Equal(t, AccessTypeServer, cfg.AccessType)
+	assert.Equal(t, "https://auth.example.com/api/v1", cfg.BaseURL)
+
+	cfg, err = ConfigFromProfile(path, "dev")
+	assert.NoError(t, err)
+	assert.Equal(t, AccessTypeClient, cfg.AccessType)
+}
+
+func TestConfigFromProfile_LiteralDollar(t *testing.T) {
+	t.Setenv("STACK_PROJECT", "env-project")
+	t.Setenv("HOME", "/home/user")
+	path := writeProfiles(t, "stack.yaml", `
+prod:
+  project_id: ${STACK_PROJECT}
+  secret_server_key: ssk_a$HOME$b$$c${
+`)
+
+	cfg, err := ConfigFromProfile(path, "prod")
+	assert.NoError(t, err)
+	assert.Equal(t, "env-project", cfg.ProjectID)
+	assert.Equal(t, "ssk_a$HOME$b$$c${", cfg.SecretServerKey)
+}
+
+func TestConfigFromProfile_JSONValidation(t *testing.T) {
+	path := writeProfiles(t, "stack.json", `{"staging": {"access_type": "admin", "project_id": "staging-project"}}`)
+
+	_, err := ConfigFromProfile(path, "staging")
+	var configError *ConfigError
+	assert.True(t, errors.As(err, &configError))
+	assert.Equal(t, "SuperSecretAdminKey", configError.Field)
+	assert.Equal(t, path+": staging.super_secret_admin_key", configError.Source)
+}
+
+func TestConfigFromProfile_Errors(t *testing.T) {
+	path := writeProfiles(t, "stack.yaml", "dev:\n  project_id: dev-project\n")
+	_, err := ConfigFromProfile(path, "prod")
+	assert.ErrorContains(t, err, `"prod"`)
+
+	_, err = ConfigFromProfile(writeProfiles(t, "stack.toml", ""), "dev")
+	assert.Error(t, err)
+
+	_, err = ConfigFromProfile(filepath.Join(t.TempDir(), "missing.yaml"), "dev")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
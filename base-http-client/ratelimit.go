@@ -0,0 +1,246 @@
+package base_http_client
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ErrRateLimited возвращается, когда клиентский лимит запросов исчерпан, а RateLimiterConfig.FailFast включен
+var ErrRateLimited = errors.New("превышен клиентский лимит запросов к Stack Auth")
+
+// DefaultRateLimitPenalty - пауза после ответа 429 без заголовка Retry-After
+const DefaultRateLimitPenalty = time.Second
+
+// DefaultRateLimitMaxPenalty - максимальная пауза после ответа 429, в том числе заданная заголовком Retry-After
+const DefaultRateLimitMaxPenalty = 30 * time.Second
+
+// RateLimit задает параметры корзины токенов
+type RateLimit struct {
+	// RequestsPerSecond - скорость пополнения корзины. Значение не больше 0 отключает лимит
+	RequestsPerSecond float64
+	// Burst - емкость корзины. Значение меньше 1 считается равным 1
+	Burst int
+}
+
+// RateLimiterConfig описывает клиентское ограничение частоты запросов.
+//
+// Каждая попытка запроса забирает токен из глобальной корзины и из корзины операции, если для нее
+// задан лимит. При ответе 429 корзины операции приостанавливаются до истечения Retry-After,
+// а их скорость снижается вдвое и постепенно восстанавливается после успешных ответов.
+type RateLimiterConfig struct {
+	// Global - общий лимит для всех запросов клиента. При nil общий лимит не применяется
+	Global *RateLimit
+	// Operations - лимиты для отдельных операций, ключом является имя операции, например "users.ListUsers"
+	Operations map[string]RateLimit
+	// FailFast включает немедленный возврат ErrRateLimited вместо ожидания свободного токена
+	FailFast bool
+	// DisableAdaptive отключает подстройку под ответы 429
+	DisableAdaptive bool
+	// Penalty - пауза после ответа 429 без Retry-After. По умолчанию DefaultRateLimitPenalty
+	Penalty time.Duration
+	// MaxPenalty - максимальная пауза после ответа 429, ограничивает и Retry-After.
+	// По умолчанию DefaultRateLimitMaxPenalty
+	MaxPenalty time.Duration
+}
+
+// tokenBucket - корзина токенов с адаптивной скоростью пополнения
+type tokenBucket struct {
+	mu           sync.Mutex
+	limit        RateLimit
+	rate         float64
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+}
+
+func newTokenBucket(limit RateLimit) *tokenBucket {
+	if limit.Burst < 1 {
+		limit.Burst = 1
+	}
+	return &tokenBucket{
+		limit:  limit,
+		rate:   limit.RequestsPerSecond,
+		tokens: float64(limit.Burst),
+		last:   time.Now(),
+	}
+}
+
+// refill пополняет корзину за время, прошедшее с последнего обращения
+func (b *tokenBucket) refill(now time.Time) {
+	elapsed := now.Sub(b.last).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(b.limit.Burst), b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// delay возвращает время ожидания до появления токена
+func (b *tokenBucket) delay(now time.Time) time.Duration {
+	var wait time.Duration
+	if b.tokens < 1 {
+		wait = time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+	}
+	if blocked := b.blockedUntil.Sub(now); blocked > wait {
+		wait = blocked
+	}
+	return wait
+}
+
+// reserve забирает токен и возвращает время ожидания до его доступности.
+// При failFast токен забирается, только если он доступен сразу.
+func (b *tokenBucket) reserve(now time.Time, failFast bool) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now)
+	wait := b.delay(now)
+	if failFast && wait > 0 {
+		return wait, false
+	}
+	b.tokens--
+	return wait, true
+}
+
+// cancel возвращает токен, забранный reserve
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens = math.Min(float64(b.limit.Burst), b.tokens+1)
+}
+
+// throttle приостанавливает корзину и снижает скорость после ответа 429
+func (b *tokenBucket) throttle(now time.Time, pause time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill(now)
+	b.tokens = math.Min(b.tokens, 0)
+	if until := now.Add(pause); until.After(b.blockedUntil) {
+		b.blockedUntil = until
+	}
+	b.rate = math.Max(b.rate/2, b.limit.RequestsPerSecond/10)
+}
+
+// recover постепенно восстанавливает скорость после успешного ответа
+func (b *tokenBucket) recover(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.rate < b.limit.RequestsPerSecond {
+		b.refill(now)
+		b.rate = math.Min(b.limit.RequestsPerSecond, b.rate+b.limit.RequestsPerSecond/10)
+	}
+}
+
+// rateLimiter применяет глобальный лимит и лимиты операций
+type rateLimiter struct {
+	config     RateLimiterConfig
+	global     *tokenBucket
+	operations map[string]*tokenBucket
+}
+
+func newRateLimiter(cfg *RateLimiterConfig) *rateLimiter {
+	limiter := &rateLimiter{
+		config:     *cfg,
+		operations: make(map[string]*tokenBucket, len(cfg.Operations)),
+	}
+	if cfg.Global != nil && cfg.Global.RequestsPerSecond > 0 {
+		limiter.global = newTokenBucket(*cfg.Global)
+	}
+	for name, limit := range cfg.Operations {
+		if limit.RequestsPerSecond > 0 {
+			limiter.operations[name] = newTokenBucket(limit)
+		}
+	}
+	if limiter.config.Penalty <= 0 {
+		limiter.config.Penalty = DefaultRateLimitPenalty
+	}
+	if limiter.config.MaxPenalty <= 0 {
+		limiter.config.MaxPenalty = DefaultRateLimitMaxPenalty
+	}
+	return limiter
+}
+
+// buckets возвращает корзины, из которых забирается токен для операции
+func (l *rateLimiter) buckets(op Operation) []*tokenBucket {
+	buckets := make([]*tokenBucket, 0, 2)
+	if l.global != nil {
+		buckets = append(buckets, l.global)
+	}
+	if bucket, ok := l.operations[op.Name]; ok {
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
+
+// wait ожидает свободный токен для операции или возвращает ErrRateLimited в режиме FailFast
+func (l *rateLimiter) wait(ctx context.Context, op Operation) error {
+	buckets := l.buckets(op)
+	now := time.Now()
+
+	var wait time.Duration
+	for i, bucket := range buckets {
+		delay, ok := bucket.reserve(now, l.config.FailFast)
+		if !ok {
+			for _, reserved := range buckets[:i] {
+				reserved.cancel()
+			}
+			return ErrRateLimited
+		}
+		if delay > wait {
+			wait = delay
+		}
+	}
+
+	if err := sleepContext(ctx, wait); err != nil {
+		for _, bucket := range buckets {
+			bucket.cancel()
+		}
+		return err
+	}
+	return nil
+}
+
+// observe подстраивает корзины операции под ответ сервера
+func (l *rateLimiter) observe(op Operation, resp *http.Response) {
+	if l.config.DisableAdaptive || resp == nil {
+		return
+	}
+
+	now := time.Now()
+	if resp.StatusCode != http.StatusTooManyRequests {
+		for _, bucket := range l.buckets(op) {
+			bucket.recover(now)
+		}
+		return
+	}
+
+	pause, ok := parseRetryAfter(resp.Header.Get("Retry-After"), now)
+	if !ok {
+		pause = l.config.Penalty
+	}
+	if pause > l.config.MaxPenalty {
+		pause = l.config.MaxPenalty
+	}
+	for _, bucket := range l.buckets(op) {
+		bucket.throttle(now, pause)
+	}
+}
+
+// middleware возвращает Middleware, ограничивающий частоту попыток запроса
+func (l *rateLimiter) middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(op Operation, req *http.Request) (*http.Response, error) {
+			if err := l.wait(req.Context(), op); err != nil {
+				return nil, err
+			}
+			resp, err := next(op, req)
+			l.observe(op, resp)
+			return resp, err
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package base_http_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit_FailFast(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL:     server.URL,
+		RetryPolicy: testRetryPolicy(),
+		RateLimit: &RateLimiterConfig{
+			Global:   &RateLimit{RequestsPerSecond: 0.1, Burst: 2},
+			FailFast: true,
+		},
+	})
+
+	for i := 0; i < 2; i++ {
+		_, err := client.SendRequest("GET", "/users", nil, nil)
+		assert.NoError(t, err)
+	}
+	_, err := client.SendRequest("GET", "/users", nil, nil)
+	assert.ErrorIs(t, err, ErrRateLimited)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
+
+func TestRateLimit_BlocksUntilTokenAvailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL:   server.URL,
+		RateLimit: &RateLimiterConfig{Global: &RateLimit{RequestsPerSecond: 50, Burst: 1}},
+	})
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := client.SendRequest("GET", "/users", nil, nil)
+		assert.NoError(t, err)
+	}
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+}
+
+func TestRateLimit_ContextDeadline(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL:   server.URL,
+		RateLimit: &RateLimiterConfig{Global: &RateLimit{RequestsPerSecond: 0.01, Burst: 1}},
+	})
+
+	_, err := client.SendRequest("GET", "/users", nil, nil)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = client.SendRequestContext(ctx, "GET", "/users", nil, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestRateLimit_PerOperation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		RateLimit: &RateLimiterConfig{
+			Operations: map[string]RateLimit{"users.ListUsers": {RequestsPerSecond: 0.1, Burst: 1}},
+			FailFast:   true,
+		},
+	})
+
+	listUsers := &Request{Operation: "users.ListUsers", Method: "GET", Path: "/users"}
+	_, err := client.Do(context.Background(), listUsers)
+	assert.NoError(t, err)
+	_, err = client.Do(context.Background(), listUsers)
+	assert.ErrorIs(t, err, ErrRateLimited)
+
+	_, err = client.Do(context.Background(), &Request{Operation: "teams.ListTeams", Method: "GET", Path: "/teams"})
+	assert.NoError(t, err)
+}
+
+func TestRateLimit_AdaptsToTooManyRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		RateLimit: &RateLimiterConfig{
+			Global:   &RateLimit{RequestsPerSecond: 1000, Burst: 10},
+			FailFast: true,
+		},
+	})
+
+	_, err := client.SendRequest("GET", "/users", nil, nil)
+	var apiError *APIError
+	assert.ErrorAs(t, err, &apiError)
+	assert.Equal(t, http.StatusTooManyRequests, apiError.StatusCode)
+	_, err = client.SendRequest("GET", "/users", nil, nil)
+	assert.ErrorIs(t, err, ErrRateLimited)
+}
+
+func TestTokenBucket_ThrottleAndRecover(t *testing.T) {
+	bucket := newTokenBucket(RateLimit{RequestsPerSecond: 10, Burst: 1})
+	now := time.Now()
+
+	bucket.throttle(now, 0)
+	assert.Equal(t, 5.0, bucket.rate)
+	bucket.throttle(now, 0)
+	bucket.throttle(now, 0)
+	bucket.throttle(now, 0)
+	assert.Equal(t, 1.0, bucket.rate)
+
+	for i := 0; i < 20; i++ {
+		bucket.recover(now)
+	}
+	assert.Equal(t, 10.0, bucket.rate)
+}
+
+func TestRateLimit_NonPositiveRateDisablesLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		RateLimit: &RateLimiterConfig{
+			Global:     &RateLimit{RequestsPerSecond: 0, Burst: 1},
+			Operations: map[string]RateLimit{"users.ListUsers": {RequestsPerSecond: -1}},
+			FailFast:   true,
+		},
+	})
+
+	for i := 0; i < 5; i++ {
+		_, err := client.Do(context.Background(), &Request{Operation: "users.ListUsers", Method: "GET", Path: "/users"})
+		assert.NoError(t, err)
+	}
+}
+
+func TestRateLimit_RetryAfterCappedByMaxPenalty(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.Header().Set("Retry-After", "86400")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		BaseURL:     server.URL,
+		RetryPolicy: testRetryPolicy(),
+		RateLimit: &RateLimiterConfig{
+			Global:     &RateLimit{RequestsPerSecond: 1000, Burst: 10},
+			MaxPenalty: 20 * time.Millisecond,
+		},
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err := client.SendRequestContext(ctx, "GET", "/users", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&requests))
+}
@@ -0,0 +1,167 @@
+package base_http_client
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// IdempotencyKeyHeader - заголовок, в котором передается ключ идемпотентности запроса
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// RetryPolicy описывает политику повторных попыток для неуспешных запросов.
+//
+// По умолчанию повторяются только идемпотентные методы (GET, HEAD, OPTIONS, PUT, DELETE).
+// Неидемпотентный запрос (например, POST из CreateUser) повторяется только в том случае,
+// если для него задан ключ идемпотентности через ContextWithIdempotencyKey.
+type RetryPolicy struct {
+	// MaxAttempts - общее число попыток, включая первую. Значение меньше 2 отключает повторы
+	MaxAttempts int
+	// InitialBackoff - задержка перед первым повтором
+	InitialBackoff time.Duration
+	// MaxBackoff - максимальная задержка между попытками, в том числе заданная заголовком Retry-After
+	MaxBackoff time.Duration
+	// Multiplier - множитель экспоненциального роста задержки
+	Multiplier float64
+	// Jitter - доля случайного разброса задержки в диапазоне [0, 1]
+	Jitter float64
+	// RetryableStatusCodes - HTTP-статусы, при которых запрос повторяется
+	RetryableStatusCodes []int
+}
+
+// DefaultRetryPolicy возвращает политику повторов с настройками по умолчанию
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 200 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+		RetryableStatusCodes: []int{
+			http.StatusTooManyRequests,
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+	}
+}
+
+type idempotencyKeyContextKey struct{}
+
+// ContextWithIdempotencyKey возвращает контекст с ключом идемпотентности.
+// Ключ отправляется в заголовке Idempotency-Key и разрешает повтор неидемпотентных запросов.
+func ContextWithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+// IdempotencyKeyFromContext возвращает ключ идемпотентности, сохраненный в контексте
+func IdempotencyKeyFromContext(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyContextKey{}).(string)
+	return key
+}
+
+// maxAttempts возвращает число попыток для запроса с указанным методом
+func (p *RetryPolicy) maxAttempts(method string, hasIdempotencyKey bool) int {
+	if p == nil || p.MaxAttempts < 2 {
+		return 1
+	}
+	if !isIdempotentMethod(method) && !hasIdempotencyKey {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+// shouldRetry определяет, нужно ли повторить запрос по результату попытки
+func (p *RetryPolicy) shouldRetry(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, ErrRateLimited)
+	}
+	for _, code := range p.RetryableStatusCodes {
+		if resp.StatusCode == code {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff вычисляет задержку перед следующей попыткой.
+// Значение заголовка Retry-After имеет приоритет над экспоненциальной задержкой, но не превышает MaxBackoff.
+func (p *RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if delay, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+				delay = p.MaxBackoff
+			}
+			return delay
+		}
+	}
+
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	delay := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && delay > float64(p.MaxBackoff) {
+		delay = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+	if delay < 0 {
+		return 0
+	}
+	return time.Duration(delay)
+}
+
+// parseRetryAfter разбирает значение заголовка Retry-After в секундах или в формате HTTP-даты
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		delay := date.Sub(now)
+		if delay < 0 {
+			delay = 0
+		}
+		return delay, true
+	}
+	return 0, false
+}
+
+// isIdempotentMethod сообщает, является ли HTTP-метод идемпотентным
+func isIdempotentMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// sleepContext ожидает указанное время или отмену контекста
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,155 @@
+package base_http_client
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testRetryPolicy() *RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.InitialBackoff = time.Millisecond
+	policy.MaxBackoff = 5 * time.Millisecond
+	return policy
+}
+
+func TestRetry_IdempotentRequestRetriedUntilSuccess(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success":true}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, RetryPolicy: testRetryPolicy()})
+	response, err := client.SendRequest("GET", "/users", nil, nil)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"success":true}`, string(response))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestRetry_StopsAfterMaxAttempts(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, RetryPolicy: testRetryPolicy()})
+	_, err := client.SendRequest("GET", "/users", nil, nil)
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestRetry_PostWithoutIdempotencyKeyNotRetried(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, RetryPolicy: testRetryPolicy()})
+	_, err := client.SendRequest("POST", "/users", nil, []byte(`{"display_name":"John Doe"}`))
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestRetry_PostWithIdempotencyKeyRetried(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "create-user-1", r.Header.Get(IdempotencyKeyHeader))
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"id":"user-id"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, RetryPolicy: testRetryPolicy()})
+	ctx := ContextWithIdempotencyKey(context.Background(), "create-user-1")
+	response, err := client.SendRequestContext(ctx, "POST", "/users", nil, []byte(`{"display_name":"John Doe"}`))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id":"user-id"}`, string(response))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestRetry_ClientErrorNotRetried(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, RetryPolicy: testRetryPolicy()})
+	_, err := client.SendRequest("GET", "/users/unknown", nil, nil)
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestRetry_BackoffInterruptedByContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	policy := testRetryPolicy()
+	policy.MaxBackoff = time.Minute
+	client := NewClient(Config{BaseURL: server.URL, RetryPolicy: policy})
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.SendRequestContext(ctx, "GET", "/users", nil, nil)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestRetryPolicy_BackoffHonorsRetryAfter(t *testing.T) {
+	policy := DefaultRetryPolicy()
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"2"}}}
+	assert.Equal(t, 2*time.Second, policy.backoff(1, resp))
+}
+
+func TestRetryPolicy_RetryAfterIsCapped(t *testing.T) {
+	policy := &RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 3 * time.Second, Multiplier: 2}
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"3600"}}}
+	assert.Equal(t, 3*time.Second, policy.backoff(1, resp))
+}
+
+func TestRetryPolicy_BackoffIsCapped(t *testing.T) {
+	policy := &RetryPolicy{InitialBackoff: time.Second, MaxBackoff: 3 * time.Second, Multiplier: 2}
+	assert.Equal(t, time.Second, policy.backoff(1, nil))
+	assert.Equal(t, 2*time.Second, policy.backoff(2, nil))
+	assert.Equal(t, 3*time.Second, policy.backoff(5, nil))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	delay, ok := parseRetryAfter("5", now)
+	assert.True(t, ok)
+	assert.Equal(t, 5*time.Second, delay)
+
+	delay, ok = parseRetryAfter(now.Add(10*time.Second).Format(http.TimeFormat), now)
+	assert.True(t, ok)
+	assert.Equal(t, 10*time.Second, delay)
+
+	_, ok = parseRetryAfter("soon", now)
+	assert.False(t, ok)
+}
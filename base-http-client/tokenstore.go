@@ -0,0 +1,341 @@
+package base_http_client
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	_interface "github.com/BlaisePopov/stack-auth/base-http-client/interface"
+)
+
+// Tokens - токены пользователя: access token и refresh token
+type Tokens = _interface.Tokens
+
+// TokenStore хранит токены пользователя между запросами.
+// Реализации: MemoryTokenStore, FileTokenStore и CookieTokenStore
+type TokenStore = _interface.TokenStore
+
+// ErrTokenStoreSave возвращается вместе с телом ответа, если запрос выполнен, но хранилище токенов
+// не удалось обновить. Ответ, например выданные при входе токены, остается доступен вызывающему коду
+var ErrTokenStoreSave = _interface.ErrTokenStoreSave
+
+// tokenOperation описывает, как операция меняет токены в хранилище
+type tokenOperation int
+
+const (
+	// tokensIssued - операция выдает access token и refresh token новой сессии
+	tokensIssued tokenOperation = iota + 1
+	// tokensRefreshed - операция выдает новый access token сессии
+	tokensRefreshed
+	// tokensRevoked - операция завершает сессию
+	tokensRevoked
+)
+
+// tokenOperations - операции, результат которых сохраняется в хранилище токенов
+var tokenOperations = map[string]tokenOperation{
+	"password.SignInWithEmail":    tokensIssued,
+	"password.SignUpWithEmail":    tokensIssued,
+	"otp.SignInWithCode":          tokensIssued,
+	"otp.MFASignIn":               tokensIssued,
+	"sessions.CreateSession":      tokensIssued,
+	"sessions.RefreshAccessToken": tokensRefreshed,
+	"sessions.SignOut":            tokensRevoked,
+}
+
+// updateTokenStore сохраняет в store токены, выданные операцией.
+// Обновление и выход меняют хранилище, только если запрос выполнен с сохраненным refresh token
+func updateTokenStore(ctx context.Context, store TokenStore, op Operation, stored Tokens, refreshToken string, responseBody []byte) error {
+	switch tokenOperations[op.Name] {
+	case tokensIssued:
+		var issued Tokens
+		if err := json.Unmarshal(responseBody, &issued); err != nil || issued.AccessToken == "" && issued.RefreshToken == "" {
+			return nil
+		}
+		return store.Save(ctx, issued)
+	case tokensRefreshed:
+		var refreshed Tokens
+		if refreshToken == "" || refreshToken != stored.RefreshToken || json.Unmarshal(responseBody, &refreshed) != nil || refreshed.AccessToken == "" {
+			return nil
+		}
+		return store.Save(ctx, Tokens{AccessToken: refreshed.AccessToken, RefreshToken: stored.RefreshToken})
+	case tokensRevoked:
+		if refreshToken == "" || refreshToken != stored.RefreshToken {
+			return nil
+		}
+		return store.Clear(ctx)
+	}
+	return nil
+}
+
+// MemoryTokenStore хранит токены в памяти процесса. Безопасен для конкурентного использования
+type MemoryTokenStore struct {
+	mu     sync.Mutex
+	tokens Tokens
+}
+
+// NewMemoryTokenStore создает хранилище токенов в памяти с начальными токенами
+func NewMemoryTokenStore(tokens Tokens) *MemoryTokenStore {
+	return &MemoryTokenStore{tokens: tokens}
+}
+
+// Load возвращает сохраненные токены
+func (s *MemoryTokenStore) Load(_ context.Context) (Tokens, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.tokens, nil
+}
+
+// Save сохраняет токены
+func (s *MemoryTokenStore) Save(_ context.Context, tokens Tokens) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = tokens
+	return nil
+}
+
+// Clear удаляет сохраненные токены
+func (s *MemoryTokenStore) Clear(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = Tokens{}
+	return nil
+}
+
+// ErrTokenFileCorrupted возвращается, если файл токенов поврежден или зашифрован другим ключом
+var ErrTokenFileCorrupted = errors.New("файл токенов поврежден или зашифрован другим ключом")
+
+// FileTokenStore хранит токены в файле, зашифрованном AES-GCM.
+// Файл создается с правами 0600 и заменяется атомарно. Безопасен для конкурентного использования в одном процессе
+type FileTokenStore struct {
+	path string
+	aead cipher.AEAD
+	mu   sync.Mutex
+}
+
+// NewFileTokenStore создает хранилище токенов в файле path.
+// Ключ key длиной 16, 24 или 32 байта выбирает AES-128, AES-192 или AES-256
+func NewFileTokenStore(path string, key []byte) (*FileTokenStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("некорректный ключ шифрования файла токенов: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &FileTokenStore{path: path, aead: aead}, nil
+}
+
+// Load читает и расшифровывает токены. Если файла нет, возвращает пустые Tokens
+func (s *FileTokenStore) Load(_ context.Context) (Tokens, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Tokens{}, nil
+	}
+	if err != nil {
+		return Tokens{}, fmt.Errorf("ошибка чтения файла токенов: %w", err)
+	}
+
+	nonceSize := s.aead.NonceSize()
+	if len(data) < nonceSize {
+		return Tokens{}, ErrTokenFileCorrupted
+	}
+	plaintext, err := s.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return Tokens{}, ErrTokenFileCorrupted
+	}
+	var tokens Tokens
+	if err := json.Unmarshal(plaintext, &tokens); err != nil {
+		return Tokens{}, ErrTokenFileCorrupted
+	}
+	return tokens, nil
+}
+
+// Save шифрует и записывает токены
+func (s *FileTokenStore) Save(_ context.Context, tokens Tokens) error {
+	plaintext, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	data := s.aead.Seal(nonce, nonce, plaintext, nil)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return writeFileAtomic(s.path, data)
+}
+
+// Clear удаляет файл токенов
+func (s *FileTokenStore) Clear(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("ошибка удаления файла токенов: %w", err)
+	}
+	return nil
+}
+
+// writeFileAtomic записывает файл с правами 0600 через временный файл в том же каталоге
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("ошибка записи файла токенов: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("ошибка записи файла токенов: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("ошибка записи файла токенов: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("ошибка записи файла токенов: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("ошибка записи файла токенов: %w", err)
+	}
+	return nil
+}
+
+const (
+	// DefaultTokenCookieName - имя cookie с токенами, совпадающее с cookie клиентского SDK Stack Auth
+	DefaultTokenCookieName = "stack-access"
+	// DefaultTokenCookieMaxAge - время жизни cookie с токенами по умолчанию
+	DefaultTokenCookieMaxAge = 365 * 24 * time.Hour
+)
+
+// CookieConfig описывает cookie, в котором CookieTokenStore хранит токены
+type CookieConfig struct {
+	// Name - имя cookie. По умолчанию DefaultTokenCookieName
+	Name string
+	// Path - путь cookie. По умолчанию "/"
+	Path string
+	// Domain - домен cookie. По умолчанию не задан
+	Domain string
+	// MaxAge - время жизни cookie. По умолчанию DefaultTokenCookieMaxAge
+	MaxAge time.Duration
+	// Insecure отключает флаг Secure, например для разработки на http://localhost
+	Insecure bool
+	// SameSite - политика SameSite. По умолчанию http.SameSiteLaxMode
+	SameSite http.SameSite
+}
+
+// CookieTokenStore хранит токены в HTTP-only cookie входящего запроса.
+// Значение cookie - URL-кодированный JSON-массив [refreshToken, accessToken], как у клиентского SDK Stack Auth.
+// Хранилище создается на каждый входящий запрос и передается в методы клиента опцией WithTokenStore
+type CookieTokenStore struct {
+	w      http.ResponseWriter
+	r      *http.Request
+	config CookieConfig
+
+	mu    sync.Mutex
+	saved *Tokens
+}
+
+// NewCookieTokenStore создает хранилище токенов в cookie запроса r, изменения записываются в ответ w
+func NewCookieTokenStore(w http.ResponseWriter, r *http.Request, config CookieConfig) *CookieTokenStore {
+	if config.Name == "" {
+		config.Name = DefaultTokenCookieName
+	}
+	if config.Path == "" {
+		config.Path = "/"
+	}
+	if config.MaxAge <= 0 {
+		config.MaxAge = DefaultTokenCookieMaxAge
+	}
+	if config.SameSite == 0 {
+		config.SameSite = http.SameSiteLaxMode
+	}
+	return &CookieTokenStore{w: w, r: r, config: config}
+}
+
+// Load возвращает токены, сохраненные в рамках текущего запроса, или токены из cookie запроса
+func (s *CookieTokenStore) Load(_ context.Context) (Tokens, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.saved != nil {
+		return *s.saved, nil
+	}
+	cookie, err := s.r.Cookie(s.config.Name)
+	if err != nil {
+		return Tokens{}, nil
+	}
+	return ParseTokenCookie(cookie.Value), nil
+}
+
+// Save записывает токены в cookie ответа
+func (s *CookieTokenStore) Save(_ context.Context, tokens Tokens) error {
+	value, err := json.Marshal([]string{tokens.RefreshToken, tokens.AccessToken})
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saved = &tokens
+	http.SetCookie(s.w, s.cookie(url.QueryEscape(string(value)), int(s.config.MaxAge/time.Second)))
+	return nil
+}
+
+// Clear удаляет cookie с токенами
+func (s *CookieTokenStore) Clear(_ context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.saved = &Tokens{}
+	http.SetCookie(s.w, s.cookie("", -1))
+	return nil
+}
+
+func (s *CookieTokenStore) cookie(value string, maxAge int) *http.Cookie {
+	return &http.Cookie{
+		Name:     s.config.Name,
+		Value:    value,
+		Path:     s.config.Path,
+		Domain:   s.config.Domain,
+		MaxAge:   maxAge,
+		Secure:   !s.config.Insecure,
+		HttpOnly: true,
+		SameSite: s.config.SameSite,
+	}
+}
+
+// ParseTokenCookie разбирает значение cookie с токенами: URL-кодированный или обычный JSON-массив
+// [refreshToken, accessToken] клиентского SDK Stack Auth или только access token.
+// Тот же разбор использует stackauthhttp.FromCookie
+func ParseTokenCookie(value string) Tokens {
+	value = strings.TrimSpace(value)
+	if unescaped, err := url.QueryUnescape(value); err == nil {
+		value = unescaped
+	}
+	if !strings.HasPrefix(value, "[") {
+		return Tokens{AccessToken: value}
+	}
+	var pair []string
+	if err := json.Unmarshal([]byte(value), &pair); err != nil || len(pair) != 2 {
+		return Tokens{}
+	}
+	return Tokens{RefreshToken: pair[0], AccessToken: pair[1]}
+}
@@ -0,0 +1,180 @@
+package base_http_client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenStore_ClientLoadsAndUpdatesTokens(t *testing.T) {
+	var accessTokens, refreshTokens []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		accessTokens = append(accessTokens, r.Header.Get("X-Stack-Access-Token"))
+		refreshTokens = append(refreshTokens, r.Header.Get("X-Stack-Refresh-Token"))
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/auth/password/sign-in":
+			json.NewEncoder(w).Encode(map[string]string{"access_token": "access-1", "refresh_token": "refresh-1", "user_id": "user-1"})
+		case "/auth/sessions/current/refresh":
+			json.NewEncoder(w).Encode(map[string]string{"access_token": "access-2"})
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	store := NewMemoryTokenStore(Tokens{})
+	client := NewClient(Config{BaseURL: server.URL, SecretServerKey: "secret", TokenStore: store})
+
+	_, err := client.Do(ctx, &Request{Operation: "password.SignInWithEmail", Method: "POST", Path: "/auth/password/sign-in"})
+	assert.NoError(t, err)
+	tokens, _ := store.Load(ctx)
+	assert.Equal(t, Tokens{AccessToken: "access-1", RefreshToken: "refresh-1"}, tokens)
+
+	_, err = client.Do(ctx, &Request{Operation: "users.GetCurrentUser", Method: "GET", Path: "/users/me"})
+	assert.NoError(t, err)
+	_, err = client.Do(ctx, &Request{Operation: "users.GetCurrentUser", Method: "GET", Path: "/users/me"}, WithAccessToken("explicit"))
+	assert.NoError(t, err)
+
+	_, err = client.Do(ctx, &Request{Operation: "sessions.RefreshAccessToken", Method: "POST", Path: "/auth/sessions/current/refresh"})
+	assert.NoError(t, err)
+	tokens, _ = store.Load(ctx)
+	assert.Equal(t, Tokens{AccessToken: "access-2", RefreshToken: "refresh-1"}, tokens)
+
+	// Выход из другой сессии не затрагивает сохраненные токены
+	_, err = client.Do(ctx, &Request{Operation: "sessions.SignOut", Method: "DELETE", Path: "/auth/sessions/current"}, WithRefreshToken("other"))
+	assert.NoError(t, err)
+	tokens, _ = store.Load(ctx)
+	assert.Equal(t, "refresh-1", tokens.RefreshToken)
+
+	_, err = client.Do(ctx, &Request{Operation: "sessions.SignOut", Method: "DELETE", Path: "/auth/sessions/current"})
+	assert.NoError(t, err)
+	tokens, _ = store.Load(ctx)
+	assert.Equal(t, Tokens{}, tokens)
+
+	assert.Equal(t, []string{"", "access-1", "explicit", "access-1", "access-2", "access-2"}, accessTokens)
+	assert.Equal(t, []string{"", "refresh-1", "refresh-1", "refresh-1", "other", "refresh-1"}, refreshTokens)
+}
+
+// failingTokenStore - хранилище, которое не удается обновить
+type failingTokenStore struct {
+	MemoryTokenStore
+}
+
+func (s *failingTokenStore) Save(context.Context, Tokens) error {
+	return errors.New("disk full")
+}
+
+func TestTokenStore_SaveErrorKeepsResponse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"access-1","refresh_token":"refresh-1"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL, SecretServerKey: "secret", TokenStore: &failingTokenStore{}})
+	body, err := client.Do(context.Background(), &Request{Operation: "password.SignInWithEmail", Method: "POST", Path: "/auth/password/sign-in"})
+	assert.ErrorIs(t, err, ErrTokenStoreSave)
+	assert.Contains(t, err.Error(), "disk full")
+	assert.Contains(t, string(body), "refresh-1")
+}
+
+func TestFileTokenStore(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "tokens")
+	key := []byte("0123456789abcdef0123456789abcdef")
+	store, err := NewFileTokenStore(path, key)
+	assert.NoError(t, err)
+
+	tokens, err := store.Load(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, Tokens{}, tokens)
+
+	assert.NoError(t, store.Save(ctx, Tokens{AccessToken: "access", RefreshToken: "refresh"}))
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	data, _ := os.ReadFile(path)
+	assert.NotContains(t, string(data), "refresh")
+
+	reopened, _ := NewFileTokenStore(path, key)
+	tokens, err = reopened.Load(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, Tokens{AccessToken: "access", RefreshToken: "refresh"}, tokens)
+
+	otherKey, _ := NewFileTokenStore(path, []byte("fedcba9876543210fedcba9876543210"))
+	_, err = otherKey.Load(ctx)
+	assert.ErrorIs(t, err, ErrTokenFileCorrupted)
+
+	assert.NoError(t, store.Clear(ctx))
+	assert.NoError(t, store.Clear(ctx))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	_, err = NewFileTokenStore(path, []byte("short"))
+	assert.Error(t, err)
+}
+
+func TestCookieTokenStore(t *testing.T) {
+	ctx := context.Background()
+	w := httptest.NewRecorder()
+	store := NewCookieTokenStore(w, httptest.NewRequest(http.MethodGet, "/", nil), CookieConfig{})
+
+	tokens, err := store.Load(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, Tokens{}, tokens)
+
+	saved := Tokens{AccessToken: "access.jwt", RefreshToken: "refresh"}
+	assert.NoError(t, store.Save(ctx, saved))
+	tokens, _ = store.Load(ctx)
+	assert.Equal(t, saved, tokens)
+
+	cookies := w.Result().Cookies()
+	if assert.Len(t, cookies, 1) {
+		assert.Equal(t, DefaultTokenCookieName, cookies[0].Name)
+		assert.True(t, cookies[0].HttpOnly)
+		assert.True(t, cookies[0].Secure)
+		assert.Equal(t, http.SameSiteLaxMode, cookies[0].SameSite)
+	}
+
+	// Следующий запрос браузера передает сохраненный cookie
+	next := httptest.NewRequest(http.MethodGet, "/", nil)
+	next.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	store = NewCookieTokenStore(w, next, CookieConfig{})
+	tokens, err = store.Load(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, saved, tokens)
+
+	assert.NoError(t, store.Clear(ctx))
+	tokens, _ = store.Load(ctx)
+	assert.Equal(t, Tokens{}, tokens)
+	if cookies := w.Result().Cookies(); assert.Len(t, cookies, 1) {
+		assert.Less(t, cookies[0].MaxAge, 0)
+	}
+}
+
+func TestParseTokenCookie(t *testing.T) {
+	pair := `["refresh","access"]`
+	cases := map[string]Tokens{
+		"access":                 {AccessToken: "access"},
+		pair:                     {RefreshToken: "refresh", AccessToken: "access"},
+		url.QueryEscape(pair):    {RefreshToken: "refresh", AccessToken: "access"},
+		url.PathEscape(pair):     {RefreshToken: "refresh", AccessToken: "access"},
+		` ` + pair + ` `:         {RefreshToken: "refresh", AccessToken: "access"},
+		`["only-one"]`:           {},
+		url.QueryEscape(`[1,2]`): {},
+	}
+	for value, expected := range cases {
+		assert.Equal(t, expected, ParseTokenCookie(value), value)
+	}
+}
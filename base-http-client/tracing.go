@@ -0,0 +1,35 @@
+package base_http_client
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/BlaisePopov/stack-auth/base-http-client/tracing"
+)
+
+// SpanNamePrefix - префикс имен спанов операций Stack Auth
+const SpanNamePrefix = "stackauth."
+
+// spanName возвращает имя спана для операции, например "stackauth.users.GetUser"
+func spanName(op Operation) string {
+	if op.Name == "" {
+		return SpanNamePrefix + "request"
+	}
+	return SpanNamePrefix + op.Name
+}
+
+// recordSpanResult добавляет в спан статус ответа и код ошибки Stack Auth
+func recordSpanResult(span tracing.Span, resp *http.Response, err error) {
+	if resp != nil {
+		span.SetAttributes(tracing.Int("http.response.status_code", resp.StatusCode))
+	}
+	if err == nil {
+		return
+	}
+
+	var apiError *APIError
+	if errors.As(err, &apiError) && apiError.Code != "" {
+		span.SetAttributes(tracing.String("stackauth.error_code", apiError.Code))
+	}
+	span.RecordError(err)
+}
@@ -0,0 +1,256 @@
+// Package tracing описывает минимальный интерфейс трассировки в стиле OpenTelemetry,
+// который используется базовым клиентом для создания спанов на каждую операцию Stack Auth.
+//
+// Реализация по умолчанию (Noop) не записывает спаны, но сохраняет контекст трассировки
+// вызывающего кода. Для тестов предусмотрены NewTracer и InMemoryExporter.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceParentHeader - заголовок W3C Trace Context для передачи контекста трассировки
+const TraceParentHeader = "traceparent"
+
+// TraceID - идентификатор трассы
+type TraceID [16]byte
+
+// SpanID - идентификатор спана
+type SpanID [8]byte
+
+// SpanContext содержит идентификаторы спана, передаваемые между сервисами
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid сообщает, заданы ли идентификаторы трассы и спана
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != TraceID{} && sc.SpanID != SpanID{}
+}
+
+// TraceParent возвращает значение заголовка traceparent в формате W3C Trace Context
+func (sc SpanContext) TraceParent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return fmt.Sprintf("00-%s-%s-%s", hex.EncodeToString(sc.TraceID[:]), hex.EncodeToString(sc.SpanID[:]), flags)
+}
+
+// ParseTraceParent разбирает значение заголовка traceparent
+func ParseTraceParent(value string) (SpanContext, bool) {
+	parts := strings.Split(value, "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return SpanContext{}, false
+	}
+
+	var sc SpanContext
+	if _, err := hex.Decode(sc.TraceID[:], []byte(parts[1])); err != nil {
+		return SpanContext{}, false
+	}
+	if _, err := hex.Decode(sc.SpanID[:], []byte(parts[2])); err != nil {
+		return SpanContext{}, false
+	}
+	sc.Sampled = parts[3] == "01"
+	return sc, sc.IsValid()
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext возвращает контекст с контекстом трассировки вызывающего кода
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext возвращает контекст трассировки, сохраненный в контексте
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	sc, _ := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc
+}
+
+// Attribute - атрибут спана
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// String создает строковый атрибут
+func String(key, value string) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Int создает целочисленный атрибут
+func Int(key string, value int) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Span - выполняемая операция в рамках трассы
+type Span interface {
+	// SpanContext возвращает идентификаторы спана для передачи в заголовках
+	SpanContext() SpanContext
+	// SetAttributes добавляет атрибуты спана
+	SetAttributes(attrs ...Attribute)
+	// RecordError отмечает спан как завершившийся ошибкой
+	RecordError(err error)
+	// End завершает спан
+	End()
+}
+
+// Tracer создает спаны
+type Tracer interface {
+	// Start создает спан, дочерний по отношению к контексту трассировки ctx
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Noop возвращает трассировщик, не записывающий спаны.
+// Контекст трассировки вызывающего кода сохраняется и передается дальше без изменений.
+func Noop() Tracer {
+	return noopTracer{}
+}
+
+type noopTracer struct{}
+
+func (noopTracer) Start(ctx context.Context, _ string, _ ...Attribute) (context.Context, Span) {
+	return ctx, noopSpan{sc: SpanContextFromContext(ctx)}
+}
+
+type noopSpan struct {
+	sc SpanContext
+}
+
+func (s noopSpan) SpanContext() SpanContext { return s.sc }
+func (noopSpan) SetAttributes(...Attribute) {}
+func (noopSpan) RecordError(error)          {}
+func (noopSpan) End()                       {}
+
+// SpanData содержит сведения о завершенном спане
+type SpanData struct {
+	Name        string
+	SpanContext SpanContext
+	Parent      SpanContext
+	Attributes  map[string]interface{}
+	Err         error
+	StartTime   time.Time
+	EndTime     time.Time
+}
+
+// Exporter получает завершенные спаны
+type Exporter interface {
+	ExportSpan(span SpanData)
+}
+
+// NewTracer возвращает трассировщик, передающий завершенные спаны в exporter.
+// Спан наследует флаг sampled родительского контекста. Спаны без этого флага не передаются в exporter,
+// но их контекст распространяется дальше
+func NewTracer(exporter Exporter) Tracer {
+	return &tracer{exporter: exporter}
+}
+
+type tracer struct {
+	exporter Exporter
+}
+
+func (t *tracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	parent := SpanContextFromContext(ctx)
+
+	sc := SpanContext{TraceID: parent.TraceID, Sampled: parent.Sampled}
+	if !parent.IsValid() {
+		rand.Read(sc.TraceID[:])
+		sc.Sampled = true
+	}
+	rand.Read(sc.SpanID[:])
+
+	s := &span{
+		exporter: t.exporter,
+		data: SpanData{
+			Name:        name,
+			SpanContext: sc,
+			Parent:      parent,
+			Attributes:  map[string]interface{}{},
+			StartTime:   time.Now(),
+		},
+	}
+	s.SetAttributes(attrs...)
+	return ContextWithSpanContext(ctx, sc), s
+}
+
+type span struct {
+	mu       sync.Mutex
+	exporter Exporter
+	data     SpanData
+	ended    bool
+}
+
+func (s *span) SpanContext() SpanContext {
+	return s.data.SpanContext
+}
+
+func (s *span) SetAttributes(attrs ...Attribute) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, attr := range attrs {
+		s.data.Attributes[attr.Key] = attr.Value
+	}
+}
+
+func (s *span) RecordError(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Err = err
+}
+
+func (s *span) End() {
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.data.EndTime = time.Now()
+	data := s.data
+	s.mu.Unlock()
+
+	if data.SpanContext.Sampled {
+		s.exporter.ExportSpan(data)
+	}
+}
+
+// InMemoryExporter сохраняет завершенные спаны в памяти. Предназначен для тестов
+type InMemoryExporter struct {
+	mu    sync.Mutex
+	spans []SpanData
+}
+
+// NewInMemoryExporter создает пустой InMemoryExporter
+func NewInMemoryExporter() *InMemoryExporter {
+	return &InMemoryExporter{}
+}
+
+// ExportSpan сохраняет завершенный спан
+func (e *InMemoryExporter) ExportSpan(span SpanData) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = append(e.spans, span)
+}
+
+// Spans возвращает копию сохраненных спанов
+func (e *InMemoryExporter) Spans() []SpanData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]SpanData(nil), e.spans...)
+}
+
+// Reset удаляет сохраненные спаны
+func (e *InMemoryExporter) Reset() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.spans = nil
+}
@@ -0,0 +1,81 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceParentRoundTrip(t *testing.T) {
+	value := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	sc, ok := ParseTraceParent(value)
+	assert.True(t, ok)
+	assert.True(t, sc.Sampled)
+	assert.Equal(t, value, sc.TraceParent())
+
+	_, ok = ParseTraceParent("00-invalid")
+	assert.False(t, ok)
+	_, ok = ParseTraceParent("00-00000000000000000000000000000000-0000000000000000-01")
+	assert.False(t, ok)
+}
+
+func TestTracer_ChildSpanKeepsTraceID(t *testing.T) {
+	parent, _ := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := ContextWithSpanContext(context.Background(), parent)
+
+	exporter := NewInMemoryExporter()
+	ctx, span := NewTracer(exporter).Start(ctx, "stackauth.users.GetUser", String("url.template", "/users/{user_id}"))
+	span.SetAttributes(Int("http.response.status_code", 404))
+	span.RecordError(errors.New("not found"))
+	span.End()
+	span.End()
+
+	spans := exporter.Spans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "stackauth.users.GetUser", spans[0].Name)
+	assert.Equal(t, parent.TraceID, spans[0].SpanContext.TraceID)
+	assert.NotEqual(t, parent.SpanID, spans[0].SpanContext.SpanID)
+	assert.Equal(t, parent, spans[0].Parent)
+	assert.Equal(t, "/users/{user_id}", spans[0].Attributes["url.template"])
+	assert.Equal(t, 404, spans[0].Attributes["http.response.status_code"])
+	assert.EqualError(t, spans[0].Err, "not found")
+	assert.Equal(t, spans[0].SpanContext, SpanContextFromContext(ctx))
+
+	exporter.Reset()
+	assert.Empty(t, exporter.Spans())
+}
+
+func TestTracer_RootSpan(t *testing.T) {
+	exporter := NewInMemoryExporter()
+	_, span := NewTracer(exporter).Start(context.Background(), "root")
+	span.End()
+
+	spans := exporter.Spans()
+	assert.Len(t, spans, 1)
+	assert.True(t, spans[0].SpanContext.IsValid())
+	assert.False(t, spans[0].Parent.IsValid())
+}
+
+func TestNoop_PreservesCallerContext(t *testing.T) {
+	parent, _ := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := ContextWithSpanContext(context.Background(), parent)
+
+	_, span := Noop().Start(ctx, "stackauth.users.GetUser")
+	assert.Equal(t, parent, span.SpanContext())
+}
+
+func TestTracer_InheritsSampledFlag(t *testing.T) {
+	parent, _ := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00")
+	ctx := ContextWithSpanContext(context.Background(), parent)
+	exporter := NewInMemoryExporter()
+
+	ctx, span := NewTracer(exporter).Start(ctx, "stackauth.users.GetUser")
+	span.End()
+
+	assert.False(t, span.SpanContext().Sampled)
+	assert.Equal(t, parent.TraceID, SpanContextFromContext(ctx).TraceID)
+	assert.Empty(t, exporter.Spans())
+}
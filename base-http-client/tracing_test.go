@@ -0,0 +1,97 @@
+package base_http_client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/BlaisePopov/stack-auth/base-http-client/tracing"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracing_SpanPerOperation(t *testing.T) {
+	var traceParent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParent = r.Header.Get(tracing.TraceParentHeader)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":"USER_NOT_FOUND","error":"User not found."}`))
+	}))
+	defer server.Close()
+
+	exporter := tracing.NewInMemoryExporter()
+	client := NewClient(Config{BaseURL: server.URL, Tracer: tracing.NewTracer(exporter)})
+
+	caller, _ := tracing.ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx := tracing.ContextWithSpanContext(context.Background(), caller)
+
+	_, err := client.Do(ctx, &Request{
+		Operation:    "users.GetUser",
+		Method:       "GET",
+		PathTemplate: "/users/{user_id}",
+		Path:         "/users/unknown",
+	})
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	spans := exporter.Spans()
+	assert.Len(t, spans, 1)
+	span := spans[0]
+	assert.Equal(t, "stackauth.users.GetUser", span.Name)
+	assert.Equal(t, caller, span.Parent)
+	assert.Equal(t, caller.TraceID, span.SpanContext.TraceID)
+	assert.Equal(t, "GET", span.Attributes["http.request.method"])
+	assert.Equal(t, "/users/{user_id}", span.Attributes["url.template"])
+	assert.Equal(t, http.StatusNotFound, span.Attributes["http.response.status_code"])
+	assert.Equal(t, "USER_NOT_FOUND", span.Attributes["stackauth.error_code"])
+	assert.ErrorIs(t, span.Err, ErrUserNotFound)
+	assert.Equal(t, span.SpanContext.TraceParent(), traceParent)
+}
+
+func TestTracing_NoopPropagatesCallerContext(t *testing.T) {
+	var traceParent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		traceParent = r.Header.Get(tracing.TraceParentHeader)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{BaseURL: server.URL})
+
+	value := "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	caller, _ := tracing.ParseTraceParent(value)
+	_, err := client.SendRequestContext(tracing.ContextWithSpanContext(context.Background(), caller), "GET", "/users", nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, value, traceParent)
+
+	_, err = client.SendRequest("GET", "/users", nil, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, traceParent)
+}
+
+func TestTracing_CircuitOpenRecordedInSpan(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	exporter := tracing.NewInMemoryExporter()
+	client := NewClient(Config{
+		BaseURL:        server.URL,
+		Tracer:         tracing.NewTracer(exporter),
+		CircuitBreaker: &CircuitBreakerConfig{Default: CircuitBreakerSettings{FailureThreshold: 1, Cooldown: time.Minute}},
+	})
+	getUser := &Request{Operation: "users.GetUser", Method: "GET", PathTemplate: "/users/{user_id}", Path: "/users/user-1"}
+
+	client.Do(context.Background(), getUser)
+	exporter.Reset()
+	_, err := client.Do(context.Background(), getUser)
+	assert.ErrorIs(t, err, ErrCircuitOpen)
+
+	spans := exporter.Spans()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, "stackauth.users.GetUser", spans[0].Name)
+	assert.ErrorIs(t, spans[0].Err, ErrCircuitOpen)
+	assert.NotContains(t, spans[0].Attributes, "http.response.status_code")
+}
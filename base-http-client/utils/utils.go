@@ -12,6 +12,12 @@ func AddOptionalStringParam(params url.Values, key string, value string) {
 	}
 }
 
+func AddOptionalBoolParam(params url.Values, key string, value bool) {
+	if value {
+		params.Add(key, strconv.FormatBool(value))
+	}
+}
+
 func AddOptionalIntParam(params url.Values, key string, value int) {
 	if value != 0 {
 		params.Add(key, strconv.Itoa(value))
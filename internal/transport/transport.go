@@ -0,0 +1,48 @@
+// Package transport содержит общий конвейер выполнения запросов для клиентов пакетов api/*.
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	_interface "github.com/BlaisePopov/stack-auth/base-http-client/interface"
+)
+
+// NoBody - тип тела для запросов и ответов без содержимого
+type NoBody struct{}
+
+// Do сериализует payload в JSON, выполняет запрос и декодирует ответ в Resp.
+// При nil payload запрос отправляется без тела.
+// Если запрос выполнен, но токены не удалось сохранить (ErrTokenStoreSave), возвращаются и ответ, и ошибка
+func Do[Req, Resp any](ctx context.Context, client _interface.BaseHTTPClient, request *_interface.Request, payload *Req, opts ..._interface.RequestOption) (*Resp, error) {
+	rawResponse, err := send(ctx, client, request, payload, opts)
+	if err != nil && !errors.Is(err, _interface.ErrTokenStoreSave) {
+		return nil, err
+	}
+
+	response := new(Resp)
+	if decodeErr := json.Unmarshal(rawResponse, response); decodeErr != nil {
+		return nil, fmt.Errorf("ошибка декодирования ответа: %w", decodeErr)
+	}
+	return response, err
+}
+
+// Send выполняет запрос, тело ответа которого не используется
+func Send[Req any](ctx context.Context, client _interface.BaseHTTPClient, request *_interface.Request, payload *Req, opts ..._interface.RequestOption) error {
+	_, err := send(ctx, client, request, payload, opts)
+	return err
+}
+
+// send сериализует тело запроса и передает запрос базовому клиенту
+func send[Req any](ctx context.Context, client _interface.BaseHTTPClient, request *_interface.Request, payload *Req, opts []_interface.RequestOption) ([]byte, error) {
+	if payload != nil {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка кодирования запроса: %w", err)
+		}
+		request.Body = body
+	}
+	return client.Do(ctx, request, opts...)
+}
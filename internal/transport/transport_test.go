@@ -0,0 +1,88 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"testing"
+
+	_interface "github.com/BlaisePopov/stack-auth/base-http-client/interface"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClient struct {
+	request  *_interface.Request
+	options  _interface.RequestOptions
+	response []byte
+	err      error
+}
+
+func (f *fakeClient) SendRequest(method, path string, queryParams url.Values, body []byte, opts ..._interface.RequestOption) ([]byte, error) {
+	return f.SendRequestContext(context.Background(), method, path, queryParams, body, opts...)
+}
+
+func (f *fakeClient) SendRequestContext(ctx context.Context, method, path string, queryParams url.Values, body []byte, opts ..._interface.RequestOption) ([]byte, error) {
+	return f.Do(ctx, &_interface.Request{Method: method, Path: path, Query: queryParams, Body: body}, opts...)
+}
+
+func (f *fakeClient) Do(_ context.Context, request *_interface.Request, opts ..._interface.RequestOption) ([]byte, error) {
+	f.request = request
+	f.options = _interface.NewRequestOptions(opts...)
+	return f.response, f.err
+}
+
+type payload struct {
+	Name string `json:"name"`
+}
+
+type result struct {
+	ID string `json:"id"`
+}
+
+func TestDo_EncodesAndDecodes(t *testing.T) {
+	client := &fakeClient{response: []byte(`{"id":"42"}`)}
+
+	response, err := Do[payload, result](context.Background(), client, &_interface.Request{Method: "POST", Path: "/items"},
+		&payload{Name: "item"}, _interface.WithAccessToken("token"))
+	assert.NoError(t, err)
+	assert.Equal(t, "42", response.ID)
+	assert.JSONEq(t, `{"name":"item"}`, string(client.request.Body))
+	assert.Equal(t, "token", client.options.AccessToken)
+}
+
+func TestDo_NoBody(t *testing.T) {
+	client := &fakeClient{response: []byte(`{"id":"42"}`)}
+
+	_, err := Do[NoBody, result](context.Background(), client, &_interface.Request{Method: "GET", Path: "/items/42"}, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, client.request.Body)
+}
+
+func TestDo_Errors(t *testing.T) {
+	apiError := errors.New("api error")
+	_, err := Do[NoBody, result](context.Background(), &fakeClient{err: apiError}, &_interface.Request{}, nil)
+	assert.ErrorIs(t, err, apiError)
+
+	_, err = Do[NoBody, result](context.Background(), &fakeClient{response: []byte(`not json`)}, &_interface.Request{}, nil)
+	assert.ErrorContains(t, err, "ошибка декодирования ответа")
+
+	err = Send[NoBody](context.Background(), &fakeClient{response: []byte(`not json`)}, &_interface.Request{}, nil)
+	assert.NoError(t, err)
+}
+
+func TestDo_ReturnsResponseWithTokenStoreError(t *testing.T) {
+	storeErr := fmt.Errorf("%w: disk full", _interface.ErrTokenStoreSave)
+	client := &fakeClient{response: []byte(`{"id":"42"}`), err: storeErr}
+
+	response, err := Do[NoBody, result](context.Background(), client, &_interface.Request{Method: "POST", Path: "/auth/password/sign-in"}, nil)
+	assert.ErrorIs(t, err, _interface.ErrTokenStoreSave)
+	if assert.NotNil(t, response) {
+		assert.Equal(t, "42", response.ID)
+	}
+
+	client.err = errors.New("connection refused")
+	response, err = Do[NoBody, result](context.Background(), client, &_interface.Request{Method: "POST", Path: "/auth/password/sign-in"}, nil)
+	assert.Error(t, err)
+	assert.Nil(t, response)
+}
@@ -0,0 +1,200 @@
+// Package stackauthhttp содержит middleware net/http для аутентификации запросов пользователями Stack Auth.
+//
+// Middleware извлекает access token из заголовка Authorization, заголовка x-stack-auth или cookie
+// клиентского SDK, проверяет его и сохраняет пользователя в контексте запроса:
+//
+//	verifier, _ := stackauthjwt.NewVerifier(stackauthjwt.Config{ProjectID: projectID})
+//	auth, _ := stackauthhttp.New(stackauthhttp.Config{Verifier: verifier})
+//
+//	mux.Handle("/profile", auth.RequireUser(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//		user, _ := stackauthhttp.UserFromContext(r.Context())
+//		fmt.Fprintln(w, user.ID)
+//	})))
+package stackauthhttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/BlaisePopov/stack-auth/api"
+	"github.com/BlaisePopov/stack-auth/api/users"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+	"github.com/BlaisePopov/stack-auth/stackauthjwt"
+)
+
+// ErrNoToken возвращается, если запрос не содержит access token
+var ErrNoToken = errors.New("stackauthhttp: access token не передан")
+
+// ErrUnavailable возвращается, если пользователя не удалось проверить из-за недоступности Stack Auth:
+// набор ключей не загружен или профиль пользователя не удалось получить
+var ErrUnavailable = errors.New("stackauthhttp: сервис аутентификации недоступен")
+
+// TokenVerifier проверяет access token. Интерфейс реализует *stackauthjwt.Verifier
+type TokenVerifier interface {
+	Verify(ctx context.Context, token string) (*stackauthjwt.Claims, error)
+}
+
+// UnauthorizedHandler формирует ответ на запрос без действующего access token
+type UnauthorizedHandler func(w http.ResponseWriter, r *http.Request, err error)
+
+// Config содержит параметры middleware
+type Config struct {
+	// Verifier проверяет access token, обязательный параметр
+	Verifier TokenVerifier
+	// Users загружает профиль пользователя через API. При nil профиль не загружается и User.Profile пуст
+	Users api.UsersAPI
+	// TokenExtractor извлекает токены из запроса. По умолчанию DefaultTokenExtractor
+	TokenExtractor TokenExtractor
+	// Unauthorized формирует ответ RequireUser на запрос без действующего токена, а также на запрос
+	// пользователя, не найденного через Users. По умолчанию WriteUnauthorized
+	Unauthorized UnauthorizedHandler
+	// Unavailable формирует ответ RequireUser, если пользователя не удалось проверить из-за
+	// недоступности Stack Auth (ошибка ErrUnavailable). По умолчанию WriteUnavailable
+	Unavailable UnauthorizedHandler
+}
+
+// User - аутентифицированный пользователь запроса
+type User struct {
+	// ID - идентификатор пользователя
+	ID string
+	// Claims - утверждения проверенного access token
+	Claims *stackauthjwt.Claims
+	// Profile - профиль пользователя, загруженный через Config.Users
+	Profile *users.User
+	// Tokens - токены, переданные в запросе
+	Tokens Tokens
+}
+
+// Authenticator аутентифицирует запросы. Методы Authenticator безопасны для конкурентного использования
+type Authenticator struct {
+	config Config
+}
+
+// New создает Authenticator
+func New(config Config) (*Authenticator, error) {
+	if config.Verifier == nil {
+		return nil, errors.New("stackauthhttp: не задан Verifier")
+	}
+	if config.TokenExtractor == nil {
+		config.TokenExtractor = DefaultTokenExtractor
+	}
+	if config.Unauthorized == nil {
+		config.Unauthorized = WriteUnauthorized
+	}
+	if config.Unavailable == nil {
+		config.Unavailable = WriteUnavailable
+	}
+	return &Authenticator{config: config}, nil
+}
+
+// Authenticate извлекает и проверяет токен запроса.
+// Возвращает ErrNoToken, если токена нет, ошибку проверки, если токен недействителен,
+// и ErrUnavailable, если проверка не удалась из-за недоступности Stack Auth
+func (a *Authenticator) Authenticate(r *http.Request) (*User, error) {
+	tokens := a.config.TokenExtractor(r)
+	if tokens.AccessToken == "" {
+		return nil, ErrNoToken
+	}
+	claims, err := a.config.Verifier.Verify(r.Context(), tokens.AccessToken)
+	if errors.Is(err, stackauthjwt.ErrJWKSUnavailable) {
+		return nil, fmt.Errorf("%w: %w", ErrUnavailable, err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	user := &User{ID: claims.UserID, Claims: claims, Tokens: tokens}
+	if a.config.Users != nil {
+		profile, err := a.config.Users.GetUserCtx(r.Context(), claims.UserID)
+		if errors.Is(err, base_http_client.ErrUserNotFound) {
+			return nil, fmt.Errorf("stackauthhttp: пользователь %s не найден: %w", claims.UserID, err)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: не удалось загрузить пользователя %s: %w", ErrUnavailable, claims.UserID, err)
+		}
+		user.Profile = &profile.User
+	}
+	return user, nil
+}
+
+// RequireUser пропускает к next только запросы с действующим access token.
+// Остальные запросы получают ответ Config.Unauthorized, а при недоступности Stack Auth - Config.Unavailable
+func (a *Authenticator) RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, err := a.Authenticate(r)
+		if errors.Is(err, ErrUnavailable) {
+			a.config.Unavailable(w, r, err)
+			return
+		}
+		if err != nil {
+			a.config.Unauthorized(w, r, err)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ContextWithUser(r.Context(), user)))
+	})
+}
+
+// OptionalUser сохраняет в контексте пользователя, если запрос содержит действующий access token.
+// Запросы без токена или с недействительным токеном передаются next без пользователя
+func (a *Authenticator) OptionalUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, err := a.Authenticate(r); err == nil {
+			r = r.WithContext(ContextWithUser(r.Context(), user))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// contextKey - ключ пользователя в контексте запроса
+type contextKey struct{}
+
+// ContextWithUser возвращает контекст с пользователем. Пригоден для тестов обработчиков
+func ContextWithUser(ctx context.Context, user *User) context.Context {
+	return context.WithValue(ctx, contextKey{}, user)
+}
+
+// UserFromContext возвращает пользователя, сохраненного RequireUser или OptionalUser
+func UserFromContext(ctx context.Context) (*User, bool) {
+	user, ok := ctx.Value(contextKey{}).(*User)
+	return user, ok && user != nil
+}
+
+// unauthorizedMessages - тексты ответа 401 по коду ошибки. Текст err в ответ не попадает
+var unauthorizedMessages = map[base_http_client.ErrorCode]string{
+	base_http_client.ErrUserAuthenticationRequired: "User authentication required for this endpoint.",
+	base_http_client.ErrAccessTokenExpired:         "Access token has expired. Please refresh it and try again.",
+	base_http_client.ErrUnparsableAccessToken:      "Access token is not parsable.",
+	base_http_client.ErrUserNotFound:               "User not found.",
+}
+
+// WriteUnauthorized отвечает статусом 401 и телом в формате известных ошибок Stack Auth.
+// Код ошибки берется из err, если это код Stack Auth, иначе используется USER_AUTHENTICATION_REQUIRED
+func WriteUnauthorized(w http.ResponseWriter, r *http.Request, err error) {
+	code := base_http_client.ErrUserAuthenticationRequired
+	for _, known := range []base_http_client.ErrorCode{base_http_client.ErrAccessTokenExpired, base_http_client.ErrUnparsableAccessToken, base_http_client.ErrUserNotFound} {
+		if errors.Is(err, known) {
+			code = known
+			break
+		}
+	}
+
+	if errors.Is(err, ErrNoToken) {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+	} else {
+		w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+	}
+	w.Header().Set(base_http_client.KnownErrorHeader, string(code))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(map[string]string{"code": string(code), "error": unauthorizedMessages[code]})
+}
+
+// WriteUnavailable отвечает статусом 503. Текст err в ответ не попадает
+func WriteUnavailable(w http.ResponseWriter, r *http.Request, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusServiceUnavailable)
+	json.NewEncoder(w).Encode(map[string]string{"error": "Authentication service is temporarily unavailable."})
+}
@@ -0,0 +1,216 @@
+package stackauthhttp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/BlaisePopov/stack-auth/api"
+	"github.com/BlaisePopov/stack-auth/api/mocks"
+	"github.com/BlaisePopov/stack-auth/api/users"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+	"github.com/BlaisePopov/stack-auth/stackauthjwt"
+	"github.com/BlaisePopov/stack-auth/stackauthtest"
+	"github.com/stretchr/testify/assert"
+)
+
+// setup запускает fake-сервер и создает Authenticator с проверкой токенов по его набору ключей
+func setup(t *testing.T, config Config, now func() time.Time) (*stackauthtest.Server, *Authenticator) {
+	server := stackauthtest.NewServer()
+	t.Cleanup(server.Close)
+
+	verifierConfig := stackauthjwt.ConfigFromClient(server.Config())
+	verifierConfig.Now = now
+	verifier, err := stackauthjwt.NewVerifier(verifierConfig)
+	assert.NoError(t, err)
+
+	config.Verifier = verifier
+	auth, err := New(config)
+	assert.NoError(t, err)
+	return server, auth
+}
+
+// whoami отвечает идентификатором пользователя из контекста или "anonymous"
+var whoami = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	if user, ok := UserFromContext(r.Context()); ok {
+		w.Write([]byte(user.ID))
+		return
+	}
+	w.Write([]byte("anonymous"))
+})
+
+func TestRequireUser_TokenSources(t *testing.T) {
+	server, auth := setup(t, Config{}, nil)
+	userID := server.CreateUser("john@example.com", "")
+	accessToken, refreshToken, err := server.SignIn(userID)
+	assert.NoError(t, err)
+
+	stackAuthHeader, _ := json.Marshal(map[string]string{"accessToken": accessToken, "refreshToken": refreshToken})
+	cookie, _ := json.Marshal([]string{refreshToken, accessToken})
+	recorder := httptest.NewRecorder()
+	tokens := base_http_client.Tokens{AccessToken: accessToken, RefreshToken: refreshToken}
+	base_http_client.NewCookieTokenStore(recorder, httptest.NewRequest(http.MethodGet, "/", nil), base_http_client.CookieConfig{}).Save(context.Background(), tokens)
+	storeCookie := recorder.Result().Cookies()[0]
+	requests := map[string]func(r *http.Request){
+		"authorization": func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+accessToken) },
+		"x-stack-auth":  func(r *http.Request) { r.Header.Set("x-stack-auth", string(stackAuthHeader)) },
+		"cookie":        func(r *http.Request) { r.AddCookie(&http.Cookie{Name: DefaultAccessCookie, Value: accessToken}) },
+		"encoded cookie": func(r *http.Request) {
+			r.Header.Set("Cookie", DefaultAccessCookie+"="+url.PathEscape(string(cookie)))
+		},
+		"token store cookie": func(r *http.Request) { r.AddCookie(storeCookie) },
+	}
+	for name, prepare := range requests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		prepare(r)
+		w := httptest.NewRecorder()
+		auth.RequireUser(whoami).ServeHTTP(w, r)
+		assert.Equal(t, http.StatusOK, w.Code, name)
+		assert.Equal(t, userID, w.Body.String(), name)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("x-stack-auth", string(stackAuthHeader))
+	user, err := auth.Authenticate(r)
+	assert.NoError(t, err)
+	assert.Equal(t, refreshToken, user.Tokens.RefreshToken)
+	assert.Equal(t, "john@example.com", user.Claims.Email)
+}
+
+func TestRequireUser_Unauthorized(t *testing.T) {
+	now := time.Now()
+	server, auth := setup(t, Config{}, func() time.Time { return now })
+	accessToken, _, err := server.SignIn(server.CreateUser("john@example.com", ""))
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	auth.RequireUser(whoami).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+	assert.Equal(t, string(base_http_client.ErrUserAuthenticationRequired), w.Header().Get(base_http_client.KnownErrorHeader))
+
+	now = now.Add(stackauthtest.DefaultAccessTokenTTL + time.Minute)
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+accessToken)
+	w = httptest.NewRecorder()
+	auth.RequireUser(whoami).ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, string(base_http_client.ErrAccessTokenExpired), w.Header().Get(base_http_client.KnownErrorHeader))
+
+	var body map[string]string
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, string(base_http_client.ErrAccessTokenExpired), body["code"])
+	assert.NotContains(t, body["error"], "stackauthjwt")
+}
+
+// verifierFunc позволяет использовать функцию как TokenVerifier
+type verifierFunc func(ctx context.Context, token string) (*stackauthjwt.Claims, error)
+
+func (f verifierFunc) Verify(ctx context.Context, token string) (*stackauthjwt.Claims, error) {
+	return f(ctx, token)
+}
+
+func TestRequireUser_Unavailable(t *testing.T) {
+	jwksDown := verifierFunc(func(ctx context.Context, token string) (*stackauthjwt.Claims, error) {
+		return nil, fmt.Errorf("%w: dial tcp 10.0.0.1:443: connection refused", stackauthjwt.ErrJWKSUnavailable)
+	})
+	valid := verifierFunc(func(ctx context.Context, token string) (*stackauthjwt.Claims, error) {
+		return &stackauthjwt.Claims{UserID: "user-1"}, nil
+	})
+	usersDown := &mocks.Users{
+		GetUserFunc: func(ctx context.Context, userID string, opts ...base_http_client.RequestOption) (*users.UserResponse, error) {
+			return nil, &base_http_client.APIError{StatusCode: http.StatusInternalServerError, Message: "dial tcp 10.0.0.1:443: connection refused"}
+		},
+	}
+	for name, config := range map[string]Config{
+		"jwks":    {Verifier: jwksDown},
+		"profile": {Verifier: valid, Users: usersDown},
+	} {
+		auth, err := New(config)
+		assert.NoError(t, err)
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Authorization", "Bearer token")
+		_, err = auth.Authenticate(r)
+		assert.ErrorIs(t, err, ErrUnavailable, name)
+
+		w := httptest.NewRecorder()
+		auth.RequireUser(whoami).ServeHTTP(w, r)
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code, name)
+		assert.NotContains(t, w.Body.String(), "10.0.0.1", name)
+	}
+}
+
+func TestRequireUser_CustomUnauthorized(t *testing.T) {
+	_, auth := setup(t, Config{
+		Unauthorized: func(w http.ResponseWriter, r *http.Request, err error) {
+			http.Redirect(w, r, "/login", http.StatusFound)
+		},
+	}, nil)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer garbage")
+	w := httptest.NewRecorder()
+	auth.RequireUser(whoami).ServeHTTP(w, r)
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "/login", w.Header().Get("Location"))
+}
+
+func TestOptionalUser(t *testing.T) {
+	server, auth := setup(t, Config{}, nil)
+	userID := server.CreateUser("john@example.com", "")
+	accessToken, _, err := server.SignIn(userID)
+	assert.NoError(t, err)
+
+	for token, expected := range map[string]string{"": "anonymous", "garbage": "anonymous", accessToken: userID} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		auth.OptionalUser(whoami).ServeHTTP(w, r)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, expected, w.Body.String())
+	}
+}
+
+func TestAuthenticate_LoadsProfile(t *testing.T) {
+	server := stackauthtest.NewServer()
+	defer server.Close()
+	verifier, err := stackauthjwt.NewVerifier(stackauthjwt.ConfigFromClient(server.Config()))
+	assert.NoError(t, err)
+	auth, err := New(Config{Verifier: verifier, Users: api.NewClient(server.Config()).Users})
+	assert.NoError(t, err)
+
+	userID := server.CreateUser("john@example.com", "")
+	accessToken, _, err := server.SignIn(userID)
+	assert.NoError(t, err)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+accessToken)
+	user, err := auth.Authenticate(r)
+	assert.NoError(t, err)
+	if assert.NotNil(t, user.Profile) {
+		assert.Equal(t, "john@example.com", user.Profile.PrimaryEmail)
+	}
+
+	client := api.NewClient(server.Config())
+	_, err = client.Users.DeleteUser(userID)
+	assert.NoError(t, err)
+	_, err = auth.Authenticate(r)
+	assert.ErrorIs(t, err, base_http_client.ErrUserNotFound)
+}
+
+func TestContextWithUser(t *testing.T) {
+	_, ok := UserFromContext(context.Background())
+	assert.False(t, ok)
+
+	user, ok := UserFromContext(ContextWithUser(context.Background(), &User{ID: "user-1"}))
+	assert.True(t, ok)
+	assert.Equal(t, "user-1", user.ID)
+}
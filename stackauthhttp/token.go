@@ -0,0 +1,84 @@
+package stackauthhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+const (
+	// HeaderStackAuth - заголовок, в котором клиентский SDK Stack Auth передает токены в формате JSON
+	HeaderStackAuth = "X-Stack-Auth"
+	// DefaultAccessCookie - cookie, в котором клиентский SDK Stack Auth хранит токены
+	DefaultAccessCookie = "stack-access"
+)
+
+// Tokens - токены пользователя, извлеченные из запроса
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+// TokenExtractor извлекает токены из запроса. Пустой AccessToken означает, что токена в запросе нет
+type TokenExtractor func(r *http.Request) Tokens
+
+// FromAuthorization извлекает access token из заголовка "Authorization: Bearer <token>"
+func FromAuthorization(r *http.Request) Tokens {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return Tokens{}
+	}
+	return Tokens{AccessToken: strings.TrimSpace(token)}
+}
+
+// FromStackAuthHeader извлекает токены из заголовка x-stack-auth.
+// Заголовок содержит JSON {"accessToken": "...", "refreshToken": "..."} или только access token
+func FromStackAuthHeader(r *http.Request) Tokens {
+	return parseStackAuthHeader(r.Header.Get(HeaderStackAuth))
+}
+
+// FromCookie возвращает извлекатель токенов из cookie. Значение cookie - JSON-массив
+// [refreshToken, accessToken], который сохраняет клиентский SDK (в том числе URL-кодированный),
+// или только access token
+func FromCookie(name string) TokenExtractor {
+	return func(r *http.Request) Tokens {
+		cookie, err := r.Cookie(name)
+		if err != nil {
+			return Tokens{}
+		}
+		tokens := base_http_client.ParseTokenCookie(cookie.Value)
+		return Tokens{AccessToken: tokens.AccessToken, RefreshToken: tokens.RefreshToken}
+	}
+}
+
+// FirstOf возвращает извлекатель, использующий первый источник, в котором найден access token
+func FirstOf(extractors ...TokenExtractor) TokenExtractor {
+	return func(r *http.Request) Tokens {
+		for _, extract := range extractors {
+			if tokens := extract(r); tokens.AccessToken != "" {
+				return tokens
+			}
+		}
+		return Tokens{}
+	}
+}
+
+// DefaultTokenExtractor ищет токен в заголовке Authorization, затем в x-stack-auth и в cookie stack-access
+var DefaultTokenExtractor = FirstOf(FromAuthorization, FromStackAuthHeader, FromCookie(DefaultAccessCookie))
+
+func parseStackAuthHeader(value string) Tokens {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, "{") {
+		return Tokens{AccessToken: value}
+	}
+	var header struct {
+		AccessToken  string `json:"accessToken"`
+		RefreshToken string `json:"refreshToken"`
+	}
+	if err := json.Unmarshal([]byte(value), &header); err != nil {
+		return Tokens{}
+	}
+	return Tokens{AccessToken: header.AccessToken, RefreshToken: header.RefreshToken}
+}
@@ -0,0 +1,141 @@
+package stackauthjwt
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Claims содержит утверждения проверенного access token Stack Auth
+type Claims struct {
+	// UserID - идентификатор пользователя (sub)
+	UserID string
+	// RefreshTokenID - идентификатор refresh token сессии, для которой выпущен access token
+	RefreshTokenID string
+	// SelectedTeamID - идентификатор выбранной команды пользователя, пустой, если команда не выбрана
+	SelectedTeamID string
+	// Role - роль пользователя, для обычных пользователей "authenticated"
+	Role string
+	// ProjectID - идентификатор проекта
+	ProjectID string
+	// BranchID - идентификатор ветки проекта
+	BranchID string
+	// IsAnonymous сообщает, что токен выпущен анонимному пользователю
+	IsAnonymous bool
+	// Name - отображаемое имя пользователя
+	Name string
+	// Email - основной email пользователя
+	Email string
+	// EmailVerified сообщает, подтвержден ли основной email
+	EmailVerified bool
+	// Issuer - издатель токена (iss)
+	Issuer string
+	// Audience - получатели токена (aud)
+	Audience []string
+	// IssuedAt - время выпуска токена (iat)
+	IssuedAt time.Time
+	// ExpiresAt - время истечения токена (exp)
+	ExpiresAt time.Time
+	// NotBefore - время, раньше которого токен недействителен (nbf), нулевое, если не задано
+	NotBefore time.Time
+}
+
+// payload - полезная нагрузка токена в формате JSON
+type payload struct {
+	Subject        string          `json:"sub"`
+	RefreshTokenID string          `json:"refresh_token_id"`
+	SelectedTeamID *string         `json:"selected_team_id"`
+	Role           string          `json:"role"`
+	ProjectID      string          `json:"project_id"`
+	BranchID       string          `json:"branch_id"`
+	IsAnonymous    bool            `json:"is_anonymous"`
+	Name           *string         `json:"name"`
+	Email          *string         `json:"email"`
+	EmailVerified  bool            `json:"email_verified"`
+	Issuer         string          `json:"iss"`
+	Audience       json.RawMessage `json:"aud"`
+	IssuedAt       *float64        `json:"iat"`
+	ExpiresAt      *float64        `json:"exp"`
+	NotBefore      *float64        `json:"nbf"`
+}
+
+// ParseUnverified разбирает утверждения токена без проверки подписи, издателя и срока действия.
+// Пригоден только для чтения собственных токенов клиента, например чтобы узнать время их истечения.
+// Для проверки токенов, полученных от пользователей, используйте Verifier
+func ParseUnverified(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: токен должен состоять из трех частей", ErrMalformedToken)
+	}
+	rawPayload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("%w: некорректная кодировка base64url", ErrMalformedToken)
+	}
+	return parseClaims(rawPayload)
+}
+
+// parseClaims разбирает полезную нагрузку токена
+func parseClaims(data []byte) (*Claims, error) {
+	var p payload
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("%w: некорректная полезная нагрузка: %v", ErrMalformedToken, err)
+	}
+	if p.ExpiresAt == nil {
+		return nil, fmt.Errorf("%w: отсутствует утверждение exp", ErrMalformedToken)
+	}
+
+	audience, err := parseAudience(p.Audience)
+	if err != nil {
+		return nil, err
+	}
+	return &Claims{
+		UserID:         p.Subject,
+		RefreshTokenID: p.RefreshTokenID,
+		SelectedTeamID: stringValue(p.SelectedTeamID),
+		Role:           p.Role,
+		ProjectID:      p.ProjectID,
+		BranchID:       p.BranchID,
+		IsAnonymous:    p.IsAnonymous,
+		Name:           stringValue(p.Name),
+		Email:          stringValue(p.Email),
+		EmailVerified:  p.EmailVerified,
+		Issuer:         p.Issuer,
+		Audience:       audience,
+		IssuedAt:       numericDate(p.IssuedAt),
+		ExpiresAt:      numericDate(p.ExpiresAt),
+		NotBefore:      numericDate(p.NotBefore),
+	}, nil
+}
+
+// parseAudience разбирает утверждение aud, заданное строкой или массивом строк
+func parseAudience(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var single string
+	if err := json.Unmarshal(raw, &single); err == nil {
+		return []string{single}, nil
+	}
+	var multiple []string
+	if err := json.Unmarshal(raw, &multiple); err != nil {
+		return nil, fmt.Errorf("%w: некорректное утверждение aud", ErrMalformedToken)
+	}
+	return multiple, nil
+}
+
+// numericDate переводит время в секундах Unix в time.Time
+func numericDate(seconds *float64) time.Time {
+	if seconds == nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(int64(*seconds * 1000))
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
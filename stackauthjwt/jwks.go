@@ -0,0 +1,173 @@
+package stackauthjwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+)
+
+// maxJWKSSize ограничивает размер ответа с набором ключей
+const maxJWKSSize = 1 << 20
+
+// jwk - открытый ключ в формате JSON Web Key
+type jwk struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+}
+
+// publicKey - разобранный открытый ключ набора
+type publicKey struct {
+	id  string
+	alg string
+	key crypto.PublicKey
+}
+
+// algorithm описывает поддерживаемый алгоритм подписи
+type algorithm struct {
+	hash  crypto.Hash
+	curve elliptic.Curve
+	rsa   bool
+}
+
+// algorithms - поддерживаемые алгоритмы подписи. Stack Auth подписывает токены алгоритмом ES256
+var algorithms = map[string]algorithm{
+	"ES256": {hash: crypto.SHA256, curve: elliptic.P256()},
+	"ES384": {hash: crypto.SHA384, curve: elliptic.P384()},
+	"ES512": {hash: crypto.SHA512, curve: elliptic.P521()},
+	"RS256": {hash: crypto.SHA256, rsa: true},
+	"RS384": {hash: crypto.SHA384, rsa: true},
+	"RS512": {hash: crypto.SHA512, rsa: true},
+}
+
+// fetchJWKS загружает набор ключей. Ключи неподдерживаемых типов пропускаются
+func fetchJWKS(ctx context.Context, client *http.Client, url string) (map[string]publicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrJWKSUnavailable, err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrJWKSUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxJWKSSize))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrJWKSUnavailable, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: статус ответа %d", ErrJWKSUnavailable, resp.StatusCode)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(body, &set); err != nil {
+		return nil, fmt.Errorf("%w: некорректный набор ключей: %v", ErrJWKSUnavailable, err)
+	}
+
+	keys := make(map[string]publicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.KeyID] = key
+	}
+	return keys, nil
+}
+
+// publicKey разбирает ключ типа EC или RSA
+func (k jwk) publicKey() (publicKey, error) {
+	switch k.KeyType {
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return publicKey{}, fmt.Errorf("неподдерживаемая кривая %q", k.Curve)
+		}
+		x, errX := decodeBigInt(k.X)
+		y, errY := decodeBigInt(k.Y)
+		if errX != nil || errY != nil {
+			return publicKey{}, fmt.Errorf("некорректные координаты ключа %q", k.KeyID)
+		}
+		key := &ecdsa.PublicKey{Curve: curve, X: x, Y: y}
+		if !curve.IsOnCurve(x, y) {
+			return publicKey{}, fmt.Errorf("точка ключа %q не лежит на кривой", k.KeyID)
+		}
+		return publicKey{id: k.KeyID, alg: k.Algorithm, key: key}, nil
+	case "RSA":
+		n, errN := decodeBigInt(k.N)
+		e, errE := decodeBigInt(k.E)
+		if errN != nil || errE != nil || !e.IsInt64() {
+			return publicKey{}, fmt.Errorf("некорректные параметры ключа %q", k.KeyID)
+		}
+		return publicKey{id: k.KeyID, alg: k.Algorithm, key: &rsa.PublicKey{N: n, E: int(e.Int64())}}, nil
+	default:
+		return publicKey{}, fmt.Errorf("неподдерживаемый тип ключа %q", k.KeyType)
+	}
+}
+
+// verify проверяет подпись signed ключом по алгоритму alg
+func (k publicKey) verify(alg string, signed, signature []byte) bool {
+	a, ok := algorithms[alg]
+	if !ok || (k.alg != "" && k.alg != alg) {
+		return false
+	}
+	h := a.hash.New()
+	h.Write(signed)
+	digest := h.Sum(nil)
+
+	switch key := k.key.(type) {
+	case *ecdsa.PublicKey:
+		if a.rsa || key.Curve != a.curve {
+			return false
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return false
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(key, digest, r, s)
+	case *rsa.PublicKey:
+		return a.rsa && rsa.VerifyPKCS1v15(key, a.hash, digest, signature) == nil
+	}
+	return false
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil || len(b) == 0 {
+		return nil, fmt.Errorf("некорректное значение %q", s)
+	}
+	return new(big.Int).SetBytes(b), nil
+}
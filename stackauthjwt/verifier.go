@@ -0,0 +1,321 @@
+// Package stackauthjwt проверяет access token Stack Auth локально, без запроса к API на каждый вызов.
+//
+// Verifier загружает набор открытых ключей проекта (JWKS), кэширует его и проверяет подпись,
+// издателя, получателя (идентификатор проекта) и срок действия токена:
+//
+//	verifier, err := stackauthjwt.NewVerifier(stackauthjwt.Config{ProjectID: "project-id"})
+//	...
+//	claims, err := verifier.Verify(ctx, accessToken)
+//	if errors.Is(err, base_http_client.ErrAccessTokenExpired) {
+//		// токен нужно обновить
+//	}
+//
+// Токен с неизвестным идентификатором ключа приводит к повторной загрузке набора ключей,
+// поэтому ротация ключей на стороне Stack Auth не требует перезапуска приложения.
+package stackauthjwt
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+const (
+	// DefaultCacheTTL - время, в течение которого загруженный набор ключей считается актуальным
+	DefaultCacheTTL = time.Hour
+	// DefaultMinRefreshInterval - минимальный интервал между загрузками набора ключей
+	DefaultMinRefreshInterval = time.Minute
+	// DefaultHTTPTimeout - таймаут загрузки набора ключей, если HTTPClient не задан
+	DefaultHTTPTimeout = 10 * time.Second
+)
+
+var (
+	// ErrMalformedToken возвращается для токена, который не удалось разобрать.
+	// Совпадает с кодом ошибки API, поэтому errors.Is одинаково работает для локальной и удаленной проверки
+	ErrMalformedToken = base_http_client.ErrUnparsableAccessToken
+	// ErrTokenExpired возвращается для токена с истекшим сроком действия
+	ErrTokenExpired = base_http_client.ErrAccessTokenExpired
+	// ErrTokenNotYetValid возвращается для токена, время действия которого еще не наступило
+	ErrTokenNotYetValid = errors.New("stackauthjwt: токен еще не действителен")
+	// ErrUnsupportedAlgorithm возвращается для токена, подписанного неподдерживаемым алгоритмом
+	ErrUnsupportedAlgorithm = errors.New("stackauthjwt: неподдерживаемый алгоритм подписи")
+	// ErrUnknownKey возвращается, если ключ подписи токена отсутствует в наборе ключей проекта
+	ErrUnknownKey = errors.New("stackauthjwt: неизвестный ключ подписи")
+	// ErrInvalidSignature возвращается для токена с неверной подписью
+	ErrInvalidSignature = errors.New("stackauthjwt: неверная подпись токена")
+	// ErrInvalidIssuer возвращается для токена другого издателя
+	ErrInvalidIssuer = errors.New("stackauthjwt: неверный издатель токена")
+	// ErrInvalidAudience возвращается для токена, выпущенного для другого проекта
+	ErrInvalidAudience = errors.New("stackauthjwt: токен выпущен для другого получателя")
+	// ErrAnonymousUser возвращается для токена анонимного пользователя, если Config.AllowAnonymous не включен
+	ErrAnonymousUser = errors.New("stackauthjwt: токен анонимного пользователя")
+	// ErrJWKSUnavailable возвращается, если набор ключей не удалось загрузить
+	ErrJWKSUnavailable = errors.New("stackauthjwt: набор ключей недоступен")
+)
+
+// Config содержит параметры проверки токенов
+type Config struct {
+	// ProjectID - идентификатор проекта, обязательный параметр
+	ProjectID string
+	// BaseURL - базовый URL API. По умолчанию base_http_client.DefaultBaseURL
+	BaseURL string
+	// JWKSURL - адрес набора ключей. По умолчанию BaseURL + "/projects/<ProjectID>/.well-known/jwks.json"
+	JWKSURL string
+	// Issuer - ожидаемый издатель токенов. По умолчанию BaseURL + "/projects/<ProjectID>"
+	Issuer string
+	// Audience - допустимые получатели токенов. По умолчанию ProjectID
+	Audience []string
+	// AllowAnonymous разрешает токены анонимных пользователей
+	AllowAnonymous bool
+	// HTTPClient - клиент для загрузки набора ключей. По умолчанию клиент с таймаутом DefaultHTTPTimeout
+	HTTPClient *http.Client
+	// CacheTTL - время актуальности набора ключей. По умолчанию DefaultCacheTTL
+	CacheTTL time.Duration
+	// MinRefreshInterval - минимальный интервал между загрузками набора ключей. По умолчанию DefaultMinRefreshInterval
+	MinRefreshInterval time.Duration
+	// Leeway - допустимое расхождение часов при проверке exp и nbf
+	Leeway time.Duration
+	// Now - источник текущего времени. По умолчанию time.Now
+	Now func() time.Time
+}
+
+// ConfigFromClient возвращает параметры проверки для проекта, базового URL и HTTP-клиента конфигурации API-клиента
+func ConfigFromClient(config base_http_client.Config) Config {
+	return Config{
+		ProjectID:  config.ProjectID,
+		BaseURL:    config.BaseURL,
+		HTTPClient: config.HTTPClient,
+	}
+}
+
+// Verifier проверяет access token Stack Auth. Методы Verifier безопасны для конкурентного использования
+type Verifier struct {
+	config    Config
+	issuers   []string
+	audiences []string
+	jwksURL   string
+
+	mu        sync.RWMutex
+	keys      map[string]publicKey
+	fetchedAt time.Time
+	checkedAt time.Time
+
+	// refreshMu объединяет одновременные загрузки набора ключей в одну
+	refreshMu sync.Mutex
+}
+
+// NewVerifier создает Verifier. Набор ключей загружается при первой проверке токена
+func NewVerifier(config Config) (*Verifier, error) {
+	if config.ProjectID == "" {
+		return nil, errors.New("stackauthjwt: не задан ProjectID")
+	}
+	if config.BaseURL == "" {
+		config.BaseURL = base_http_client.DefaultBaseURL
+	}
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+	if config.HTTPClient == nil {
+		config.HTTPClient = &http.Client{Timeout: DefaultHTTPTimeout}
+	}
+	if config.CacheTTL <= 0 {
+		config.CacheTTL = DefaultCacheTTL
+	}
+	if config.MinRefreshInterval <= 0 {
+		config.MinRefreshInterval = DefaultMinRefreshInterval
+	}
+	if config.Now == nil {
+		config.Now = time.Now
+	}
+
+	projectPath := url.PathEscape(config.ProjectID)
+	v := &Verifier{config: config, jwksURL: config.JWKSURL}
+	if v.jwksURL == "" {
+		v.jwksURL = config.BaseURL + "/projects/" + projectPath + "/.well-known/jwks.json"
+		if config.AllowAnonymous {
+			v.jwksURL += "?include_anonymous=true"
+		}
+	}
+
+	if config.Issuer != "" {
+		v.issuers = []string{config.Issuer}
+	} else {
+		v.issuers = []string{config.BaseURL + "/projects/" + projectPath}
+		if config.AllowAnonymous {
+			v.issuers = append(v.issuers, config.BaseURL+"/projects-anonymous-users/"+projectPath)
+		}
+	}
+
+	if len(config.Audience) > 0 {
+		v.audiences = config.Audience
+	} else {
+		v.audiences = []string{config.ProjectID}
+		if config.AllowAnonymous {
+			v.audiences = append(v.audiences, config.ProjectID+":anon")
+		}
+	}
+	return v, nil
+}
+
+// header - заголовок токена
+type header struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+// Verify проверяет подпись, издателя, получателя и срок действия токена и возвращает его утверждения
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: токен должен состоять из трех частей", ErrMalformedToken)
+	}
+	rawHeader, errHeader := base64.RawURLEncoding.DecodeString(parts[0])
+	rawPayload, errPayload := base64.RawURLEncoding.DecodeString(parts[1])
+	signature, errSignature := base64.RawURLEncoding.DecodeString(parts[2])
+	if errHeader != nil || errPayload != nil || errSignature != nil {
+		return nil, fmt.Errorf("%w: некорректная кодировка base64url", ErrMalformedToken)
+	}
+
+	var h header
+	if err := json.Unmarshal(rawHeader, &h); err != nil {
+		return nil, fmt.Errorf("%w: некорректный заголовок: %v", ErrMalformedToken, err)
+	}
+	if _, ok := algorithms[h.Algorithm]; !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, h.Algorithm)
+	}
+
+	keys, err := v.keysFor(ctx, h.KeyID)
+	if err != nil {
+		return nil, err
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	if !slices.ContainsFunc(keys, func(k publicKey) bool { return k.verify(h.Algorithm, signed, signature) }) {
+		return nil, ErrInvalidSignature
+	}
+
+	claims, err := parseClaims(rawPayload)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.validate(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// validate проверяет утверждения токена
+func (v *Verifier) validate(claims *Claims) error {
+	now := v.config.Now()
+	if !now.Before(claims.ExpiresAt.Add(v.config.Leeway)) {
+		return fmt.Errorf("%w: истек %s", ErrTokenExpired, claims.ExpiresAt.Format(time.RFC3339))
+	}
+	if !claims.NotBefore.IsZero() && now.Add(v.config.Leeway).Before(claims.NotBefore) {
+		return fmt.Errorf("%w: действителен с %s", ErrTokenNotYetValid, claims.NotBefore.Format(time.RFC3339))
+	}
+	if !slices.Contains(v.issuers, claims.Issuer) {
+		return fmt.Errorf("%w: %q", ErrInvalidIssuer, claims.Issuer)
+	}
+	if !slices.ContainsFunc(claims.Audience, func(audience string) bool { return slices.Contains(v.audiences, audience) }) {
+		return fmt.Errorf("%w: %q", ErrInvalidAudience, claims.Audience)
+	}
+	if claims.IsAnonymous && !v.config.AllowAnonymous {
+		return ErrAnonymousUser
+	}
+	if claims.UserID == "" {
+		return fmt.Errorf("%w: отсутствует утверждение sub", ErrMalformedToken)
+	}
+	return nil
+}
+
+// keysFor возвращает ключи, которыми мог быть подписан токен. Пустой kid подходит к любому ключу.
+//
+// Набор ключей загружается повторно, если он устарел или не содержит kid, но не чаще MinRefreshInterval.
+// Если загрузка не удалась, используется ранее загруженный набор. Неудачная первая загрузка тоже
+// повторяется не чаще MinRefreshInterval, а до этого возвращается ErrJWKSUnavailable.
+func (v *Verifier) keysFor(ctx context.Context, kid string) ([]publicKey, error) {
+	keys, fetchedAt, checkedAt := v.snapshot()
+	now := v.config.Now()
+	fresh := keys != nil && now.Sub(fetchedAt) < v.config.CacheTTL
+	found := len(lookup(keys, kid)) > 0
+
+	var refreshErr error
+	if (!fresh || !found) && now.Sub(checkedAt) >= v.config.MinRefreshInterval {
+		refreshErr = v.refresh(ctx, checkedAt)
+		keys, _, _ = v.snapshot()
+	}
+	if keys == nil {
+		if refreshErr == nil {
+			refreshErr = fmt.Errorf("%w: предыдущая загрузка не удалась", ErrJWKSUnavailable)
+		}
+		return nil, refreshErr
+	}
+
+	if matched := lookup(keys, kid); len(matched) > 0 {
+		return matched, nil
+	}
+	if refreshErr != nil {
+		return nil, refreshErr
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+}
+
+// Refresh принудительно загружает набор ключей
+func (v *Verifier) Refresh(ctx context.Context) error {
+	_, _, checkedAt := v.snapshot()
+	return v.refresh(ctx, checkedAt)
+}
+
+// refresh загружает набор ключей, если после проверки checkedAt его никто не загрузил
+func (v *Verifier) refresh(ctx context.Context, checkedAt time.Time) error {
+	v.refreshMu.Lock()
+	defer v.refreshMu.Unlock()
+
+	if _, fetchedAt, current := v.snapshot(); !current.Equal(checkedAt) {
+		if fetchedAt.Equal(current) {
+			return nil
+		}
+		return fmt.Errorf("%w: предыдущая загрузка не удалась", ErrJWKSUnavailable)
+	}
+
+	keys, err := fetchJWKS(ctx, v.config.HTTPClient, v.jwksURL)
+	now := v.config.Now()
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.checkedAt = now
+	if err != nil {
+		return err
+	}
+	v.keys = keys
+	v.fetchedAt = now
+	return nil
+}
+
+func (v *Verifier) snapshot() (map[string]publicKey, time.Time, time.Time) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.keys, v.fetchedAt, v.checkedAt
+}
+
+// lookup возвращает ключ с идентификатором kid или все ключи, если kid пуст
+func lookup(keys map[string]publicKey, kid string) []publicKey {
+	if kid != "" {
+		if key, ok := keys[kid]; ok {
+			return []publicKey{key}
+		}
+		return nil
+	}
+	all := make([]publicKey, 0, len(keys))
+	for _, key := range keys {
+		all = append(all, key)
+	}
+	return all
+}
@@ -0,0 +1,358 @@
+package stackauthjwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+	"github.com/BlaisePopov/stack-auth/stackauthtest"
+	"github.com/stretchr/testify/assert"
+)
+
+const testProjectID = "project-1"
+
+// testKey - ключ подписи тестового издателя
+type testKey struct {
+	id  string
+	alg string
+	ec  *ecdsa.PrivateKey
+	rsa *rsa.PrivateKey
+}
+
+func newECKey(id string) testKey {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	return testKey{id: id, alg: "ES256", ec: key}
+}
+
+func newRSAKey(id string) testKey {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	return testKey{id: id, alg: "RS256", rsa: key}
+}
+
+func (k testKey) jwk() map[string]string {
+	encode := func(n *big.Int, size int) string {
+		return base64.RawURLEncoding.EncodeToString(n.FillBytes(make([]byte, size)))
+	}
+	if k.ec != nil {
+		return map[string]string{"kty": "EC", "crv": "P-256", "kid": k.id, "x": encode(k.ec.X, 32), "y": encode(k.ec.Y, 32)}
+	}
+	return map[string]string{"kty": "RSA", "kid": k.id, "n": encode(k.rsa.N, k.rsa.Size()), "e": encode(big.NewInt(int64(k.rsa.E)), 3)}
+}
+
+func (k testKey) sign(claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": k.alg, "kid": k.id})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+
+	var signature []byte
+	if k.ec != nil {
+		r, s, _ := ecdsa.Sign(rand.Reader, k.ec, digest[:])
+		signature = append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	} else {
+		signature, _ = rsa.SignPKCS1v15(rand.Reader, k.rsa, crypto.SHA256, digest[:])
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// jwksServer - локальный сервер набора ключей, считающий запросы
+type jwksServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	keys     []testKey
+	fail     bool
+	requests atomic.Int32
+}
+
+func newJWKSServer(keys ...testKey) *jwksServer {
+	s := &jwksServer{keys: keys}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.fail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		set := []map[string]string{}
+		for _, k := range s.keys {
+			set = append(set, k.jwk())
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": set})
+	}))
+	return s
+}
+
+func (s *jwksServer) setKeys(keys ...testKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+}
+
+func (s *jwksServer) setFail(fail bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fail = fail
+}
+
+// testClock - управляемые часы
+type testClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func claimsAt(now time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"sub":              "user-1",
+		"iss":              "https://api.example.com/api/v1/projects/" + testProjectID,
+		"aud":              testProjectID,
+		"iat":              now.Unix(),
+		"exp":              now.Add(10 * time.Minute).Unix(),
+		"refresh_token_id": "refresh-1",
+		"selected_team_id": "team-1",
+		"role":             "authenticated",
+		"project_id":       testProjectID,
+	}
+}
+
+func newTestVerifier(t *testing.T, jwks *jwksServer, clock *testClock) *Verifier {
+	verifier, err := NewVerifier(Config{
+		ProjectID: testProjectID,
+		BaseURL:   "https://api.example.com/api/v1",
+		JWKSURL:   jwks.URL,
+		Now:       clock.Now,
+	})
+	assert.NoError(t, err)
+	return verifier
+}
+
+func TestVerify_ValidTokens(t *testing.T) {
+	ecKey, rsaKey := newECKey("ec"), newRSAKey("rsa")
+	jwks := newJWKSServer(ecKey, rsaKey)
+	defer jwks.Close()
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	verifier := newTestVerifier(t, jwks, clock)
+
+	for _, key := range []testKey{ecKey, rsaKey} {
+		claims, err := verifier.Verify(context.Background(), key.sign(claimsAt(clock.Now())))
+		assert.NoError(t, err, key.alg)
+		if assert.NotNil(t, claims) {
+			assert.Equal(t, "user-1", claims.UserID)
+			assert.Equal(t, "refresh-1", claims.RefreshTokenID)
+			assert.Equal(t, "team-1", claims.SelectedTeamID)
+			assert.Equal(t, "authenticated", claims.Role)
+			assert.Equal(t, []string{testProjectID}, claims.Audience)
+			assert.Equal(t, clock.Now(), claims.IssuedAt)
+			assert.Equal(t, clock.Now().Add(10*time.Minute), claims.ExpiresAt)
+		}
+	}
+	assert.Equal(t, int32(1), jwks.requests.Load())
+}
+
+func TestParseUnverified(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	claims, err := ParseUnverified(newECKey("unknown").sign(claimsAt(now)))
+	assert.NoError(t, err)
+	if assert.NotNil(t, claims) {
+		assert.Equal(t, "user-1", claims.UserID)
+		assert.Equal(t, now.Add(10*time.Minute), claims.ExpiresAt)
+	}
+
+	_, err = ParseUnverified("not-a-token")
+	assert.ErrorIs(t, err, ErrMalformedToken)
+}
+
+func TestVerify_RejectsInvalidTokens(t *testing.T) {
+	key := newECKey("ec")
+	jwks := newJWKSServer(key)
+	defer jwks.Close()
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	verifier := newTestVerifier(t, jwks, clock)
+
+	with := func(name string, value interface{}) map[string]interface{} {
+		claims := claimsAt(clock.Now())
+		claims[name] = value
+		return claims
+	}
+	valid := key.sign(claimsAt(clock.Now()))
+	tampered := valid[:len(valid)-4] + "AAAA"
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{"malformed", "not-a-token", base_http_client.ErrUnparsableAccessToken},
+		{"alg none", base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + ".e30.", ErrUnsupportedAlgorithm},
+		{"signature", tampered, ErrInvalidSignature},
+		{"foreign key", newECKey("ec").sign(claimsAt(clock.Now())), ErrInvalidSignature},
+		{"expired", key.sign(with("exp", clock.Now().Add(-time.Second).Unix())), base_http_client.ErrAccessTokenExpired},
+		{"not before", key.sign(with("nbf", clock.Now().Add(time.Hour).Unix())), ErrTokenNotYetValid},
+		{"issuer", key.sign(with("iss", "https://evil.example.com")), ErrInvalidIssuer},
+		{"audience", key.sign(with("aud", []string{"other-project"})), ErrInvalidAudience},
+		{"anonymous", key.sign(with("is_anonymous", true)), ErrAnonymousUser},
+		{"no exp", key.sign(with("exp", nil)), ErrMalformedToken},
+	}
+	for _, tt := range tests {
+		_, err := verifier.Verify(context.Background(), tt.token)
+		assert.ErrorIs(t, err, tt.err, tt.name)
+	}
+}
+
+func TestVerify_KeyRotation(t *testing.T) {
+	oldKey, newKey := newECKey("old"), newECKey("new")
+	jwks := newJWKSServer(oldKey)
+	defer jwks.Close()
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	verifier := newTestVerifier(t, jwks, clock)
+
+	_, err := verifier.Verify(context.Background(), oldKey.sign(claimsAt(clock.Now())))
+	assert.NoError(t, err)
+
+	// Неизвестный ключ не вызывает загрузку чаще MinRefreshInterval
+	_, err = verifier.Verify(context.Background(), newKey.sign(claimsAt(clock.Now())))
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	assert.Equal(t, int32(1), jwks.requests.Load())
+
+	jwks.setKeys(oldKey, newKey)
+	clock.Advance(DefaultMinRefreshInterval)
+	_, err = verifier.Verify(context.Background(), newKey.sign(claimsAt(clock.Now())))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), jwks.requests.Load())
+
+	_, err = verifier.Verify(context.Background(), oldKey.sign(claimsAt(clock.Now())))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), jwks.requests.Load())
+}
+
+func TestVerify_CacheExpiryAndOutage(t *testing.T) {
+	key := newECKey("ec")
+	jwks := newJWKSServer(key)
+	defer jwks.Close()
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	verifier := newTestVerifier(t, jwks, clock)
+
+	_, err := verifier.Verify(context.Background(), key.sign(claimsAt(clock.Now())))
+	assert.NoError(t, err)
+
+	// Устаревший набор ключей используется, пока JWKS недоступен
+	jwks.setFail(true)
+	clock.Advance(DefaultCacheTTL)
+	_, err = verifier.Verify(context.Background(), key.sign(claimsAt(clock.Now())))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), jwks.requests.Load())
+
+	jwks.setFail(false)
+	clock.Advance(DefaultMinRefreshInterval)
+	_, err = verifier.Verify(context.Background(), key.sign(claimsAt(clock.Now())))
+	assert.NoError(t, err)
+	assert.Equal(t, int32(3), jwks.requests.Load())
+}
+
+func TestVerify_JWKSUnavailable(t *testing.T) {
+	jwks := newJWKSServer()
+	jwks.setFail(true)
+	defer jwks.Close()
+	verifier := newTestVerifier(t, jwks, &testClock{now: time.Now()})
+
+	_, err := verifier.Verify(context.Background(), newECKey("ec").sign(claimsAt(time.Now())))
+	assert.ErrorIs(t, err, ErrJWKSUnavailable)
+}
+
+func TestVerify_FailedInitialLoadRespectsMinRefreshInterval(t *testing.T) {
+	key := newECKey("ec")
+	jwks := newJWKSServer(key)
+	jwks.setFail(true)
+	defer jwks.Close()
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	verifier := newTestVerifier(t, jwks, clock)
+	token := key.sign(claimsAt(clock.Now()))
+
+	for i := 0; i < 3; i++ {
+		_, err := verifier.Verify(context.Background(), token)
+		assert.ErrorIs(t, err, ErrJWKSUnavailable)
+	}
+	assert.Equal(t, int32(1), jwks.requests.Load())
+
+	jwks.setFail(false)
+	clock.Advance(DefaultMinRefreshInterval)
+	_, err := verifier.Verify(context.Background(), token)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), jwks.requests.Load())
+}
+
+func TestVerify_ConcurrentRefreshDeduplicated(t *testing.T) {
+	key := newECKey("ec")
+	jwks := newJWKSServer(key)
+	defer jwks.Close()
+	clock := &testClock{now: time.Unix(1700000000, 0)}
+	verifier := newTestVerifier(t, jwks, clock)
+	token := key.sign(claimsAt(clock.Now()))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := verifier.Verify(context.Background(), token)
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), jwks.requests.Load())
+}
+
+func TestVerify_StackAuthTestServer(t *testing.T) {
+	server := stackauthtest.NewServer()
+	defer server.Close()
+
+	userID := server.CreateUser("john@example.com", "")
+	accessToken, _, err := server.SignIn(userID)
+	assert.NoError(t, err)
+
+	verifier, err := NewVerifier(ConfigFromClient(server.Config()))
+	assert.NoError(t, err)
+	claims, err := verifier.Verify(context.Background(), accessToken)
+	assert.NoError(t, err)
+	if assert.NotNil(t, claims) {
+		assert.Equal(t, userID, claims.UserID)
+		assert.Equal(t, server.Issuer(), claims.Issuer)
+		assert.Equal(t, "john@example.com", claims.Email)
+		assert.NotEmpty(t, claims.RefreshTokenID)
+	}
+
+	server.RotateSigningKey()
+	rotated, _, err := server.SignIn(userID)
+	assert.NoError(t, err)
+	_, err = verifier.Verify(context.Background(), rotated)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	assert.NoError(t, verifier.Refresh(context.Background()))
+	_, err = verifier.Verify(context.Background(), rotated)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,289 @@
+package stackauthtest
+
+import (
+	"net/http"
+	"time"
+
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+// Коды ошибок выключенных способов входа
+var (
+	errPasswordAuthNotEnabled = base_http_client.ErrorCode("PASSWORD_AUTHENTICATION_NOT_ENABLED")
+	errOTPAuthNotEnabled      = base_http_client.ErrorCode("OTP_AUTHENTICATION_NOT_ENABLED")
+)
+
+// sendResetCodeMessage - ответ на запрос кода сброса пароля, не раскрывающий наличие пользователя
+const sendResetCodeMessage = "maybe, only if user with e-mail exists"
+
+// tokens - ответ операций, создающих сессию
+type tokens struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	UserID       string `json:"user_id,omitempty"`
+}
+
+// signInUser создает сессию пользователя со временем жизни по умолчанию
+func (s *Server) signInUser(u *user) tokens {
+	sess, accessToken := s.startSession(u.ID, DefaultRefreshTokenTTL)
+	return tokens{AccessToken: accessToken, RefreshToken: sess.refreshToken, UserID: u.ID}
+}
+
+// requirePasswordAuth проверяет, что вход по паролю включен в проекте
+func (s *Server) requirePasswordAuth() error {
+	if !s.projectConfig.CredentialEnabled {
+		return newError(http.StatusBadRequest, errPasswordAuthNotEnabled, "Вход по паролю отключен в проекте")
+	}
+	return nil
+}
+
+// sessionByRefreshToken возвращает действующую сессию по refresh token
+func (s *Server) sessionByRefreshToken(refreshToken string) (*session, error) {
+	sess := s.store.sessions.get(s.store.refreshTokens[refreshToken])
+	if sess != nil && !s.now().Before(sess.expiresAt) {
+		s.store.deleteSession(sess)
+		sess = nil
+	}
+	if sess == nil {
+		return nil, newError(http.StatusUnauthorized, base_http_client.ErrRefreshTokenNotFoundOrExpired, "Refresh token не найден или истек")
+	}
+	return sess, nil
+}
+
+func (s *Server) passwordSignUp(r *http.Request, c *caller) (interface{}, error) {
+	if err := s.requirePasswordAuth(); err != nil {
+		return nil, err
+	}
+	if !s.projectConfig.SignUpEnabled {
+		return nil, newError(http.StatusBadRequest, base_http_client.ErrSignUpNotEnabled, "Регистрация отключена в проекте")
+	}
+	var request struct {
+		Email                   string `json:"email"`
+		Password                string `json:"password"`
+		VerificationCallbackURL string `json:"verification_callback_url"`
+	}
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	if request.Email == "" {
+		return nil, schemaError("Поле email обязательно")
+	}
+	if err := validatePassword(request.Password); err != nil {
+		return nil, err
+	}
+	if err := s.checkEmailAvailable(request.Email, ""); err != nil {
+		return nil, err
+	}
+
+	u := &user{PrimaryEmail: request.Email, PrimaryEmailAuthEnabled: true, password: request.Password}
+	s.store.createUser(u, s.now())
+	if request.VerificationCallbackURL != "" {
+		channel := s.store.primaryChannel(u.ID)
+		s.sendCode(&verificationCode{emailType: EmailVerification, email: channel.Value, userID: u.ID, channelID: channel.ID}, request.VerificationCallbackURL)
+	}
+	return s.signInUser(u), nil
+}
+
+func (s *Server) passwordSignIn(r *http.Request, c *caller) (interface{}, error) {
+	if err := s.requirePasswordAuth(); err != nil {
+		return nil, err
+	}
+	var request struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	u := s.store.userByEmail(request.Email)
+	if u == nil || !u.PrimaryEmailAuthEnabled || u.password == "" || u.password != request.Password {
+		return nil, newError(http.StatusBadRequest, base_http_client.ErrEmailPasswordMismatch, "Неверный email или пароль")
+	}
+	return s.signInUser(u), nil
+}
+
+func (s *Server) passwordUpdate(r *http.Request, c *caller) (interface{}, error) {
+	u, err := requireUser(c)
+	if err != nil {
+		return nil, err
+	}
+	var request struct {
+		OldPassword string `json:"old_password"`
+		NewPassword string `json:"new_password"`
+	}
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	if u.password == "" {
+		return nil, newError(http.StatusBadRequest, base_http_client.ErrUserDoesNotHavePassword, "У пользователя не задан пароль")
+	}
+	if u.password != request.OldPassword {
+		return nil, newError(http.StatusBadRequest, base_http_client.ErrPasswordConfirmationMismatch, "Текущий пароль указан неверно")
+	}
+	if err := validatePassword(request.NewPassword); err != nil {
+		return nil, err
+	}
+	u.password = request.NewPassword
+	return success{Success: true}, nil
+}
+
+func (s *Server) passwordSet(r *http.Request, c *caller) (interface{}, error) {
+	u, err := requireUser(c)
+	if err != nil {
+		return nil, err
+	}
+	var request struct {
+		Password string `json:"password"`
+	}
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	if err := validatePassword(request.Password); err != nil {
+		return nil, err
+	}
+	u.password = request.Password
+	return success{Success: true}, nil
+}
+
+func (s *Server) passwordSendResetCode(r *http.Request, c *caller) (interface{}, error) {
+	if err := s.requirePasswordAuth(); err != nil {
+		return nil, err
+	}
+	var request struct {
+		Email       string `json:"email"`
+		CallbackURL string `json:"callback_url"`
+	}
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	if u := s.store.userByEmail(request.Email); u != nil && u.PrimaryEmailAuthEnabled {
+		s.sendCode(&verificationCode{emailType: EmailPasswordReset, email: u.PrimaryEmail, userID: u.ID}, request.CallbackURL)
+	}
+	return map[string]string{"success": sendResetCodeMessage}, nil
+}
+
+func (s *Server) passwordReset(r *http.Request, c *caller) (interface{}, error) {
+	var request struct {
+		Password string `json:"password"`
+		Code     string `json:"code"`
+	}
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	code, err := s.lookupCode(request.Code, EmailPasswordReset)
+	if err != nil {
+		return nil, err
+	}
+	if err := validatePassword(request.Password); err != nil {
+		return nil, err
+	}
+	code.used = true
+	if u := s.store.users.get(code.userID); u != nil {
+		u.password = request.Password
+	}
+	return success{Success: true}, nil
+}
+
+func (s *Server) passwordCheckResetCode(r *http.Request, c *caller) (interface{}, error) {
+	return s.checkCode(r, EmailPasswordReset)
+}
+
+func (s *Server) otpSendSignInCode(r *http.Request, c *caller) (interface{}, error) {
+	if !s.projectConfig.MagicLinkEnabled {
+		return nil, newError(http.StatusBadRequest, errOTPAuthNotEnabled, "Вход по одноразовому коду отключен в проекте")
+	}
+	var request struct {
+		Email       string `json:"email"`
+		CallbackURL string `json:"callback_url"`
+	}
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	if request.Email == "" || request.CallbackURL == "" {
+		return nil, schemaError("Поля email и callback_url обязательны")
+	}
+	s.sendCode(&verificationCode{emailType: EmailSignInCode, email: request.Email}, request.CallbackURL)
+	return map[string]string{"nonce": newSecret("")}, nil
+}
+
+func (s *Server) otpSignIn(r *http.Request, c *caller) (interface{}, error) {
+	var request struct {
+		Code string `json:"code"`
+	}
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	code, err := s.lookupCode(request.Code, EmailSignInCode)
+	if err != nil {
+		return nil, err
+	}
+
+	isNewUser := false
+	u := s.store.userByEmail(code.email)
+	if u == nil {
+		if !s.projectConfig.SignUpEnabled {
+			return nil, newError(http.StatusBadRequest, base_http_client.ErrSignUpNotEnabled, "Регистрация отключена в проекте")
+		}
+		u = &user{PrimaryEmail: code.email, PrimaryEmailVerified: true, PrimaryEmailAuthEnabled: true}
+		s.store.createUser(u, s.now())
+		isNewUser = true
+	}
+	code.used = true
+
+	result := s.signInUser(u)
+	return map[string]interface{}{
+		"access_token":  result.AccessToken,
+		"refresh_token": result.RefreshToken,
+		"user_id":       result.UserID,
+		"is_new_user":   isNewUser,
+	}, nil
+}
+
+func (s *Server) otpCheckSignInCode(r *http.Request, c *caller) (interface{}, error) {
+	return s.checkCode(r, EmailSignInCode)
+}
+
+func (s *Server) createSession(r *http.Request, c *caller) (interface{}, error) {
+	if err := requireServer(c); err != nil {
+		return nil, err
+	}
+	var request struct {
+		UserID          string `json:"user_id"`
+		ExpiresInMillis *int64 `json:"expires_in_millis"`
+	}
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	if s.store.users.get(request.UserID) == nil {
+		return nil, newError(http.StatusBadRequest, base_http_client.ErrUserIDDoesNotExist, "Пользователь %s не существует", request.UserID)
+	}
+	ttl := DefaultRefreshTokenTTL
+	if request.ExpiresInMillis != nil {
+		ttl = time.Duration(*request.ExpiresInMillis) * time.Millisecond
+	}
+	sess, accessToken := s.startSession(request.UserID, ttl)
+	return tokens{AccessToken: accessToken, RefreshToken: sess.refreshToken}, nil
+}
+
+func (s *Server) signOut(r *http.Request, c *caller) (interface{}, error) {
+	sess := c.session
+	if refreshToken := r.Header.Get("X-Stack-Refresh-Token"); refreshToken != "" {
+		var err error
+		if sess, err = s.sessionByRefreshToken(refreshToken); err != nil {
+			return nil, err
+		}
+	}
+	if sess == nil {
+		return nil, newError(http.StatusUnauthorized, base_http_client.ErrRefreshTokenNotFoundOrExpired, "Не передан refresh token текущей сессии")
+	}
+	s.store.deleteSession(sess)
+	return success{Success: true}, nil
+}
+
+func (s *Server) refreshAccessToken(r *http.Request, c *caller) (interface{}, error) {
+	sess, err := s.sessionByRefreshToken(r.Header.Get("X-Stack-Refresh-Token"))
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"access_token": s.issueAccessToken(sess)}, nil
+}
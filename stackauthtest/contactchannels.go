@@ -0,0 +1,208 @@
+package stackauthtest
+
+import (
+	"net/http"
+	"strings"
+
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+// contactChannelRequest - тело запросов создания и обновления контактного канала
+type contactChannelRequest struct {
+	UserID      string  `json:"user_id"`
+	Value       *string `json:"value"`
+	Type        *string `json:"type"`
+	UsedForAuth *bool   `json:"used_for_auth"`
+	IsVerified  *bool   `json:"is_verified"`
+	IsPrimary   *bool   `json:"is_primary"`
+}
+
+// channelOwner возвращает идентификатор пользователя, к каналам которого обращается запрос.
+// Клиент может обращаться только к собственным каналам
+func channelOwner(c *caller, userID string) (string, error) {
+	if !c.isServer() {
+		if _, err := requireUser(c); err != nil {
+			return "", err
+		}
+		if userID == "" {
+			userID = "me"
+		}
+	}
+	userID, err := resolveUserID(c, userID)
+	if err != nil {
+		return "", err
+	}
+	if !c.isServer() && userID != c.user.ID {
+		return "", schemaError("Клиент может обращаться только к собственным контактным каналам (user_id=me)")
+	}
+	return userID, nil
+}
+
+// channelByPath возвращает контактный канал по идентификаторам из пути
+func (s *Server) channelByPath(r *http.Request, c *caller) (*contactChannel, error) {
+	userID, err := channelOwner(c, r.PathValue("user_id"))
+	if err != nil {
+		return nil, err
+	}
+	channel := s.store.contactChannels.get(r.PathValue("contact_channel_id"))
+	if channel == nil || channel.UserID != userID {
+		return nil, newError(http.StatusNotFound, base_http_client.ErrContactChannelNotFound, "Контактный канал %s не найден", r.PathValue("contact_channel_id"))
+	}
+	return channel, nil
+}
+
+// applyChannelRequest применяет изменения к контактному каналу и синхронизирует основной email пользователя
+func (s *Server) applyChannelRequest(channel *contactChannel, request *contactChannelRequest, c *caller) error {
+	if request.Type != nil && *request.Type != "email" {
+		return schemaError("Неподдерживаемый тип контактного канала: %q", *request.Type)
+	}
+	if request.IsVerified != nil && !c.isServer() {
+		return schemaError("Поле is_verified доступно только серверу")
+	}
+	if request.Value != nil {
+		for _, other := range s.store.contactChannels.list() {
+			if other.ID != channel.ID && other.UserID == channel.UserID && strings.EqualFold(other.Value, *request.Value) {
+				return schemaError("Контактный канал %s уже существует", *request.Value)
+			}
+		}
+		if channel.Value != *request.Value {
+			channel.Value = *request.Value
+			channel.IsVerified = false
+		}
+	}
+	if request.UsedForAuth != nil {
+		channel.UsedForAuth = *request.UsedForAuth
+	}
+	if request.IsVerified != nil {
+		channel.IsVerified = *request.IsVerified
+	}
+	if request.IsPrimary != nil {
+		if *request.IsPrimary {
+			for _, other := range s.store.contactChannels.list() {
+				if other.UserID == channel.UserID {
+					other.IsPrimary = false
+				}
+			}
+		}
+		channel.IsPrimary = *request.IsPrimary
+	}
+	s.store.syncPrimaryEmail(channel.UserID)
+	return nil
+}
+
+func (s *Server) listContactChannels(r *http.Request, c *caller) (interface{}, error) {
+	query := r.URL.Query()
+	userID, err := channelOwner(c, query.Get("user_id"))
+	if err != nil {
+		return nil, err
+	}
+	channelID := query.Get("contact_channel_id")
+
+	var items []contactChannel
+	for _, channel := range s.store.contactChannels.list() {
+		if (userID == "" || channel.UserID == userID) && (channelID == "" || channel.ID == channelID) {
+			items = append(items, *channel)
+		}
+	}
+	return paginate(r, items, func(channel contactChannel) string { return channel.ID })
+}
+
+func (s *Server) createContactChannel(r *http.Request, c *caller) (interface{}, error) {
+	var request contactChannelRequest
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	userID, err := channelOwner(c, request.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if s.store.users.get(userID) == nil {
+		return nil, newError(http.StatusNotFound, base_http_client.ErrUserNotFound, "Пользователь %s не найден", userID)
+	}
+	if request.Value == nil || *request.Value == "" || request.Type == nil {
+		return nil, schemaError("Поля value и type обязательны")
+	}
+
+	channel := &contactChannel{ID: newID(), UserID: userID}
+	if err := s.applyChannelRequest(channel, &request, c); err != nil {
+		return nil, err
+	}
+	channel.Type = *request.Type
+	s.store.contactChannels.add(channel.ID, channel)
+	s.store.syncPrimaryEmail(userID)
+	return *channel, nil
+}
+
+func (s *Server) getContactChannel(r *http.Request, c *caller) (interface{}, error) {
+	channel, err := s.channelByPath(r, c)
+	if err != nil {
+		return nil, err
+	}
+	return *channel, nil
+}
+
+func (s *Server) updateContactChannel(r *http.Request, c *caller) (interface{}, error) {
+	channel, err := s.channelByPath(r, c)
+	if err != nil {
+		return nil, err
+	}
+	var request contactChannelRequest
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	if err := s.applyChannelRequest(channel, &request, c); err != nil {
+		return nil, err
+	}
+	return *channel, nil
+}
+
+func (s *Server) deleteContactChannel(r *http.Request, c *caller) (interface{}, error) {
+	channel, err := s.channelByPath(r, c)
+	if err != nil {
+		return nil, err
+	}
+	s.store.contactChannels.remove(channel.ID)
+	s.store.syncPrimaryEmail(channel.UserID)
+	return success{Success: true}, nil
+}
+
+func (s *Server) sendContactChannelCode(r *http.Request, c *caller) (interface{}, error) {
+	channel, err := s.channelByPath(r, c)
+	if err != nil {
+		return nil, err
+	}
+	var request struct {
+		CallbackURL string `json:"callback_url"`
+	}
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	if channel.IsVerified {
+		return nil, newError(http.StatusBadRequest, base_http_client.ErrEmailAlreadyVerified, "Email %s уже подтвержден", channel.Value)
+	}
+	s.sendCode(&verificationCode{emailType: EmailVerification, email: channel.Value, userID: channel.UserID, channelID: channel.ID}, request.CallbackURL)
+	return success{Success: true}, nil
+}
+
+func (s *Server) verifyContactChannel(r *http.Request, c *caller) (interface{}, error) {
+	var request struct {
+		Code string `json:"code"`
+	}
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	code, err := s.lookupCode(request.Code, EmailVerification)
+	if err != nil {
+		return nil, err
+	}
+	code.used = true
+	if channel := s.store.contactChannels.get(code.channelID); channel != nil && strings.EqualFold(channel.Value, code.email) {
+		channel.IsVerified = true
+		s.store.syncPrimaryEmail(channel.UserID)
+	}
+	return success{Success: true}, nil
+}
+
+func (s *Server) checkContactChannelCode(r *http.Request, c *caller) (interface{}, error) {
+	return s.checkCode(r, EmailVerification)
+}
@@ -0,0 +1,118 @@
+package stackauthtest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+// signingKey - ключ ES256, которым сервер подписывает access token
+type signingKey struct {
+	id  string
+	key *ecdsa.PrivateKey
+}
+
+func newSigningKey() signingKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		panic(err)
+	}
+	return signingKey{id: newID(), key: key}
+}
+
+// jwk возвращает открытую часть ключа в формате JSON Web Key
+func (k signingKey) jwk() map[string]string {
+	return map[string]string{
+		"kty": "EC",
+		"crv": "P-256",
+		"alg": "ES256",
+		"use": "sig",
+		"kid": k.id,
+		"x":   base64.RawURLEncoding.EncodeToString(k.key.PublicKey.X.FillBytes(make([]byte, 32))),
+		"y":   base64.RawURLEncoding.EncodeToString(k.key.PublicKey.Y.FillBytes(make([]byte, 32))),
+	}
+}
+
+// sign подписывает полезную нагрузку и возвращает токен в компактном формате JWT
+func (k signingKey) sign(claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "ES256", "typ": "JWT", "kid": k.id})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	digest := sha256.Sum256([]byte(signed))
+	r, s, err := ecdsa.Sign(rand.Reader, k.key, digest[:])
+	if err != nil {
+		panic(err)
+	}
+	signature := append(r.FillBytes(make([]byte, 32)), s.FillBytes(make([]byte, 32))...)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// Issuer возвращает издателя, которого сервер указывает в access token
+func (s *Server) Issuer() string {
+	return s.URL() + "/projects/" + s.projectID
+}
+
+// RotateSigningKey переключает подпись access token на новый ключ.
+// Прежние ключи остаются в наборе ключей, поэтому ранее выданные токены продолжают проходить проверку
+func (s *Server) RotateSigningKey() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.signingKeys = append(s.signingKeys, newSigningKey())
+}
+
+// signAccessToken выпускает access token пользователя сессии в формате JWT
+func (s *Server) signAccessToken(sess *session, issuedAt, expiresAt time.Time) string {
+	claims := map[string]interface{}{
+		"sub":              sess.userID,
+		"iss":              s.Issuer(),
+		"aud":              s.projectID,
+		"iat":              issuedAt.Unix(),
+		"exp":              expiresAt.Unix(),
+		"project_id":       s.projectID,
+		"branch_id":        "main",
+		"refresh_token_id": sess.id,
+		"role":             "authenticated",
+	}
+	if u := s.store.users.get(sess.userID); u != nil {
+		claims["name"] = nullable(u.DisplayName)
+		claims["email"] = nullable(u.PrimaryEmail)
+		claims["email_verified"] = u.PrimaryEmailVerified
+		claims["selected_team_id"] = nullable(u.SelectedTeamID)
+		claims["is_anonymous"] = u.IsAnonymous
+	}
+	return s.signingKeys[len(s.signingKeys)-1].sign(claims)
+}
+
+// getJWKS возвращает набор открытых ключей проекта. Эндпоинт не требует учетных данных
+func (s *Server) getJWKS(w http.ResponseWriter, r *http.Request) {
+	if r.PathValue("project_id") != s.projectID {
+		writeError(w, newError(http.StatusNotFound, base_http_client.ErrProjectNotFound, "Проект %q не найден", r.PathValue("project_id")))
+		return
+	}
+
+	s.mu.Lock()
+	keys := make([]map[string]string, 0, len(s.signingKeys))
+	for _, k := range s.signingKeys {
+		keys = append(keys, k.jwk())
+	}
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"keys": keys})
+}
+
+// nullable возвращает nil для пустой строки, чтобы утверждение кодировалось как null
+func nullable(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
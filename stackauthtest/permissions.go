@@ -0,0 +1,87 @@
+package stackauthtest
+
+import (
+	"net/http"
+
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+// teamPermissionView - представление командного разрешения пользователя
+type teamPermissionView struct {
+	ID     string `json:"id"`
+	TeamID string `json:"team_id"`
+	UserID string `json:"user_id"`
+}
+
+func (s *Server) listTeamPermissions(r *http.Request, c *caller) (interface{}, error) {
+	query := r.URL.Query()
+	userID := query.Get("user_id")
+	if !c.isServer() && userID != "me" {
+		return nil, schemaError("Клиент может получить только собственные разрешения (user_id=me)")
+	}
+	userID, err := resolveUserID(c, userID)
+	if err != nil {
+		return nil, err
+	}
+	permissionID := query.Get("permission_id")
+	recursive := query.Get("recursive") == "true"
+
+	var items []teamPermissionView
+	for _, m := range s.store.teamMemberships(query.Get("team_id"), userID) {
+		granted := m.permissions
+		if recursive {
+			granted = s.store.expandPermissions(granted)
+		}
+		for _, id := range sortedKeys(granted) {
+			if permissionID == "" || id == permissionID {
+				items = append(items, teamPermissionView{ID: id, TeamID: m.teamID, UserID: m.userID})
+			}
+		}
+	}
+	return paginate(r, items, func(p teamPermissionView) string { return membershipKey(p.TeamID, p.UserID) + "/" + p.ID })
+}
+
+// permissionMembership проверяет команду, пользователя и разрешение из пути и возвращает членство
+func (s *Server) permissionMembership(r *http.Request, c *caller) (*membership, string, error) {
+	if err := requireServer(c); err != nil {
+		return nil, "", err
+	}
+	t, err := s.teamByID(c, r.PathValue("team_id"))
+	if err != nil {
+		return nil, "", err
+	}
+	u, err := s.userByPath(r)
+	if err != nil {
+		return nil, "", err
+	}
+	m := s.store.membership(t.ID, u.ID)
+	if m == nil {
+		return nil, "", newError(http.StatusNotFound, base_http_client.ErrTeamMembershipNotFound, "Пользователь %s не состоит в команде %s", u.ID, t.ID)
+	}
+	permissionID := r.PathValue("permission_id")
+	if _, ok := s.store.permissions[permissionID]; !ok {
+		return nil, "", newError(http.StatusNotFound, base_http_client.ErrPermissionNotFound, "Разрешение %s не найдено", permissionID)
+	}
+	return m, permissionID, nil
+}
+
+func (s *Server) grantTeamPermission(r *http.Request, c *caller) (interface{}, error) {
+	m, permissionID, err := s.permissionMembership(r, c)
+	if err != nil {
+		return nil, err
+	}
+	m.permissions[permissionID] = true
+	return teamPermissionView{ID: permissionID, TeamID: m.teamID, UserID: m.userID}, nil
+}
+
+func (s *Server) revokeTeamPermission(r *http.Request, c *caller) (interface{}, error) {
+	m, permissionID, err := s.permissionMembership(r, c)
+	if err != nil {
+		return nil, err
+	}
+	if !m.permissions[permissionID] {
+		return nil, newError(http.StatusNotFound, base_http_client.ErrTeamPermissionNotFound, "Разрешение %s не выдано пользователю %s в команде %s", permissionID, m.userID, m.teamID)
+	}
+	delete(m.permissions, permissionID)
+	return success{Success: true}, nil
+}
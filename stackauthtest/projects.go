@@ -0,0 +1,15 @@
+package stackauthtest
+
+import "net/http"
+
+func (s *Server) getAPIInfo(r *http.Request, c *caller) (interface{}, error) {
+	return map[string]string{"message": "Welcome to the Stack API endpoint! Please refer to the documentation at https://docs.stack-auth.com.\n\nAuthentication: " + c.accessType}, nil
+}
+
+func (s *Server) getCurrentProject(r *http.Request, c *caller) (interface{}, error) {
+	return map[string]interface{}{
+		"id":           s.projectID,
+		"display_name": DefaultProjectDisplayName,
+		"config":       s.projectConfig,
+	}, nil
+}
@@ -0,0 +1,488 @@
+// Package stackauthtest содержит fake-сервер Stack Auth для интеграционных тестов.
+//
+// Server запускает httptest.Server, реализующий в памяти эндпоинты, которые покрывает клиент:
+// пользователей, команды, профили и членство участников, приглашения, командные разрешения,
+// контактные каналы, вход по паролю и одноразовому коду, сессии и текущий проект. Сервер проверяет
+// учетные данные проекта и тип доступа, хранит состояние между запросами, выдает курсоры пагинации
+// и отвечает кодами известных ошибок Stack Auth, поэтому тесты могут вызывать настоящий api.Client
+// без сети. Access token выдаются в формате JWT с подписью ES256, а набор ключей публикуется
+// по адресу <URL>/projects/<ProjectID>/.well-known/jwks.json:
+//
+//	server := stackauthtest.NewServer()
+//	defer server.Close()
+//
+//	client := api.NewClient(server.Config())
+//	user, err := client.Users.CreateUser(&users.CreateUserRequest{PrimaryEmail: "john@example.com"})
+//
+// Письма не отправляются: коды подтверждения, входа и приглашений доступны через Server.LastEmail.
+package stackauthtest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+// Учетные данные проекта fake-сервера по умолчанию
+const (
+	DefaultProjectID            = "stackauthtest-project"
+	DefaultProjectDisplayName   = "Stack Auth Test Project"
+	DefaultPublishableClientKey = "pck_stackauthtest"
+	DefaultSecretServerKey      = "ssk_stackauthtest"
+	DefaultSuperSecretAdminKey  = "sak_stackauthtest"
+)
+
+const (
+	// DefaultAccessTokenTTL - время жизни выдаваемых access token
+	DefaultAccessTokenTTL = 10 * time.Minute
+	// DefaultRefreshTokenTTL - время жизни сессии, если при создании не указано другое
+	DefaultRefreshTokenTTL = 365 * 24 * time.Hour
+	// DefaultCodeTTL - время жизни кодов подтверждения, входа и приглашений
+	DefaultCodeTTL = time.Hour
+)
+
+// apiPrefix - префикс пути, под которым сервер обслуживает API
+const apiPrefix = "/api/v1"
+
+// ProjectConfig содержит настройки проекта, влияющие на поведение сервера
+type ProjectConfig struct {
+	ClientTeamCreationEnabled bool `json:"client_team_creation_enabled"`
+	ClientUserDeletionEnabled bool `json:"client_user_deletion_enabled"`
+	CredentialEnabled         bool `json:"credential_enabled"`
+	MagicLinkEnabled          bool `json:"magic_link_enabled"`
+	PasskeyEnabled            bool `json:"passkey_enabled"`
+	SignUpEnabled             bool `json:"sign_up_enabled"`
+}
+
+// DefaultProjectConfig - настройки проекта по умолчанию: разрешены регистрация, вход по паролю и по коду
+var DefaultProjectConfig = ProjectConfig{
+	ClientTeamCreationEnabled: true,
+	ClientUserDeletionEnabled: true,
+	CredentialEnabled:         true,
+	MagicLinkEnabled:          true,
+	SignUpEnabled:             true,
+}
+
+// Option задает параметр fake-сервера
+type Option func(*Server)
+
+// WithProjectID задает идентификатор проекта, который сервер ожидает в заголовке X-Stack-Project-Id
+func WithProjectID(projectID string) Option {
+	return func(s *Server) {
+		s.projectID = projectID
+	}
+}
+
+// WithProjectConfig задает настройки проекта
+func WithProjectConfig(config ProjectConfig) Option {
+	return func(s *Server) {
+		s.projectConfig = config
+	}
+}
+
+// WithAccessTokenTTL задает время жизни выдаваемых access token
+func WithAccessTokenTTL(ttl time.Duration) Option {
+	return func(s *Server) {
+		s.accessTokenTTL = ttl
+	}
+}
+
+// WithClock задает источник текущего времени, например для проверки истечения токенов и кодов
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) {
+		s.now = now
+	}
+}
+
+// Server - fake-сервер Stack Auth, хранящий состояние в памяти.
+// Методы Server безопасны для конкурентного использования.
+type Server struct {
+	server         *httptest.Server
+	projectID      string
+	projectConfig  ProjectConfig
+	accessTokenTTL time.Duration
+	now            func() time.Time
+	requestCount   atomic.Int64
+
+	mu    sync.Mutex
+	store *store
+	// signingKeys - опубликованные ключи подписи access token, последний используется для новых токенов
+	signingKeys []signingKey
+}
+
+// NewServer запускает fake-сервер. Сервер нужно остановить вызовом Close
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		projectID:      DefaultProjectID,
+		projectConfig:  DefaultProjectConfig,
+		accessTokenTTL: DefaultAccessTokenTTL,
+		now:            time.Now,
+		store:          newStore(),
+		signingKeys:    []signingKey{newSigningKey()},
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	mux := http.NewServeMux()
+	s.routes(mux)
+	s.server = httptest.NewServer(mux)
+	return s
+}
+
+// Close останавливает сервер
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// URL возвращает базовый URL API, пригодный для Config.BaseURL
+func (s *Server) URL() string {
+	return s.server.URL + apiPrefix
+}
+
+// ProjectID возвращает идентификатор проекта сервера
+func (s *Server) ProjectID() string {
+	return s.projectID
+}
+
+// Config возвращает конфигурацию клиента с типом доступа "server"
+func (s *Server) Config() base_http_client.Config {
+	return base_http_client.Config{
+		ProjectID:       s.projectID,
+		AccessType:      base_http_client.AccessTypeServer,
+		SecretServerKey: DefaultSecretServerKey,
+		BaseURL:         s.URL(),
+		HTTPClient:      s.server.Client(),
+	}
+}
+
+// ClientConfig возвращает конфигурацию клиента с типом доступа "client" и токенами пользователя
+func (s *Server) ClientConfig(accessToken, refreshToken string) base_http_client.Config {
+	return base_http_client.Config{
+		ProjectID:            s.projectID,
+		AccessType:           base_http_client.AccessTypeClient,
+		PublishableClientKey: DefaultPublishableClientKey,
+		AccessToken:          accessToken,
+		RefreshToken:         refreshToken,
+		BaseURL:              s.URL(),
+		HTTPClient:           s.server.Client(),
+	}
+}
+
+// AdminConfig возвращает конфигурацию клиента с типом доступа "admin"
+func (s *Server) AdminConfig() base_http_client.Config {
+	return base_http_client.Config{
+		ProjectID:           s.projectID,
+		AccessType:          base_http_client.AccessTypeAdmin,
+		SuperSecretAdminKey: DefaultSuperSecretAdminKey,
+		BaseURL:             s.URL(),
+		HTTPClient:          s.server.Client(),
+	}
+}
+
+// apiError - ошибка, которую сервер возвращает в формате известных ошибок Stack Auth
+type apiError struct {
+	status  int
+	code    string
+	message string
+}
+
+func (e *apiError) Error() string {
+	return fmt.Sprintf("[%s] %s", e.code, e.message)
+}
+
+// newError создает ошибку с HTTP-статусом и кодом известной ошибки
+func newError(status int, code error, format string, args ...interface{}) *apiError {
+	return &apiError{status: status, code: code.Error(), message: fmt.Sprintf(format, args...)}
+}
+
+// schemaError создает ошибку валидации запроса
+func schemaError(format string, args ...interface{}) *apiError {
+	return newError(http.StatusBadRequest, base_http_client.ErrSchemaError, format, args...)
+}
+
+// caller описывает аутентифицированного отправителя запроса
+type caller struct {
+	accessType string
+	user       *user
+	session    *session
+	// tokenErr - ошибка проверки access token, возвращаемая операциями, которым нужен пользователь
+	tokenErr error
+}
+
+// isServer сообщает, выполнен ли запрос с серверным или административным ключом
+func (c *caller) isServer() bool {
+	return c.accessType == base_http_client.AccessTypeServer || c.accessType == base_http_client.AccessTypeAdmin
+}
+
+// handlerFunc обрабатывает запрос и возвращает значение для кодирования в JSON
+type handlerFunc func(r *http.Request, c *caller) (interface{}, error)
+
+// handle регистрирует обработчик, выполняемый под блокировкой состояния после проверки учетных данных
+func (s *Server) handle(mux *http.ServeMux, pattern string, handler handlerFunc) {
+	mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Stack-Request-Id", strconv.FormatInt(s.requestCount.Add(1), 10))
+
+		s.mu.Lock()
+		var result interface{}
+		c, err := s.authenticate(r)
+		if err == nil {
+			result, err = handler(r, c)
+		}
+		s.mu.Unlock()
+
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, result)
+	})
+}
+
+// authenticate проверяет учетные данные проекта и токены пользователя
+func (s *Server) authenticate(r *http.Request) (*caller, error) {
+	projectID := r.Header.Get("X-Stack-Project-Id")
+	if projectID == "" {
+		return nil, newError(http.StatusBadRequest, base_http_client.ErrInvalidProjectAuthentication, "Не передан заголовок X-Stack-Project-Id")
+	}
+	if projectID != s.projectID {
+		return nil, newError(http.StatusNotFound, base_http_client.ErrProjectNotFound, "Проект %q не найден", projectID)
+	}
+
+	c := &caller{accessType: r.Header.Get("X-Stack-Access-Type")}
+	switch c.accessType {
+	case base_http_client.AccessTypeClient:
+		if r.Header.Get("X-Stack-Publishable-Client-Key") != DefaultPublishableClientKey {
+			return nil, newError(http.StatusUnauthorized, base_http_client.ErrInvalidPublishableClientKey, "Неверный публичный ключ клиента")
+		}
+	case base_http_client.AccessTypeServer:
+		if r.Header.Get("X-Stack-Secret-Server-Key") != DefaultSecretServerKey {
+			return nil, newError(http.StatusUnauthorized, base_http_client.ErrInvalidSecretServerKey, "Неверный секретный серверный ключ")
+		}
+	case base_http_client.AccessTypeAdmin:
+		if r.Header.Get("X-Stack-Super-Secret-Admin-Key") != DefaultSuperSecretAdminKey {
+			return nil, newError(http.StatusUnauthorized, base_http_client.ErrInvalidSuperSecretAdminKey, "Неверный административный ключ")
+		}
+	default:
+		return nil, newError(http.StatusBadRequest, base_http_client.ErrInvalidProjectAuthentication, "Неизвестный тип доступа %q", c.accessType)
+	}
+
+	if accessToken := r.Header.Get("X-Stack-Access-Token"); accessToken != "" {
+		token, ok := s.store.accessTokens[accessToken]
+		switch {
+		case !ok:
+			c.tokenErr = newError(http.StatusUnauthorized, base_http_client.ErrUnparsableAccessToken, "Access token не распознан")
+		case !s.now().Before(token.expiresAt):
+			c.tokenErr = newError(http.StatusUnauthorized, base_http_client.ErrAccessTokenExpired, "Срок действия access token истек")
+		default:
+			c.user = s.store.users.get(token.userID)
+			c.session = s.store.sessions.get(token.sessionID)
+			if c.user != nil {
+				c.user.LastActiveAtMillis = s.now().UnixMilli()
+			}
+		}
+	}
+	return c, nil
+}
+
+// requireServer возвращает ошибку, если запрос выполнен не с серверным ключом
+func requireServer(c *caller) error {
+	if !c.isServer() {
+		return newError(http.StatusUnauthorized, base_http_client.ErrInsufficientAccessType, "Операция требует тип доступа server")
+	}
+	return nil
+}
+
+// requireUser возвращает текущего пользователя или ошибку, если запрос выполнен без действующего access token
+func requireUser(c *caller) (*user, error) {
+	if c.tokenErr != nil {
+		return nil, c.tokenErr
+	}
+	if c.user == nil {
+		return nil, newError(http.StatusUnauthorized, base_http_client.ErrUserAuthenticationRequired, "Операция требует аутентификации пользователя")
+	}
+	return c.user, nil
+}
+
+// decode читает JSON-тело запроса
+func decode(r *http.Request, v interface{}) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return schemaError("Не удалось прочитать тело запроса: %v", err)
+	}
+	if len(body) == 0 {
+		body = []byte("{}")
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return schemaError("Некорректное тело запроса: %v", err)
+	}
+	return nil
+}
+
+// writeJSON кодирует ответ в JSON
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError записывает ошибку в формате известных ошибок Stack Auth
+func writeError(w http.ResponseWriter, err error) {
+	var e *apiError
+	if !errors.As(err, &e) {
+		e = &apiError{status: http.StatusInternalServerError, code: "INTERNAL_ERROR", message: err.Error()}
+	}
+	w.Header().Set(base_http_client.KnownErrorHeader, e.code)
+	writeJSON(w, e.status, map[string]string{"code": e.code, "error": e.message})
+}
+
+// success - ответ операций, не возвращающих данных
+type success struct {
+	Success bool `json:"success"`
+}
+
+// codeValidity - ответ проверки кода
+type codeValidity struct {
+	IsCodeValid bool `json:"is_code_valid"`
+}
+
+// page - страница списка с курсором следующей страницы
+type page struct {
+	Items      interface{} `json:"items"`
+	Pagination struct {
+		NextCursor *string `json:"next_cursor"`
+	} `json:"pagination"`
+}
+
+// paginate выделяет страницу списка по курсору и лимиту из строки запроса.
+// Курсор - идентификатор первого элемента страницы.
+func paginate[T any](r *http.Request, items []T, id func(T) string) (*page, error) {
+	query := r.URL.Query()
+	start := 0
+	if cursor := query.Get("cursor"); cursor != "" {
+		start = -1
+		for i, item := range items {
+			if id(item) == cursor {
+				start = i
+				break
+			}
+		}
+		if start < 0 {
+			return nil, schemaError("Курсор %q не найден", cursor)
+		}
+	}
+
+	end := len(items)
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return nil, schemaError("Некорректный параметр limit: %q", value)
+		}
+		if start+limit < end {
+			end = start + limit
+		}
+	}
+
+	result := &page{Items: append([]T{}, items[start:end]...)}
+	if end < len(items) {
+		next := id(items[end])
+		result.Pagination.NextCursor = &next
+	}
+	return result, nil
+}
+
+// newID создает случайный идентификатор в формате UUID
+func newID() string {
+	b := randomBytes(16)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// newSecret создает случайную строку для токенов и кодов
+func newSecret(prefix string) string {
+	return prefix + hex.EncodeToString(randomBytes(24))
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return b
+}
+
+// routes регистрирует обработчики эндпоинтов
+func (s *Server) routes(mux *http.ServeMux) {
+	s.handle(mux, "GET "+apiPrefix, s.getAPIInfo)
+	s.handle(mux, "GET "+apiPrefix+"/{$}", s.getAPIInfo)
+	s.handle(mux, "GET "+apiPrefix+"/projects/current", s.getCurrentProject)
+	mux.HandleFunc("GET "+apiPrefix+"/projects/{project_id}/.well-known/jwks.json", s.getJWKS)
+
+	s.handle(mux, "GET "+apiPrefix+"/users", s.listUsers)
+	s.handle(mux, "POST "+apiPrefix+"/users", s.createUser)
+	s.handle(mux, "GET "+apiPrefix+"/users/me", s.getCurrentUser)
+	s.handle(mux, "PATCH "+apiPrefix+"/users/me", s.updateCurrentUser)
+	s.handle(mux, "DELETE "+apiPrefix+"/users/me", s.deleteCurrentUser)
+	s.handle(mux, "GET "+apiPrefix+"/users/{user_id}", s.getUser)
+	s.handle(mux, "PATCH "+apiPrefix+"/users/{user_id}", s.updateUser)
+	s.handle(mux, "DELETE "+apiPrefix+"/users/{user_id}", s.deleteUser)
+
+	s.handle(mux, "GET "+apiPrefix+"/teams", s.listTeams)
+	s.handle(mux, "POST "+apiPrefix+"/teams", s.createTeam)
+	s.handle(mux, "GET "+apiPrefix+"/teams/{team_id}", s.getTeam)
+	s.handle(mux, "PATCH "+apiPrefix+"/teams/{team_id}", s.updateTeam)
+	s.handle(mux, "DELETE "+apiPrefix+"/teams/{team_id}", s.deleteTeam)
+	s.handle(mux, "POST "+apiPrefix+"/team-memberships/{team_id}/{user_id}", s.addTeamMember)
+	s.handle(mux, "DELETE "+apiPrefix+"/team-memberships/{team_id}/{user_id}", s.removeTeamMember)
+	s.handle(mux, "GET "+apiPrefix+"/team-member-profiles", s.listTeamMemberProfiles)
+	s.handle(mux, "GET "+apiPrefix+"/team-member-profiles/{team_id}/{user_id}", s.getTeamMemberProfile)
+	s.handle(mux, "PATCH "+apiPrefix+"/team-member-profiles/{team_id}/{user_id}", s.updateTeamMemberProfile)
+
+	s.handle(mux, "GET "+apiPrefix+"/team-invitations", s.listTeamInvitations)
+	s.handle(mux, "DELETE "+apiPrefix+"/team-invitations/{invitation_id}", s.deleteTeamInvitation)
+	s.handle(mux, "POST "+apiPrefix+"/team-invitations/send-code", s.sendTeamInvitation)
+	s.handle(mux, "POST "+apiPrefix+"/team-invitations/accept", s.acceptTeamInvitation)
+	s.handle(mux, "POST "+apiPrefix+"/team-invitations/accept/details", s.teamInvitationDetails)
+	s.handle(mux, "POST "+apiPrefix+"/team-invitations/accept/check-code", s.checkTeamInvitationCode)
+
+	s.handle(mux, "GET "+apiPrefix+"/team-permissions", s.listTeamPermissions)
+	s.handle(mux, "POST "+apiPrefix+"/team-permissions/{team_id}/{user_id}/{permission_id}", s.grantTeamPermission)
+	s.handle(mux, "DELETE "+apiPrefix+"/team-permissions/{team_id}/{user_id}/{permission_id}", s.revokeTeamPermission)
+
+	s.handle(mux, "GET "+apiPrefix+"/contact-channels", s.listContactChannels)
+	s.handle(mux, "POST "+apiPrefix+"/contact-channels", s.createContactChannel)
+	s.handle(mux, "POST "+apiPrefix+"/contact-channels/verify", s.verifyContactChannel)
+	s.handle(mux, "POST "+apiPrefix+"/contact-channels/verify/check-code", s.checkContactChannelCode)
+	s.handle(mux, "GET "+apiPrefix+"/contact-channels/{user_id}/{contact_channel_id}", s.getContactChannel)
+	s.handle(mux, "PATCH "+apiPrefix+"/contact-channels/{user_id}/{contact_channel_id}", s.updateContactChannel)
+	s.handle(mux, "DELETE "+apiPrefix+"/contact-channels/{user_id}/{contact_channel_id}", s.deleteContactChannel)
+	s.handle(mux, "POST "+apiPrefix+"/contact-channels/{user_id}/{contact_channel_id}/send-verification-code", s.sendContactChannelCode)
+
+	s.handle(mux, "POST "+apiPrefix+"/auth/password/sign-up", s.passwordSignUp)
+	s.handle(mux, "POST "+apiPrefix+"/auth/password/sign-in", s.passwordSignIn)
+	s.handle(mux, "POST "+apiPrefix+"/auth/password/update", s.passwordUpdate)
+	s.handle(mux, "POST "+apiPrefix+"/auth/password/set", s.passwordSet)
+	s.handle(mux, "POST "+apiPrefix+"/auth/password/send-reset-code", s.passwordSendResetCode)
+	s.handle(mux, "POST "+apiPrefix+"/auth/password/reset", s.passwordReset)
+	s.handle(mux, "POST "+apiPrefix+"/auth/password/reset/check-code", s.passwordCheckResetCode)
+
+	s.handle(mux, "POST "+apiPrefix+"/auth/otp/send-sign-in-code", s.otpSendSignInCode)
+	s.handle(mux, "POST "+apiPrefix+"/auth/otp/sign-in", s.otpSignIn)
+	s.handle(mux, "POST "+apiPrefix+"/auth/otp/sign-in/check-code", s.otpCheckSignInCode)
+
+	s.handle(mux, "POST "+apiPrefix+"/auth/sessions", s.createSession)
+	s.handle(mux, "DELETE "+apiPrefix+"/auth/sessions/current", s.signOut)
+	s.handle(mux, "POST "+apiPrefix+"/auth/sessions/current/refresh", s.refreshAccessToken)
+}
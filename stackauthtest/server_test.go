@@ -0,0 +1,333 @@
+package stackauthtest
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/BlaisePopov/stack-auth/api"
+	"github.com/BlaisePopov/stack-auth/api/contactchannels"
+	"github.com/BlaisePopov/stack-auth/api/otp"
+	"github.com/BlaisePopov/stack-auth/api/pagination"
+	"github.com/BlaisePopov/stack-auth/api/password"
+	"github.com/BlaisePopov/stack-auth/api/permissions"
+	"github.com/BlaisePopov/stack-auth/api/teams"
+	"github.com/BlaisePopov/stack-auth/api/users"
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_UsersPaginationAndErrors(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := api.NewClient(server.Config())
+
+	var ids []string
+	for _, email := range []string{"ann@example.com", "bob@example.com", "carl@example.com"} {
+		user, err := client.Users.CreateUser(&users.CreateUserRequest{PrimaryEmail: email, DisplayName: email[:3]})
+		assert.NoError(t, err)
+		ids = append(ids, user.ID)
+	}
+
+	first, err := client.Users.ListUsers(&users.ListUsersOptions{Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, first.Items, 2)
+	assert.Equal(t, ids[0], first.Items[0].ID)
+	assert.Equal(t, ids[2], first.Pagination.NextCursor)
+
+	second, err := client.Users.ListUsers(&users.ListUsersOptions{Cursor: first.Pagination.NextCursor, OrderBy: users.OrderBySignedUpAt, Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, second.Items, 1)
+	assert.Empty(t, second.Pagination.NextCursor)
+
+	found, err := client.Users.ListUsers(&users.ListUsersOptions{Query: "BOB", Desc: true})
+	assert.NoError(t, err)
+	assert.Len(t, found.Items, 1)
+	assert.Equal(t, "bob@example.com", found.Items[0].PrimaryEmail)
+
+	anonymousID := server.CreateAnonymousUser()
+	all, err := pagination.CollectAll(client.Users.AllUsers(context.Background(), &users.ListUsersOptions{Limit: 1}), 0)
+	assert.NoError(t, err)
+	assert.Len(t, all, 3)
+	withAnonymous, err := client.Users.ListUsers(&users.ListUsersOptions{IncludeAnonymous: true})
+	assert.NoError(t, err)
+	assert.Len(t, withAnonymous.Items, 4)
+	assert.Equal(t, anonymousID, withAnonymous.Items[3].ID)
+	assert.True(t, withAnonymous.Items[3].IsAnonymous)
+
+	_, err = client.Users.CreateUser(&users.CreateUserRequest{PrimaryEmail: "ann@example.com"})
+	assert.ErrorIs(t, err, base_http_client.ErrUserEmailAlreadyExists)
+
+	_, err = client.Users.DeleteUser(ids[1])
+	assert.NoError(t, err)
+	_, err = client.Users.GetUser(ids[1])
+	assert.ErrorIs(t, err, base_http_client.ErrUserNotFound)
+
+	accessToken, refreshToken, err := server.SignIn(ids[0])
+	assert.NoError(t, err)
+	userClient := api.NewClient(server.ClientConfig(accessToken, refreshToken))
+	_, err = userClient.Users.ListUsers(nil)
+	assert.ErrorIs(t, err, base_http_client.ErrInsufficientAccessType)
+
+	current, err := userClient.Users.UpdateCurrentUser(&users.UpdateUserRequest{DisplayName: "Ann"})
+	assert.NoError(t, err)
+	assert.Equal(t, "Ann", current.DisplayName)
+	assert.Equal(t, "ann@example.com", current.PrimaryEmail)
+}
+
+func TestServer_PasswordSignInAndSessions(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := api.NewClient(server.Config())
+
+	signUp, err := client.Password.SignUpWithEmail(&password.SignUpWithEmailRequest{
+		Email:                   "john@example.com",
+		Password:                "correct horse",
+		VerificationCallbackURL: "https://example.com/verify",
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, signUp.UserID)
+	email, ok := server.LastEmail("john@example.com")
+	assert.True(t, ok)
+	assert.Equal(t, EmailVerification, email.Type)
+
+	_, err = client.Password.SignInWithEmail(&password.SignInRequest{Email: "john@example.com", Password: "wrong password"})
+	assert.ErrorIs(t, err, base_http_client.ErrEmailPasswordMismatch)
+	_, err = client.Password.SignUpWithEmail(&password.SignUpWithEmailRequest{Email: "jane@example.com", Password: "short"})
+	assert.ErrorIs(t, err, base_http_client.ErrPasswordTooShort)
+
+	signIn, err := client.Password.SignInWithEmail(&password.SignInRequest{Email: "john@example.com", Password: "correct horse"})
+	assert.NoError(t, err)
+	assert.Equal(t, signUp.UserID, signIn.UserID)
+
+	userClient := api.NewClient(server.ClientConfig(signIn.AccessToken, signIn.RefreshToken))
+	current, err := userClient.Users.GetCurrentUser()
+	assert.NoError(t, err)
+	assert.Equal(t, signUp.UserID, current.ID)
+
+	server.ExpireAccessToken(signIn.AccessToken)
+	_, err = userClient.Users.GetCurrentUser()
+	assert.ErrorIs(t, err, base_http_client.ErrAccessTokenExpired)
+
+	refreshed, err := userClient.Sessions.RefreshAccessToken(signIn.RefreshToken)
+	assert.NoError(t, err)
+	current, err = userClient.Users.GetCurrentUser(base_http_client.WithAccessToken(refreshed.AccessToken))
+	assert.NoError(t, err)
+	assert.Equal(t, signUp.UserID, current.ID)
+
+	_, err = userClient.Sessions.SignOut(signIn.RefreshToken)
+	assert.NoError(t, err)
+	_, err = userClient.Sessions.RefreshAccessToken(signIn.RefreshToken)
+	assert.ErrorIs(t, err, base_http_client.ErrRefreshTokenNotFoundOrExpired)
+}
+
+func TestServer_TokenStore(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	userID := server.CreateUser("john@example.com", "correct horse")
+
+	store := base_http_client.NewMemoryTokenStore(base_http_client.Tokens{})
+	config := server.ClientConfig("", "")
+	config.TokenStore = store
+	client := api.NewClient(config)
+
+	signIn, err := client.Password.SignInWithEmail(&password.SignInRequest{Email: "john@example.com", Password: "correct horse"})
+	assert.NoError(t, err)
+	tokens, _ := store.Load(context.Background())
+	assert.Equal(t, signIn.RefreshToken, tokens.RefreshToken)
+
+	current, err := client.Users.GetCurrentUser()
+	assert.NoError(t, err)
+	assert.Equal(t, userID, current.ID)
+
+	server.ExpireAccessToken(signIn.AccessToken)
+	_, err = client.Sessions.RefreshAccessToken("")
+	assert.NoError(t, err)
+	current, err = client.Users.GetCurrentUser()
+	assert.NoError(t, err)
+	assert.Equal(t, userID, current.ID)
+
+	_, err = client.Sessions.SignOut("")
+	assert.NoError(t, err)
+	tokens, _ = store.Load(context.Background())
+	assert.Equal(t, base_http_client.Tokens{}, tokens)
+	_, err = client.Users.GetCurrentUser()
+	assert.Error(t, err)
+}
+
+func TestServer_OTPSignIn(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := api.NewClient(server.Config())
+
+	_, err := client.OTP.SendSignInCode(&otp.SendSignInCodeRequest{Email: "new@example.com", CallbackURL: "https://example.com/otp"})
+	assert.NoError(t, err)
+	email, ok := server.LastEmail("new@example.com")
+	assert.True(t, ok)
+	assert.Equal(t, EmailSignInCode, email.Type)
+
+	check, err := client.OTP.CheckSignInCode(&otp.CheckSignInCodeRequest{Code: email.Code})
+	assert.NoError(t, err)
+	assert.True(t, check.IsCodeValid)
+
+	signIn, err := client.OTP.SignInWithCode(&otp.SignInWithCodeRequest{Code: email.Code})
+	assert.NoError(t, err)
+	assert.True(t, signIn.IsNewUser)
+
+	_, err = client.OTP.SignInWithCode(&otp.SignInWithCodeRequest{Code: email.Code})
+	assert.ErrorIs(t, err, base_http_client.ErrVerificationCodeAlreadyUsed)
+	_, err = client.OTP.SignInWithCode(&otp.SignInWithCodeRequest{Code: "unknown"})
+	assert.ErrorIs(t, err, base_http_client.ErrVerificationCodeNotFound)
+
+	user, err := client.Users.GetUser(signIn.UserID)
+	assert.NoError(t, err)
+	assert.True(t, user.PrimaryEmailVerified)
+}
+
+func TestServer_TeamsInvitationsAndPermissions(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := api.NewClient(server.Config())
+
+	ownerID := server.CreateUser("owner@example.com", "")
+	guestID := server.CreateUser("guest@example.com", "")
+	ownerAccess, _, err := server.SignIn(ownerID)
+	assert.NoError(t, err)
+	guestAccess, _, err := server.SignIn(guestID)
+	assert.NoError(t, err)
+	owner := api.NewClient(server.ClientConfig(ownerAccess, ""))
+	guest := api.NewClient(server.ClientConfig(guestAccess, ""))
+
+	me := "me"
+	team, err := owner.Teams.CreateTeam(&teams.CreateTeamRequest{DisplayName: "Core", CreatorUserID: &me})
+	assert.NoError(t, err)
+
+	_, err = guest.Teams.GetTeam(team.ID)
+	assert.ErrorIs(t, err, base_http_client.ErrTeamNotFound)
+
+	invite, err := owner.Teams.SendInviteEmail(&teams.SendInviteEmailRequest{TeamID: team.ID, Email: "guest@example.com", CallbackURL: "https://example.com/join"})
+	assert.NoError(t, err)
+	invitations, err := client.Others.ListTeamInvitations()
+	assert.NoError(t, err)
+	assert.Len(t, invitations.Items, 1)
+	assert.Equal(t, invite.ID, invitations.Items[0].ID)
+
+	email, ok := server.LastEmail("guest@example.com")
+	assert.True(t, ok)
+	details, err := guest.Teams.GetInvitationDetails(email.Code)
+	assert.NoError(t, err)
+	assert.Equal(t, "Core", details.TeamDisplayName)
+	assert.NoError(t, guest.Teams.AcceptInvite(&teams.AcceptInviteRequest{Code: email.Code}))
+
+	profiles, err := client.Teams.ListTeamMembersProfiles(&teams.ListTeamMembersProfilesOptions{TeamID: team.ID})
+	assert.NoError(t, err)
+	assert.Len(t, profiles.Items, 2)
+	guestTeams, err := guest.Teams.ListTeams(&teams.ListTeamsOptions{UserID: "me"})
+	assert.NoError(t, err)
+	assert.Len(t, guestTeams.Items, 1)
+
+	_, err = guest.Teams.DeleteTeam(team.ID)
+	assert.ErrorIs(t, err, base_http_client.ErrTeamPermissionRequired)
+	_, err = client.Teams.AddTeamMember(team.ID, guestID)
+	assert.ErrorIs(t, err, base_http_client.ErrTeamMembershipAlreadyExists)
+
+	direct, err := client.Permissions.ListTeamPermissions(&permissions.ListTeamPermissionsOptions{TeamID: team.ID, UserID: guestID})
+	assert.NoError(t, err)
+	assert.Len(t, direct.Items, 1)
+	assert.Equal(t, PermissionTeamMember, direct.Items[0].ID)
+	recursive, err := guest.Permissions.ListTeamPermissions(&permissions.ListTeamPermissionsOptions{TeamID: team.ID, UserID: "me", PermissionID: PermissionReadMembers, Recursive: true})
+	assert.NoError(t, err)
+	assert.Len(t, recursive.Items, 1)
+
+	_, err = client.Permissions.GrantTeamPermissionToUser(team.ID, guestID, PermissionTeamAdmin, "")
+	assert.NoError(t, err)
+	_, err = client.Permissions.GrantTeamPermissionToUser(team.ID, guestID, "unknown", "")
+	assert.ErrorIs(t, err, base_http_client.ErrPermissionNotFound)
+	_, err = guest.Teams.UpdateTeam(team.ID, &teams.UpdateTeamRequest{DisplayName: &me})
+	assert.NoError(t, err)
+	_, err = client.Permissions.RevokeTeamPermissionFromUser(team.ID, guestID, PermissionTeamAdmin, "")
+	assert.NoError(t, err)
+	_, err = client.Permissions.RevokeTeamPermissionFromUser(team.ID, guestID, PermissionTeamAdmin, "")
+	assert.ErrorIs(t, err, base_http_client.ErrTeamPermissionNotFound)
+
+	_, err = guest.Teams.RemoveTeamMember(team.ID, "me")
+	assert.NoError(t, err)
+	_, err = client.Teams.GetTeamMemberProfile(team.ID, guestID)
+	assert.ErrorIs(t, err, base_http_client.ErrTeamMembershipNotFound)
+}
+
+func TestServer_ContactChannelVerification(t *testing.T) {
+	server := NewServer()
+	defer server.Close()
+	client := api.NewClient(server.Config())
+
+	userID := server.CreateUser("", "")
+	primary := true
+	channel, err := client.ContactChannels.CreateContactChannel(&contactchannels.CreateContactChannelRequest{
+		UserID:    userID,
+		Value:     "mail@example.com",
+		Type:      "email",
+		IsPrimary: &primary,
+	})
+	assert.NoError(t, err)
+	assert.False(t, channel.IsVerified)
+
+	_, err = client.ContactChannels.SendVerificationCode(userID, channel.ID, &contactchannels.SendCodeRequest{CallbackURL: "https://example.com/verify"})
+	assert.NoError(t, err)
+	email, ok := server.LastEmail("mail@example.com")
+	assert.True(t, ok)
+	_, err = client.ContactChannels.VerifyEmail(&contactchannels.VerifyRequest{Code: email.Code})
+	assert.NoError(t, err)
+
+	channels, err := client.ContactChannels.ListContactChannels(userID, "")
+	assert.NoError(t, err)
+	assert.Len(t, channels.Items, 1)
+	assert.True(t, channels.Items[0].IsVerified)
+	user, err := client.Users.GetUser(userID)
+	assert.NoError(t, err)
+	assert.Equal(t, "mail@example.com", user.PrimaryEmail)
+	assert.True(t, user.PrimaryEmailVerified)
+
+	_, err = client.ContactChannels.GetContactChannel(userID, "unknown")
+	assert.ErrorIs(t, err, base_http_client.ErrContactChannelNotFound)
+}
+
+func TestServer_ProjectAndCredentials(t *testing.T) {
+	var now atomic.Pointer[time.Time]
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now.Store(&start)
+	server := NewServer(
+		WithProjectConfig(ProjectConfig{CredentialEnabled: true}),
+		WithClock(func() time.Time { return *now.Load() }),
+	)
+	defer server.Close()
+
+	project, err := api.NewClient(server.Config()).Projects.GetCurrentProject()
+	assert.NoError(t, err)
+	assert.Equal(t, server.ProjectID(), project.ID)
+	assert.False(t, project.Config.SignUpEnabled)
+
+	_, err = api.NewClient(server.Config()).Password.SignUpWithEmail(&password.SignUpWithEmailRequest{Email: "a@example.com", Password: "long enough"})
+	assert.ErrorIs(t, err, base_http_client.ErrSignUpNotEnabled)
+
+	config := server.Config()
+	config.SecretServerKey = "wrong"
+	_, err = api.NewClient(config).Projects.GetCurrentProject()
+	assert.ErrorIs(t, err, base_http_client.ErrInvalidSecretServerKey)
+
+	config = server.Config()
+	config.ProjectID = "other-project"
+	_, err = api.NewClient(config).Projects.GetCurrentProject()
+	assert.ErrorIs(t, err, base_http_client.ErrProjectNotFound)
+
+	accessToken, _, err := server.SignIn(server.CreateUser("clock@example.com", "long enough"))
+	assert.NoError(t, err)
+	userClient := api.NewClient(server.ClientConfig(accessToken, ""))
+	_, err = userClient.Users.GetCurrentUser()
+	assert.NoError(t, err)
+	expired := start.Add(DefaultAccessTokenTTL)
+	now.Store(&expired)
+	_, err = userClient.Users.GetCurrentUser()
+	assert.ErrorIs(t, err, base_http_client.ErrAccessTokenExpired)
+}
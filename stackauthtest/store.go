@@ -0,0 +1,526 @@
+package stackauthtest
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+// Системные командные разрешения Stack Auth
+const (
+	PermissionUpdateTeam    = "$update_team"
+	PermissionDeleteTeam    = "$delete_team"
+	PermissionReadMembers   = "$read_members"
+	PermissionRemoveMembers = "$remove_members"
+	PermissionInviteMembers = "$invite_members"
+)
+
+// Разрешения, выдаваемые участникам команды по умолчанию
+const (
+	// PermissionTeamMember выдается каждому участнику команды
+	PermissionTeamMember = "team_member"
+	// PermissionTeamAdmin дополнительно выдается создателю команды
+	PermissionTeamAdmin = "team_admin"
+)
+
+// EmailType - тип письма, которое сервер "отправил" бы пользователю
+type EmailType string
+
+const (
+	EmailSignInCode     EmailType = "sign_in_code"
+	EmailPasswordReset  EmailType = "password_reset"
+	EmailVerification   EmailType = "email_verification"
+	EmailTeamInvitation EmailType = "team_invitation"
+)
+
+// Email - письмо с кодом, сохраненное вместо отправки
+type Email struct {
+	To          string
+	Type        EmailType
+	Code        string
+	CallbackURL string
+	SentAt      time.Time
+}
+
+// collection хранит сущности в порядке добавления
+type collection[T any] struct {
+	ids   []string
+	items map[string]*T
+}
+
+func (c *collection[T]) add(id string, item *T) {
+	if c.items == nil {
+		c.items = make(map[string]*T)
+	}
+	if _, ok := c.items[id]; !ok {
+		c.ids = append(c.ids, id)
+	}
+	c.items[id] = item
+}
+
+func (c *collection[T]) get(id string) *T {
+	return c.items[id]
+}
+
+func (c *collection[T]) remove(id string) {
+	if _, ok := c.items[id]; !ok {
+		return
+	}
+	delete(c.items, id)
+	for i, existing := range c.ids {
+		if existing == id {
+			c.ids = append(c.ids[:i], c.ids[i+1:]...)
+			break
+		}
+	}
+}
+
+func (c *collection[T]) list() []*T {
+	items := make([]*T, 0, len(c.ids))
+	for _, id := range c.ids {
+		items = append(items, c.items[id])
+	}
+	return items
+}
+
+type user struct {
+	ID                      string
+	DisplayName             string
+	ProfileImageURL         string
+	ClientMetadata          map[string]interface{}
+	ClientReadOnlyMetadata  map[string]interface{}
+	ServerMetadata          map[string]interface{}
+	PrimaryEmail            string
+	PrimaryEmailVerified    bool
+	PrimaryEmailAuthEnabled bool
+	SignedUpAtMillis        int64
+	LastActiveAtMillis      int64
+	SelectedTeamID          string
+	IsAnonymous             bool
+	password                string
+}
+
+type team struct {
+	ID                     string                 `json:"id"`
+	DisplayName            string                 `json:"display_name"`
+	ProfileImageURL        string                 `json:"profile_image_url"`
+	CreatedAtMillis        int64                  `json:"created_at_millis"`
+	ClientMetadata         map[string]interface{} `json:"client_metadata"`
+	ClientReadOnlyMetadata map[string]interface{} `json:"client_read_only_metadata"`
+	ServerMetadata         map[string]interface{} `json:"server_metadata"`
+}
+
+type membership struct {
+	teamID          string
+	userID          string
+	displayName     string
+	profileImageURL string
+	permissions     map[string]bool
+}
+
+type invitation struct {
+	ID              string  `json:"id"`
+	TeamID          string  `json:"team_id"`
+	RecipientEmail  string  `json:"recipient_email"`
+	ExpiresAtMillis float64 `json:"expires_at_millis"`
+	code            string
+}
+
+type contactChannel struct {
+	ID          string `json:"id"`
+	UserID      string `json:"user_id"`
+	Value       string `json:"value"`
+	Type        string `json:"type"`
+	UsedForAuth bool   `json:"used_for_auth"`
+	IsVerified  bool   `json:"is_verified"`
+	IsPrimary   bool   `json:"is_primary"`
+}
+
+type session struct {
+	id           string
+	userID       string
+	refreshToken string
+	expiresAt    time.Time
+}
+
+type accessToken struct {
+	userID    string
+	sessionID string
+	expiresAt time.Time
+}
+
+// verificationCode - одноразовый код, отправленный в письме
+type verificationCode struct {
+	emailType    EmailType
+	email        string
+	userID       string
+	channelID    string
+	invitationID string
+	expiresAt    time.Time
+	used         bool
+}
+
+// store содержит состояние fake-сервера. Доступ к нему выполняется под Server.mu
+type store struct {
+	users           collection[user]
+	teams           collection[team]
+	memberships     collection[membership]
+	invitations     collection[invitation]
+	contactChannels collection[contactChannel]
+	sessions        collection[session]
+	accessTokens    map[string]accessToken
+	refreshTokens   map[string]string
+	codes           map[string]*verificationCode
+	emails          []Email
+	permissions     map[string][]string
+}
+
+func newStore() *store {
+	return &store{
+		accessTokens:  make(map[string]accessToken),
+		refreshTokens: make(map[string]string),
+		codes:         make(map[string]*verificationCode),
+		permissions: map[string][]string{
+			PermissionUpdateTeam:    nil,
+			PermissionDeleteTeam:    nil,
+			PermissionReadMembers:   nil,
+			PermissionRemoveMembers: nil,
+			PermissionInviteMembers: nil,
+			PermissionTeamMember:    {PermissionReadMembers, PermissionInviteMembers},
+			PermissionTeamAdmin:     {PermissionUpdateTeam, PermissionDeleteTeam, PermissionReadMembers, PermissionRemoveMembers, PermissionInviteMembers},
+		},
+	}
+}
+
+func membershipKey(teamID, userID string) string {
+	return teamID + "/" + userID
+}
+
+// userByEmail ищет пользователя по основному email без учета регистра
+func (st *store) userByEmail(email string) *user {
+	for _, u := range st.users.list() {
+		if u.PrimaryEmail != "" && strings.EqualFold(u.PrimaryEmail, email) {
+			return u
+		}
+	}
+	return nil
+}
+
+// membership возвращает членство пользователя в команде
+func (st *store) membership(teamID, userID string) *membership {
+	return st.memberships.get(membershipKey(teamID, userID))
+}
+
+// teamMemberships возвращает членства команды или пользователя в порядке добавления
+func (st *store) teamMemberships(teamID, userID string) []*membership {
+	var result []*membership
+	for _, m := range st.memberships.list() {
+		if (teamID == "" || m.teamID == teamID) && (userID == "" || m.userID == userID) {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// addMembership добавляет пользователя в команду с разрешением team_member
+func (st *store) addMembership(teamID, userID string) *membership {
+	m := &membership{teamID: teamID, userID: userID, permissions: map[string]bool{PermissionTeamMember: true}}
+	st.memberships.add(membershipKey(teamID, userID), m)
+	return m
+}
+
+// removeMembership удаляет членство вместе с командными разрешениями
+func (st *store) removeMembership(teamID, userID string) {
+	st.memberships.remove(membershipKey(teamID, userID))
+	if u := st.users.get(userID); u != nil && u.SelectedTeamID == teamID {
+		u.SelectedTeamID = ""
+	}
+}
+
+// expandPermissions возвращает разрешения вместе со всеми вложенными разрешениями
+func (st *store) expandPermissions(granted map[string]bool) map[string]bool {
+	result := make(map[string]bool)
+	var visit func(id string)
+	visit = func(id string) {
+		if result[id] {
+			return
+		}
+		result[id] = true
+		for _, contained := range st.permissions[id] {
+			visit(contained)
+		}
+	}
+	for id := range granted {
+		visit(id)
+	}
+	return result
+}
+
+// hasPermission сообщает, есть ли у участника команды разрешение с учетом вложенности
+func (st *store) hasPermission(teamID, userID, permissionID string) bool {
+	m := st.membership(teamID, userID)
+	return m != nil && st.expandPermissions(m.permissions)[permissionID]
+}
+
+// createUser добавляет пользователя с основным email и соответствующим контактным каналом
+func (st *store) createUser(u *user, now time.Time) {
+	u.ID = newID()
+	u.SignedUpAtMillis = now.UnixMilli()
+	u.LastActiveAtMillis = u.SignedUpAtMillis
+	st.users.add(u.ID, u)
+	if u.PrimaryEmail != "" {
+		channel := &contactChannel{
+			ID:          newID(),
+			UserID:      u.ID,
+			Value:       u.PrimaryEmail,
+			Type:        "email",
+			UsedForAuth: u.PrimaryEmailAuthEnabled,
+			IsVerified:  u.PrimaryEmailVerified,
+			IsPrimary:   true,
+		}
+		st.contactChannels.add(channel.ID, channel)
+	}
+}
+
+// deleteUser удаляет пользователя и все связанные с ним данные
+func (st *store) deleteUser(userID string) {
+	st.users.remove(userID)
+	for _, m := range st.teamMemberships("", userID) {
+		st.memberships.remove(membershipKey(m.teamID, m.userID))
+	}
+	for _, channel := range st.contactChannels.list() {
+		if channel.UserID == userID {
+			st.contactChannels.remove(channel.ID)
+		}
+	}
+	for _, sess := range st.sessions.list() {
+		if sess.userID == userID {
+			st.deleteSession(sess)
+		}
+	}
+}
+
+// primaryChannel возвращает основной контактный канал пользователя
+func (st *store) primaryChannel(userID string) *contactChannel {
+	for _, channel := range st.contactChannels.list() {
+		if channel.UserID == userID && channel.IsPrimary {
+			return channel
+		}
+	}
+	return nil
+}
+
+// syncPrimaryEmail переносит данные основного контактного канала в пользователя
+func (st *store) syncPrimaryEmail(userID string) {
+	u := st.users.get(userID)
+	if u == nil {
+		return
+	}
+	u.PrimaryEmail, u.PrimaryEmailVerified, u.PrimaryEmailAuthEnabled = "", false, false
+	if channel := st.primaryChannel(userID); channel != nil {
+		u.PrimaryEmail, u.PrimaryEmailVerified, u.PrimaryEmailAuthEnabled = channel.Value, channel.IsVerified, channel.UsedForAuth
+	}
+}
+
+// startSession создает сессию пользователя и выдает первую пару токенов
+func (s *Server) startSession(userID string, ttl time.Duration) (*session, string) {
+	sess := &session{
+		id:           newID(),
+		userID:       userID,
+		refreshToken: newSecret("refresh_"),
+		expiresAt:    s.now().Add(ttl),
+	}
+	s.store.sessions.add(sess.id, sess)
+	s.store.refreshTokens[sess.refreshToken] = sess.id
+	return sess, s.issueAccessToken(sess)
+}
+
+// issueAccessToken выдает новый access token в рамках сессии
+func (s *Server) issueAccessToken(sess *session) string {
+	now := s.now()
+	expiresAt := now.Add(s.accessTokenTTL)
+	token := s.signAccessToken(sess, now, expiresAt)
+	s.store.accessTokens[token] = accessToken{userID: sess.userID, sessionID: sess.id, expiresAt: expiresAt}
+	return token
+}
+
+// deleteSession завершает сессию и отзывает ее access token
+func (st *store) deleteSession(sess *session) {
+	st.sessions.remove(sess.id)
+	delete(st.refreshTokens, sess.refreshToken)
+	for token, issued := range st.accessTokens {
+		if issued.sessionID == sess.id {
+			delete(st.accessTokens, token)
+		}
+	}
+}
+
+// sendCode создает одноразовый код и сохраняет письмо с ним
+func (s *Server) sendCode(code *verificationCode, callbackURL string) string {
+	value := newSecret("")
+	code.expiresAt = s.now().Add(DefaultCodeTTL)
+	s.store.codes[value] = code
+	s.store.emails = append(s.store.emails, Email{
+		To:          code.email,
+		Type:        code.emailType,
+		Code:        value,
+		CallbackURL: callbackURL,
+		SentAt:      s.now(),
+	})
+	return value
+}
+
+// lookupCode находит действующий код заданного типа
+func (s *Server) lookupCode(value string, emailType EmailType) (*verificationCode, error) {
+	code, ok := s.store.codes[value]
+	if !ok || code.emailType != emailType {
+		return nil, newError(http.StatusNotFound, base_http_client.ErrVerificationCodeNotFound, "Код не найден")
+	}
+	if code.used {
+		return nil, newError(http.StatusConflict, base_http_client.ErrVerificationCodeAlreadyUsed, "Код уже использован")
+	}
+	if !s.now().Before(code.expiresAt) {
+		return nil, newError(http.StatusBadRequest, base_http_client.ErrVerificationCodeExpired, "Срок действия кода истек")
+	}
+	return code, nil
+}
+
+// checkCode проверяет код без его использования
+func (s *Server) checkCode(r *http.Request, emailType EmailType) (interface{}, error) {
+	var request struct {
+		Code string `json:"code"`
+	}
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	_, err := s.lookupCode(request.Code, emailType)
+	return codeValidity{IsCodeValid: err == nil}, nil
+}
+
+// CreateUser создает пользователя с подтвержденным email, по которому разрешен вход.
+// Пустой password создает пользователя без пароля. Возвращает идентификатор пользователя.
+func (s *Server) CreateUser(email, password string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := &user{
+		PrimaryEmail:            email,
+		PrimaryEmailVerified:    email != "",
+		PrimaryEmailAuthEnabled: email != "",
+		password:                password,
+	}
+	s.store.createUser(u, s.now())
+	return u.ID
+}
+
+// CreateAnonymousUser создает анонимного пользователя без email и пароля. Возвращает идентификатор пользователя.
+// Анонимные пользователи попадают в список пользователей только с параметром include_anonymous
+func (s *Server) CreateAnonymousUser() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u := &user{IsAnonymous: true}
+	s.store.createUser(u, s.now())
+	return u.ID
+}
+
+// CreateTeam создает команду и добавляет в нее участников. Возвращает идентификатор команды
+func (s *Server) CreateTeam(displayName string, memberIDs ...string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, userID := range memberIDs {
+		if s.store.users.get(userID) == nil {
+			return "", fmt.Errorf("stackauthtest: пользователь %s не найден", userID)
+		}
+	}
+	t := &team{ID: newID(), DisplayName: displayName, CreatedAtMillis: s.now().UnixMilli()}
+	s.store.teams.add(t.ID, t)
+	for _, userID := range memberIDs {
+		s.store.addMembership(t.ID, userID)
+	}
+	return t.ID, nil
+}
+
+// GrantTeamPermission выдает участнику команды разрешение
+func (s *Server) GrantTeamPermission(teamID, userID, permissionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := s.store.membership(teamID, userID)
+	if m == nil {
+		return fmt.Errorf("stackauthtest: пользователь %s не состоит в команде %s", userID, teamID)
+	}
+	if _, ok := s.store.permissions[permissionID]; !ok {
+		return fmt.Errorf("stackauthtest: разрешение %s не определено", permissionID)
+	}
+	m.permissions[permissionID] = true
+	return nil
+}
+
+// DefinePermission определяет командное разрешение, включающее перечисленные разрешения
+func (s *Server) DefinePermission(permissionID string, contains ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.store.permissions[permissionID] = append([]string(nil), contains...)
+}
+
+// SignIn создает сессию пользователя и возвращает выданные токены
+func (s *Server) SignIn(userID string) (accessToken, refreshToken string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.store.users.get(userID) == nil {
+		return "", "", fmt.Errorf("stackauthtest: пользователь %s не найден", userID)
+	}
+	sess, accessToken := s.startSession(userID, DefaultRefreshTokenTTL)
+	return accessToken, sess.refreshToken, nil
+}
+
+// ExpireAccessToken досрочно завершает срок действия access token на сервере.
+// Утверждение exp в самом токене не меняется, поэтому локальная проверка подписи его не заметит
+func (s *Server) ExpireAccessToken(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if issued, ok := s.store.accessTokens[token]; ok {
+		issued.expiresAt = time.Time{}
+		s.store.accessTokens[token] = issued
+	}
+}
+
+// Emails возвращает все письма в порядке отправки
+func (s *Server) Emails() []Email {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Email(nil), s.store.emails...)
+}
+
+// LastEmail возвращает последнее письмо, отправленное на адрес
+func (s *Server) LastEmail(to string) (Email, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := len(s.store.emails) - 1; i >= 0; i-- {
+		if strings.EqualFold(s.store.emails[i].To, to) {
+			return s.store.emails[i], true
+		}
+	}
+	return Email{}, false
+}
+
+// sortedKeys возвращает ключи множества в порядке сортировки
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,460 @@
+package stackauthtest
+
+import (
+	"net/http"
+
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+// errInvitationNotFound - код ошибки для несуществующего приглашения
+var errInvitationNotFound = base_http_client.ErrorCode("TEAM_INVITATION_NOT_FOUND")
+
+// teamRequest - тело запросов создания и обновления команды
+type teamRequest struct {
+	DisplayName            *string                `json:"display_name"`
+	CreatorUserID          *string                `json:"creator_user_id"`
+	ProfileImageURL        *string                `json:"profile_image_url"`
+	ClientMetadata         map[string]interface{} `json:"client_metadata"`
+	ClientReadOnlyMetadata map[string]interface{} `json:"client_read_only_metadata"`
+	ServerMetadata         map[string]interface{} `json:"server_metadata"`
+}
+
+// memberProfileView - представление профиля участника команды
+type memberProfileView struct {
+	TeamID          string   `json:"team_id"`
+	UserID          string   `json:"user_id"`
+	DisplayName     string   `json:"display_name"`
+	ProfileImageURL string   `json:"profile_image_url"`
+	User            userView `json:"user"`
+}
+
+// resolveUserID заменяет "me" идентификатором текущего пользователя
+func resolveUserID(c *caller, userID string) (string, error) {
+	if userID != "me" {
+		return userID, nil
+	}
+	u, err := requireUser(c)
+	if err != nil {
+		return "", err
+	}
+	return u.ID, nil
+}
+
+// requireTeamPermission проверяет, что у текущего пользователя есть разрешение в команде.
+// Запросы с серверным ключом не проверяются
+func (s *Server) requireTeamPermission(c *caller, teamID, permissionID string) error {
+	if c.isServer() {
+		return nil
+	}
+	u, err := requireUser(c)
+	if err != nil {
+		return err
+	}
+	if !s.store.hasPermission(teamID, u.ID, permissionID) {
+		return newError(http.StatusUnauthorized, base_http_client.ErrTeamPermissionRequired, "Требуется разрешение %s в команде %s", permissionID, teamID)
+	}
+	return nil
+}
+
+// teamByID возвращает команду, видимую отправителю запроса. Клиент видит только свои команды
+func (s *Server) teamByID(c *caller, teamID string) (*team, error) {
+	if !c.isServer() {
+		if _, err := requireUser(c); err != nil {
+			return nil, err
+		}
+	}
+	t := s.store.teams.get(teamID)
+	if t != nil && !c.isServer() && s.store.membership(teamID, c.user.ID) == nil {
+		t = nil
+	}
+	if t == nil {
+		return nil, newError(http.StatusNotFound, base_http_client.ErrTeamNotFound, "Команда %s не найдена", teamID)
+	}
+	return t, nil
+}
+
+// viewTeam формирует представление команды. Серверные метаданные видны только с серверным ключом
+func viewTeam(t *team, c *caller) team {
+	view := *t
+	if !c.isServer() {
+		view.ServerMetadata = nil
+	}
+	return view
+}
+
+// viewMemberProfile формирует представление профиля участника команды
+func (s *Server) viewMemberProfile(m *membership, c *caller) memberProfileView {
+	return memberProfileView{
+		TeamID:          m.teamID,
+		UserID:          m.userID,
+		DisplayName:     m.displayName,
+		ProfileImageURL: m.profileImageURL,
+		User:            s.viewUser(s.store.users.get(m.userID), c),
+	}
+}
+
+// applyTeamRequest применяет изменения к команде. Клиент не может менять серверные поля
+func applyTeamRequest(t *team, request *teamRequest, c *caller) error {
+	if !c.isServer() && (request.ServerMetadata != nil || request.ClientReadOnlyMetadata != nil) {
+		return schemaError("Поля server_metadata и client_read_only_metadata доступны только серверу")
+	}
+	if request.DisplayName != nil {
+		t.DisplayName = *request.DisplayName
+	}
+	if request.ProfileImageURL != nil {
+		t.ProfileImageURL = *request.ProfileImageURL
+	}
+	if request.ClientMetadata != nil {
+		t.ClientMetadata = request.ClientMetadata
+	}
+	if request.ClientReadOnlyMetadata != nil {
+		t.ClientReadOnlyMetadata = request.ClientReadOnlyMetadata
+	}
+	if request.ServerMetadata != nil {
+		t.ServerMetadata = request.ServerMetadata
+	}
+	return nil
+}
+
+func (s *Server) listTeams(r *http.Request, c *caller) (interface{}, error) {
+	userID := r.URL.Query().Get("user_id")
+	if !c.isServer() {
+		if userID == "" {
+			userID = "me"
+		}
+		if userID != "me" {
+			return nil, schemaError("Клиент может получить только собственные команды (user_id=me)")
+		}
+	}
+	userID, err := resolveUserID(c, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []team
+	for _, t := range s.store.teams.list() {
+		if userID != "" && s.store.membership(t.ID, userID) == nil {
+			continue
+		}
+		items = append(items, viewTeam(t, c))
+	}
+	return paginate(r, items, func(t team) string { return t.ID })
+}
+
+func (s *Server) createTeam(r *http.Request, c *caller) (interface{}, error) {
+	var request teamRequest
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	if request.DisplayName == nil || *request.DisplayName == "" {
+		return nil, schemaError("Поле display_name обязательно")
+	}
+
+	creatorID := ""
+	if request.CreatorUserID != nil {
+		creatorID = *request.CreatorUserID
+	}
+	if !c.isServer() && creatorID != "me" {
+		return nil, schemaError("Клиент должен передать creator_user_id=me")
+	}
+	creatorID, err := resolveUserID(c, creatorID)
+	if err != nil {
+		return nil, err
+	}
+	if creatorID != "" && s.store.users.get(creatorID) == nil {
+		return nil, newError(http.StatusBadRequest, base_http_client.ErrUserIDDoesNotExist, "Пользователь %s не существует", creatorID)
+	}
+
+	t := &team{ID: newID(), CreatedAtMillis: s.now().UnixMilli()}
+	if err := applyTeamRequest(t, &request, c); err != nil {
+		return nil, err
+	}
+	s.store.teams.add(t.ID, t)
+	if creatorID != "" {
+		s.store.addMembership(t.ID, creatorID).permissions[PermissionTeamAdmin] = true
+	}
+	return viewTeam(t, c), nil
+}
+
+func (s *Server) getTeam(r *http.Request, c *caller) (interface{}, error) {
+	t, err := s.teamByID(c, r.PathValue("team_id"))
+	if err != nil {
+		return nil, err
+	}
+	return viewTeam(t, c), nil
+}
+
+func (s *Server) updateTeam(r *http.Request, c *caller) (interface{}, error) {
+	t, err := s.teamByID(c, r.PathValue("team_id"))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireTeamPermission(c, t.ID, PermissionUpdateTeam); err != nil {
+		return nil, err
+	}
+	var request teamRequest
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	if err := applyTeamRequest(t, &request, c); err != nil {
+		return nil, err
+	}
+	return viewTeam(t, c), nil
+}
+
+func (s *Server) deleteTeam(r *http.Request, c *caller) (interface{}, error) {
+	t, err := s.teamByID(c, r.PathValue("team_id"))
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireTeamPermission(c, t.ID, PermissionDeleteTeam); err != nil {
+		return nil, err
+	}
+
+	for _, m := range s.store.teamMemberships(t.ID, "") {
+		s.store.removeMembership(m.teamID, m.userID)
+	}
+	for _, inv := range s.store.invitations.list() {
+		if inv.TeamID == t.ID {
+			s.store.invitations.remove(inv.ID)
+			delete(s.store.codes, inv.code)
+		}
+	}
+	s.store.teams.remove(t.ID)
+	return success{Success: true}, nil
+}
+
+func (s *Server) addTeamMember(r *http.Request, c *caller) (interface{}, error) {
+	if err := requireServer(c); err != nil {
+		return nil, err
+	}
+	t, err := s.teamByID(c, r.PathValue("team_id"))
+	if err != nil {
+		return nil, err
+	}
+	u, err := s.userByPath(r)
+	if err != nil {
+		return nil, err
+	}
+	if s.store.membership(t.ID, u.ID) != nil {
+		return nil, newError(http.StatusConflict, base_http_client.ErrTeamMembershipAlreadyExists, "Пользователь %s уже состоит в команде %s", u.ID, t.ID)
+	}
+	s.store.addMembership(t.ID, u.ID)
+	return map[string]string{"team_id": t.ID, "user_id": u.ID}, nil
+}
+
+func (s *Server) removeTeamMember(r *http.Request, c *caller) (interface{}, error) {
+	t, err := s.teamByID(c, r.PathValue("team_id"))
+	if err != nil {
+		return nil, err
+	}
+	userID, err := resolveUserID(c, r.PathValue("user_id"))
+	if err != nil {
+		return nil, err
+	}
+	if c.user == nil || c.user.ID != userID {
+		if err := s.requireTeamPermission(c, t.ID, PermissionRemoveMembers); err != nil {
+			return nil, err
+		}
+	}
+	if s.store.membership(t.ID, userID) == nil {
+		return nil, newError(http.StatusNotFound, base_http_client.ErrTeamMembershipNotFound, "Пользователь %s не состоит в команде %s", userID, t.ID)
+	}
+	s.store.removeMembership(t.ID, userID)
+	return success{Success: true}, nil
+}
+
+func (s *Server) listTeamMemberProfiles(r *http.Request, c *caller) (interface{}, error) {
+	query := r.URL.Query()
+	teamID := query.Get("team_id")
+	userID, err := resolveUserID(c, query.Get("user_id"))
+	if err != nil {
+		return nil, err
+	}
+	if !c.isServer() {
+		if teamID == "" {
+			if c.user == nil || userID != c.user.ID {
+				return nil, schemaError("Клиент должен передать team_id или user_id=me")
+			}
+		} else if c.user == nil || userID != c.user.ID {
+			if err := s.requireTeamPermission(c, teamID, PermissionReadMembers); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if teamID != "" && s.store.teams.get(teamID) == nil {
+		return nil, newError(http.StatusNotFound, base_http_client.ErrTeamNotFound, "Команда %s не найдена", teamID)
+	}
+
+	var items []memberProfileView
+	for _, m := range s.store.teamMemberships(teamID, userID) {
+		items = append(items, s.viewMemberProfile(m, c))
+	}
+	return paginate(r, items, func(p memberProfileView) string { return membershipKey(p.TeamID, p.UserID) })
+}
+
+// memberProfileByPath возвращает членство по идентификаторам команды и пользователя из пути
+func (s *Server) memberProfileByPath(r *http.Request, c *caller) (*membership, error) {
+	teamID := r.PathValue("team_id")
+	userID, err := resolveUserID(c, r.PathValue("user_id"))
+	if err != nil {
+		return nil, err
+	}
+	if c.user == nil || c.user.ID != userID {
+		if err := s.requireTeamPermission(c, teamID, PermissionReadMembers); err != nil {
+			return nil, err
+		}
+	}
+	m := s.store.membership(teamID, userID)
+	if m == nil {
+		return nil, newError(http.StatusNotFound, base_http_client.ErrTeamMembershipNotFound, "Пользователь %s не состоит в команде %s", userID, teamID)
+	}
+	return m, nil
+}
+
+func (s *Server) getTeamMemberProfile(r *http.Request, c *caller) (interface{}, error) {
+	m, err := s.memberProfileByPath(r, c)
+	if err != nil {
+		return nil, err
+	}
+	return s.viewMemberProfile(m, c), nil
+}
+
+func (s *Server) updateTeamMemberProfile(r *http.Request, c *caller) (interface{}, error) {
+	m, err := s.memberProfileByPath(r, c)
+	if err != nil {
+		return nil, err
+	}
+	if !c.isServer() && c.user.ID != m.userID {
+		return nil, schemaError("Клиент может изменить только собственный профиль участника")
+	}
+	var request struct {
+		DisplayName     *string `json:"display_name"`
+		ProfileImageURL *string `json:"profile_image_url"`
+	}
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	if request.DisplayName != nil {
+		m.displayName = *request.DisplayName
+	}
+	if request.ProfileImageURL != nil {
+		m.profileImageURL = *request.ProfileImageURL
+	}
+	return s.viewMemberProfile(m, c), nil
+}
+
+func (s *Server) listTeamInvitations(r *http.Request, c *caller) (interface{}, error) {
+	teamID := r.URL.Query().Get("team_id")
+	if !c.isServer() {
+		if teamID == "" {
+			return nil, schemaError("Клиент должен передать team_id")
+		}
+		if err := s.requireTeamPermission(c, teamID, PermissionInviteMembers); err != nil {
+			return nil, err
+		}
+	}
+
+	var items []invitation
+	for _, inv := range s.store.invitations.list() {
+		if teamID != "" && inv.TeamID != teamID {
+			continue
+		}
+		if float64(s.now().UnixMilli()) >= inv.ExpiresAtMillis {
+			continue
+		}
+		items = append(items, *inv)
+	}
+	return paginate(r, items, func(inv invitation) string { return inv.ID })
+}
+
+func (s *Server) deleteTeamInvitation(r *http.Request, c *caller) (interface{}, error) {
+	inv := s.store.invitations.get(r.PathValue("invitation_id"))
+	if inv == nil {
+		return nil, newError(http.StatusNotFound, errInvitationNotFound, "Приглашение %s не найдено", r.PathValue("invitation_id"))
+	}
+	if err := s.requireTeamPermission(c, inv.TeamID, PermissionRemoveMembers); err != nil {
+		return nil, err
+	}
+	s.store.invitations.remove(inv.ID)
+	delete(s.store.codes, inv.code)
+	return success{Success: true}, nil
+}
+
+func (s *Server) sendTeamInvitation(r *http.Request, c *caller) (interface{}, error) {
+	var request struct {
+		TeamID      string `json:"team_id"`
+		Email       string `json:"email"`
+		CallbackURL string `json:"callback_url"`
+	}
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	if request.Email == "" || request.CallbackURL == "" {
+		return nil, schemaError("Поля email и callback_url обязательны")
+	}
+	t, err := s.teamByID(c, request.TeamID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.requireTeamPermission(c, t.ID, PermissionInviteMembers); err != nil {
+		return nil, err
+	}
+
+	inv := &invitation{ID: newID(), TeamID: t.ID, RecipientEmail: request.Email}
+	inv.code = s.sendCode(&verificationCode{emailType: EmailTeamInvitation, email: request.Email, invitationID: inv.ID}, request.CallbackURL)
+	inv.ExpiresAtMillis = float64(s.store.codes[inv.code].expiresAt.UnixMilli())
+	s.store.invitations.add(inv.ID, inv)
+	return map[string]interface{}{"success": true, "id": inv.ID}, nil
+}
+
+// invitationByCode возвращает действующее приглашение по коду из тела запроса
+func (s *Server) invitationByCode(r *http.Request) (*verificationCode, *invitation, error) {
+	var request struct {
+		Code string `json:"code"`
+	}
+	if err := decode(r, &request); err != nil {
+		return nil, nil, err
+	}
+	code, err := s.lookupCode(request.Code, EmailTeamInvitation)
+	if err != nil {
+		return nil, nil, err
+	}
+	inv := s.store.invitations.get(code.invitationID)
+	if inv == nil {
+		return nil, nil, newError(http.StatusNotFound, base_http_client.ErrVerificationCodeNotFound, "Код не найден")
+	}
+	return code, inv, nil
+}
+
+func (s *Server) acceptTeamInvitation(r *http.Request, c *caller) (interface{}, error) {
+	u, err := requireUser(c)
+	if err != nil {
+		return nil, err
+	}
+	code, inv, err := s.invitationByCode(r)
+	if err != nil {
+		return nil, err
+	}
+	code.used = true
+	s.store.invitations.remove(inv.ID)
+	if s.store.membership(inv.TeamID, u.ID) == nil {
+		s.store.addMembership(inv.TeamID, u.ID)
+	}
+	return success{Success: true}, nil
+}
+
+func (s *Server) teamInvitationDetails(r *http.Request, c *caller) (interface{}, error) {
+	if _, err := requireUser(c); err != nil {
+		return nil, err
+	}
+	_, inv, err := s.invitationByCode(r)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]string{"team_id": inv.TeamID, "team_display_name": s.store.teams.get(inv.TeamID).DisplayName}, nil
+}
+
+func (s *Server) checkTeamInvitationCode(r *http.Request, c *caller) (interface{}, error) {
+	return s.checkCode(r, EmailTeamInvitation)
+}
@@ -0,0 +1,295 @@
+package stackauthtest
+
+import (
+	"net/http"
+	"strings"
+
+	base_http_client "github.com/BlaisePopov/stack-auth/base-http-client"
+)
+
+// Ограничения длины пароля
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 70
+)
+
+// userView - представление пользователя в ответах API
+type userView struct {
+	ID                      string                 `json:"id"`
+	DisplayName             string                 `json:"display_name"`
+	ProfileImageURL         string                 `json:"profile_image_url"`
+	ClientMetadata          map[string]interface{} `json:"client_metadata"`
+	ClientReadOnlyMetadata  map[string]interface{} `json:"client_read_only_metadata"`
+	ServerMetadata          map[string]interface{} `json:"server_metadata,omitempty"`
+	PrimaryEmail            string                 `json:"primary_email"`
+	PrimaryEmailVerified    bool                   `json:"primary_email_verified"`
+	PrimaryEmailAuthEnabled bool                   `json:"primary_email_auth_enabled"`
+	HasPassword             bool                   `json:"has_password"`
+	SignedUpAtMillis        int64                  `json:"signed_up_at_millis"`
+	LastActiveAtMillis      int64                  `json:"last_active_at_millis"`
+	SelectedTeamID          string                 `json:"selected_team_id"`
+	SelectedTeam            *team                  `json:"selected_team"`
+	IsAnonymous             bool                   `json:"is_anonymous"`
+}
+
+// userRequest - тело запросов создания и обновления пользователя
+type userRequest struct {
+	DisplayName             *string                `json:"display_name"`
+	ProfileImageURL         *string                `json:"profile_image_url"`
+	ClientMetadata          map[string]interface{} `json:"client_metadata"`
+	ClientReadOnlyMetadata  map[string]interface{} `json:"client_read_only_metadata"`
+	ServerMetadata          map[string]interface{} `json:"server_metadata"`
+	PrimaryEmail            *string                `json:"primary_email"`
+	PrimaryEmailVerified    *bool                  `json:"primary_email_verified"`
+	PrimaryEmailAuthEnabled *bool                  `json:"primary_email_auth_enabled"`
+	Password                *string                `json:"password"`
+	SelectedTeamID          *string                `json:"selected_team_id"`
+}
+
+// viewUser формирует представление пользователя. Серверные метаданные видны только с серверным ключом
+func (s *Server) viewUser(u *user, c *caller) userView {
+	view := userView{
+		ID:                      u.ID,
+		DisplayName:             u.DisplayName,
+		ProfileImageURL:         u.ProfileImageURL,
+		ClientMetadata:          u.ClientMetadata,
+		ClientReadOnlyMetadata:  u.ClientReadOnlyMetadata,
+		PrimaryEmail:            u.PrimaryEmail,
+		PrimaryEmailVerified:    u.PrimaryEmailVerified,
+		PrimaryEmailAuthEnabled: u.PrimaryEmailAuthEnabled,
+		HasPassword:             u.password != "",
+		SignedUpAtMillis:        u.SignedUpAtMillis,
+		LastActiveAtMillis:      u.LastActiveAtMillis,
+		SelectedTeamID:          u.SelectedTeamID,
+		SelectedTeam:            s.store.teams.get(u.SelectedTeamID),
+		IsAnonymous:             u.IsAnonymous,
+	}
+	if c.isServer() {
+		view.ServerMetadata = u.ServerMetadata
+	}
+	return view
+}
+
+// validatePassword проверяет требования к паролю
+func validatePassword(password string) error {
+	if len(password) < minPasswordLength {
+		return newError(http.StatusBadRequest, base_http_client.ErrPasswordTooShort, "Пароль должен содержать не менее %d символов", minPasswordLength)
+	}
+	if len(password) > maxPasswordLength {
+		return newError(http.StatusBadRequest, base_http_client.ErrPasswordTooLong, "Пароль должен содержать не более %d символов", maxPasswordLength)
+	}
+	return nil
+}
+
+// checkEmailAvailable проверяет, что email не занят другим пользователем
+func (s *Server) checkEmailAvailable(email, userID string) error {
+	if existing := s.store.userByEmail(email); existing != nil && existing.ID != userID {
+		return newError(http.StatusConflict, base_http_client.ErrUserEmailAlreadyExists, "Пользователь с email %s уже существует", email)
+	}
+	return nil
+}
+
+// applyUserRequest применяет изменения к пользователю. Клиент не может менять серверные поля
+func (s *Server) applyUserRequest(u *user, request *userRequest, c *caller) error {
+	if !c.isServer() && (request.ServerMetadata != nil || request.ClientReadOnlyMetadata != nil || request.PrimaryEmailVerified != nil) {
+		return schemaError("Поля server_metadata, client_read_only_metadata и primary_email_verified доступны только серверу")
+	}
+	if request.Password != nil {
+		if err := validatePassword(*request.Password); err != nil {
+			return err
+		}
+	}
+	if request.PrimaryEmail != nil {
+		if err := s.checkEmailAvailable(*request.PrimaryEmail, u.ID); err != nil {
+			return err
+		}
+	}
+	if request.SelectedTeamID != nil && *request.SelectedTeamID != "" && s.store.membership(*request.SelectedTeamID, u.ID) == nil {
+		return newError(http.StatusNotFound, base_http_client.ErrTeamMembershipNotFound, "Пользователь не состоит в команде %s", *request.SelectedTeamID)
+	}
+
+	if request.DisplayName != nil {
+		u.DisplayName = *request.DisplayName
+	}
+	if request.ProfileImageURL != nil {
+		u.ProfileImageURL = *request.ProfileImageURL
+	}
+	if request.ClientMetadata != nil {
+		u.ClientMetadata = request.ClientMetadata
+	}
+	if request.ClientReadOnlyMetadata != nil {
+		u.ClientReadOnlyMetadata = request.ClientReadOnlyMetadata
+	}
+	if request.ServerMetadata != nil {
+		u.ServerMetadata = request.ServerMetadata
+	}
+	if request.Password != nil {
+		u.password = *request.Password
+	}
+	if request.SelectedTeamID != nil {
+		u.SelectedTeamID = *request.SelectedTeamID
+	}
+
+	if request.PrimaryEmail != nil || request.PrimaryEmailVerified != nil || request.PrimaryEmailAuthEnabled != nil {
+		channel := s.store.primaryChannel(u.ID)
+		if channel == nil && request.PrimaryEmail != nil && *request.PrimaryEmail != "" {
+			channel = &contactChannel{ID: newID(), UserID: u.ID, Type: "email", IsPrimary: true}
+			s.store.contactChannels.add(channel.ID, channel)
+		}
+		if channel != nil {
+			if request.PrimaryEmail != nil {
+				if *request.PrimaryEmail == "" {
+					s.store.contactChannels.remove(channel.ID)
+				} else if channel.Value != *request.PrimaryEmail {
+					channel.Value = *request.PrimaryEmail
+					channel.IsVerified = false
+				}
+			}
+			if request.PrimaryEmailVerified != nil {
+				channel.IsVerified = *request.PrimaryEmailVerified
+			}
+			if request.PrimaryEmailAuthEnabled != nil {
+				channel.UsedForAuth = *request.PrimaryEmailAuthEnabled
+			}
+		}
+		s.store.syncPrimaryEmail(u.ID)
+	}
+	return nil
+}
+
+// userByPath возвращает пользователя по идентификатору из пути
+func (s *Server) userByPath(r *http.Request) (*user, error) {
+	u := s.store.users.get(r.PathValue("user_id"))
+	if u == nil {
+		return nil, newError(http.StatusNotFound, base_http_client.ErrUserNotFound, "Пользователь %s не найден", r.PathValue("user_id"))
+	}
+	return u, nil
+}
+
+func (s *Server) listUsers(r *http.Request, c *caller) (interface{}, error) {
+	if err := requireServer(c); err != nil {
+		return nil, err
+	}
+
+	query := r.URL.Query()
+	if orderBy := query.Get("order_by"); orderBy != "" && orderBy != "signed_up_at" {
+		return nil, schemaError("Неподдерживаемое значение order_by: %q", orderBy)
+	}
+	teamID := query.Get("team_id")
+	search := strings.ToLower(query.Get("query"))
+	includeAnonymous := query.Get("include_anonymous") == "true"
+
+	var items []userView
+	for _, u := range s.store.users.list() {
+		if u.IsAnonymous && !includeAnonymous {
+			continue
+		}
+		if teamID != "" && s.store.membership(teamID, u.ID) == nil {
+			continue
+		}
+		if search != "" && !strings.Contains(strings.ToLower(u.ID+" "+u.DisplayName+" "+u.PrimaryEmail), search) {
+			continue
+		}
+		items = append(items, s.viewUser(u, c))
+	}
+	if query.Get("desc") == "true" {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+	return paginate(r, items, func(u userView) string { return u.ID })
+}
+
+func (s *Server) createUser(r *http.Request, c *caller) (interface{}, error) {
+	if err := requireServer(c); err != nil {
+		return nil, err
+	}
+	var request userRequest
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	if request.SelectedTeamID != nil {
+		return nil, schemaError("Поле selected_team_id нельзя задать при создании пользователя")
+	}
+
+	u := &user{}
+	s.store.createUser(u, s.now())
+	if err := s.applyUserRequest(u, &request, c); err != nil {
+		s.store.deleteUser(u.ID)
+		return nil, err
+	}
+	return s.viewUser(u, c), nil
+}
+
+func (s *Server) getCurrentUser(r *http.Request, c *caller) (interface{}, error) {
+	u, err := requireUser(c)
+	if err != nil {
+		return nil, err
+	}
+	return s.viewUser(u, c), nil
+}
+
+func (s *Server) updateCurrentUser(r *http.Request, c *caller) (interface{}, error) {
+	u, err := requireUser(c)
+	if err != nil {
+		return nil, err
+	}
+	var request userRequest
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	if err := s.applyUserRequest(u, &request, c); err != nil {
+		return nil, err
+	}
+	return s.viewUser(u, c), nil
+}
+
+func (s *Server) deleteCurrentUser(r *http.Request, c *caller) (interface{}, error) {
+	u, err := requireUser(c)
+	if err != nil {
+		return nil, err
+	}
+	s.store.deleteUser(u.ID)
+	return success{Success: true}, nil
+}
+
+func (s *Server) getUser(r *http.Request, c *caller) (interface{}, error) {
+	if err := requireServer(c); err != nil {
+		return nil, err
+	}
+	u, err := s.userByPath(r)
+	if err != nil {
+		return nil, err
+	}
+	return s.viewUser(u, c), nil
+}
+
+func (s *Server) updateUser(r *http.Request, c *caller) (interface{}, error) {
+	if err := requireServer(c); err != nil {
+		return nil, err
+	}
+	u, err := s.userByPath(r)
+	if err != nil {
+		return nil, err
+	}
+	var request userRequest
+	if err := decode(r, &request); err != nil {
+		return nil, err
+	}
+	if err := s.applyUserRequest(u, &request, c); err != nil {
+		return nil, err
+	}
+	return s.viewUser(u, c), nil
+}
+
+func (s *Server) deleteUser(r *http.Request, c *caller) (interface{}, error) {
+	if err := requireServer(c); err != nil {
+		return nil, err
+	}
+	u, err := s.userByPath(r)
+	if err != nil {
+		return nil, err
+	}
+	s.store.deleteUser(u.ID)
+	return success{Success: true}, nil
+}